@@ -1,14 +1,18 @@
 package main
 
 import (
+	"flag"
 	"fmt"
 	"log"
+	"net/http"
 	"os"
 	"strings"
 
 	"github.com/arl/evolve"
 	"github.com/arl/evolve/condition"
+	"github.com/arl/evolve/dashboard"
 	"github.com/arl/evolve/engine"
+	"github.com/arl/evolve/factory"
 	"github.com/arl/evolve/generator"
 	"github.com/arl/evolve/operator"
 	"github.com/arl/evolve/operator/mutation"
@@ -48,8 +52,10 @@ func (evaluator) Fitness(cand interface{}, pop []interface{}) float64 {
 func (evaluator) IsNatural() bool { return false }
 
 func main() {
-	if len(os.Args) == 2 {
-		target = strings.ToUpper(os.Args[1])
+	addr := flag.String("http", "", "serve a live dashboard on this address (e.g. localhost:8080)")
+	flag.Parse()
+	if flag.NArg() == 1 {
+		target = strings.ToUpper(flag.Arg(0))
 	}
 
 	// Setup a generator of random strings
@@ -58,7 +64,7 @@ func main() {
 			log.Fatalf("Target string must be solely made of \"%v\"", alphabet)
 		}
 	}
-	gen, err := generator.NewString(alphabet, len(target))
+	gen, err := factory.NewString(alphabet, len(target))
 	check(err)
 
 	// Define our evolutionary operators, a string mutation where each rune has
@@ -99,9 +105,20 @@ func main() {
 	// Evolution should end when a candidate with a fitness of 0 has been
 	// reached (0 different chars between candidate and target string)
 	cond := condition.TargetFitness{Fitness: 0, Natural: false}
+	options := []func(*engine.Engine) error{engine.Elites(5), engine.EndOn(cond)}
+
+	// Optionally, follow the evolution on a live dashboard.
+	if *addr != "" {
+		dash, err := dashboard.New(dashboard.Title("hello " + target))
+		check(err)
+		go func() { log.Fatal(http.ListenAndServe(*addr, dash)) }()
+		log.Printf("Dashboard on http://%s, press enter to start evolution", *addr)
+		fmt.Scanln()
+		options = append(options, engine.Observe(dash), engine.EndOn(dash.Condition()))
+	}
 
 	// Start evolution engine and print the best result
-	bests, _, err := eng.Evolve(100, engine.Elites(5), engine.EndOn(cond))
+	bests, _, err := eng.Evolve(100, options...)
 	check(err)
 	log.Println(bests[0])
 }
@@ -9,11 +9,13 @@ import (
 	"io"
 	"log"
 	"math/rand"
+	"net/http"
 	"os"
 	"time"
 
 	"github.com/arl/evolve"
 	"github.com/arl/evolve/condition"
+	"github.com/arl/evolve/dashboard"
 	"github.com/arl/evolve/engine"
 	"github.com/arl/evolve/generator"
 	"github.com/arl/evolve/operator"
@@ -51,7 +53,7 @@ func readPattern(r io.Reader) ([]string, error) {
 	return puzzle, s.Err()
 }
 
-func solveSudoku(pattern []string) error {
+func solveSudoku(pattern []string, addr string) error {
 	// Crossover rows between parents (so offspring is x rows from parent1 and y
 	// rows from parent2).
 	xover := xover.New(mater{})
@@ -97,12 +99,28 @@ func solveSudoku(pattern []string) error {
 		popsize = 500
 		nelites = 500 * 0.05
 	)
-	bests, _, err := eng.Evolve(
-		popsize,
+	options := []func(*engine.Engine) error{
 		engine.Elites(nelites),
 		engine.EndOn(condition.TargetFitness{Fitness: 0, Natural: false}),
-		engine.EndOn(condition.NewUserAbort()),
-	)
+	}
+
+	// Optionally, follow the evolution on a live dashboard, from which it can
+	// be paused, resumed or aborted.
+	if addr != "" {
+		dash, err := dashboard.New(
+			dashboard.Title("sudoku"),
+			dashboard.Format(func(cand interface{}) string { return cand.(*sudoku).String() }),
+		)
+		check(err)
+		go func() { log.Fatal(http.ListenAndServe(addr, dash)) }()
+		log.Printf("Dashboard on http://%s, press enter to start evolution", addr)
+		fmt.Scanln()
+		options = append(options, engine.Observe(dash), engine.EndOn(dash.Condition()))
+	} else {
+		options = append(options, engine.EndOn(condition.NewUserAbort()))
+	}
+
+	bests, _, err := eng.Evolve(popsize, options...)
 	check(err)
 
 	log.Printf("Sudoku solution:\n%v\n", bests[0].Candidate.(*sudoku))
@@ -131,6 +149,7 @@ func ui() (io.Reader, error) {
 
 func main() {
 	fpuzzle := flag.String("puzzle", "", "file with the sudoku puzzle to solve")
+	addr := flag.String("http", "", "serve a live dashboard on this address (e.g. localhost:8080)")
 	flag.Parse()
 
 	var r io.Reader // puzzle buffer
@@ -157,7 +176,7 @@ func main() {
 		log.Fatalf("can't read sudo pattern: %v", err)
 	}
 
-	err = solveSudoku(pattern)
+	err = solveSudoku(pattern, *addr)
 	if err != nil {
 		log.Fatalf("couldn't solve sudoku pattern: %v\n", err)
 	}
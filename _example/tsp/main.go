@@ -0,0 +1,99 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"math/rand"
+	"time"
+
+	"github.com/arl/evolve"
+	"github.com/arl/evolve/benchmark/tsp"
+	"github.com/arl/evolve/condition"
+	"github.com/arl/evolve/engine"
+	"github.com/arl/evolve/generator"
+	"github.com/arl/evolve/operator"
+	"github.com/arl/evolve/operator/mutation"
+	"github.com/arl/evolve/operator/xover"
+	"github.com/arl/evolve/pkg/mt19937"
+	"github.com/arl/evolve/selection"
+)
+
+func check(err error) {
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+// solve evolves tours of inst, until a tour of length optimum is found or
+// maxgen generations have been evolved, and returns the best tour.
+func solve(inst *tsp.Instance, optimum, maxgen int, rng *rand.Rand) *evolve.Individual {
+	// Partially mapped crossover, and list order mutation, both keep tours
+	// valid permutations of the nodes.
+	xover := xover.New(xover.PMX{})
+	xover.Points = generator.ConstInt(2)
+	xover.Probability = generator.ConstFloat64(0.9)
+
+	mut := &mutation.ListOrder{
+		Count:          generator.NewPoisson(generator.ConstFloat64(1.5), rng),
+		MutationAmount: generator.NewPoisson(generator.ConstFloat64(1.5), rng),
+	}
+
+	// Offspring are then improved by local search, up to a local optimum of
+	// both neighbourhoods.
+	twoOpt := tsp.NewTwoOpt(inst)
+	orOpt := tsp.NewOrOpt(inst)
+	twoOpt.Probability = generator.ConstFloat64(0.3)
+	orOpt.Probability = generator.ConstFloat64(0.3)
+
+	sel := selection.NewTournament()
+	check(sel.SetProb(0.8))
+
+	epocher := engine.Generational{
+		Op:   operator.Pipeline{xover, mut, twoOpt, orOpt},
+		Eval: inst,
+		Sel:  sel,
+	}
+
+	// The instance is both the factory of random tours and their evaluator.
+	eng, err := engine.New(inst, inst, &epocher, engine.Rand(rng))
+	check(err)
+
+	bests, _, err := eng.Evolve(
+		100,
+		engine.Elites(2),
+		engine.EndOn(condition.TargetFitness{Fitness: float64(optimum), Natural: false}),
+		engine.EndOn(condition.GenerationCount(maxgen)),
+	)
+	check(err)
+	return bests[0]
+}
+
+// This example solves the bundled TSPLIB instances with a memetic algorithm,
+// that is a genetic algorithm whose offspring are improved by local search,
+// and compares the length of the best tours found with the known optima.
+func main() {
+	name := flag.String("instance", "", "bundled instance to solve, all of them if empty")
+	maxgen := flag.Int("gens", 500, "maximum number of generations")
+	seed := flag.Int64("seed", time.Now().UnixNano(), "random seed")
+	flag.Parse()
+
+	names := tsp.BundledNames()
+	if *name != "" {
+		names = []string{*name}
+	}
+
+	rng := rand.New(mt19937.New(*seed))
+	for _, name := range names {
+		inst, err := tsp.Bundled(name)
+		check(err)
+
+		optimum := tsp.Optima[name]
+		start := time.Now()
+		best := solve(inst, optimum, *maxgen, rng)
+		length := int(best.Fitness)
+		gap := 100 * float64(length-optimum) / float64(optimum)
+		fmt.Printf("%-10s %3d nodes: best %6d, optimum %6d, gap %5.2f%% (%v)\n",
+			name, inst.Dim(), length, optimum, gap, time.Since(start).Round(time.Millisecond))
+	}
+}
@@ -0,0 +1,140 @@
+// Package binary provides a suite of benchmark problems on bit strings, to
+// assess and compare evolutionary algorithms and operators.
+//
+// The problems range from the simplest (OneMax) to deceptive (Trap, Jump),
+// hierarchical (HIFF, RoyalRoad) and epistatic (NK landscapes) ones. Every
+// problem is an evolve.Evaluator of *bitstring.Bitstring candidates, with
+// natural fitness and a known optimum, and an evolve.Factory that generates
+// random bit strings of the right length.
+//
+// The optimum can be used as termination condition:
+//
+//	trap, _ := binary.NewTrap(100, 5)
+//	eng, _ := engine.New(trap, trap, epocher, engine.EndOn(binary.Target(trap)))
+package binary
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+
+	"github.com/arl/evolve"
+	"github.com/arl/evolve/condition"
+	"github.com/arl/evolve/pkg/bitstring"
+)
+
+// A Problem is a benchmark problem on bit strings.
+type Problem interface {
+	evolve.Evaluator
+	evolve.Factory
+
+	// Len returns the length of the bit strings.
+	Len() uint
+
+	// Optimum returns the fitness of the optimal solutions.
+	Optimum() float64
+}
+
+// Target returns the condition satisfied when the optimum of p is reached.
+func Target(p Problem) condition.TargetFitness {
+	return condition.TargetFitness{Fitness: p.Optimum(), Natural: true}
+}
+
+var errZeroLength = errors.New("length must be strictly positive")
+
+// length is the length of the bit strings of a problem. It implements the
+// methods common to all problems.
+type length uint
+
+// Len returns the length of the bit strings.
+func (l length) Len() uint { return uint(l) }
+
+// New implements evolve.Factory, it returns a random bit string.
+func (l length) New(rng *rand.Rand) interface{} { return bitstring.Random(uint(l), rng) }
+
+// IsNatural implements evolve.Evaluator, it returns true.
+func (length) IsNatural() bool { return true }
+
+// bits returns cand, as a bit string. It panics if its length is wrong.
+func (l length) bits(cand interface{}) *bitstring.Bitstring {
+	bs := cand.(*bitstring.Bitstring)
+	if uint(bs.Len()) != uint(l) {
+		panic(fmt.Sprintf("%d-bit string, want %d", bs.Len(), uint(l)))
+	}
+	return bs
+}
+
+// OneMax counts the ones.
+type OneMax struct{ length }
+
+// NewOneMax returns the OneMax problem on n bits.
+func NewOneMax(n uint) (*OneMax, error) {
+	if n == 0 {
+		return nil, errZeroLength
+	}
+	return &OneMax{length(n)}, nil
+}
+
+// Fitness implements evolve.Evaluator.
+func (p *OneMax) Fitness(cand interface{}, pop []interface{}) float64 {
+	return float64(p.bits(cand).OnesCount())
+}
+
+// Optimum returns n.
+func (p *OneMax) Optimum() float64 { return float64(p.length) }
+
+// LeadingOnes counts the consecutive ones, starting from bit 0.
+type LeadingOnes struct{ length }
+
+// NewLeadingOnes returns the LeadingOnes problem on n bits.
+func NewLeadingOnes(n uint) (*LeadingOnes, error) {
+	if n == 0 {
+		return nil, errZeroLength
+	}
+	return &LeadingOnes{length(n)}, nil
+}
+
+// Fitness implements evolve.Evaluator.
+func (p *LeadingOnes) Fitness(cand interface{}, pop []interface{}) float64 {
+	bs := p.bits(cand)
+	i := uint(0)
+	for i < uint(p.length) && bs.Bit(i) {
+		i++
+	}
+	return float64(i)
+}
+
+// Optimum returns n.
+func (p *LeadingOnes) Optimum() float64 { return float64(p.length) }
+
+// Jump is OneMax with a gap of width k before the optimum: bit strings with
+// more than n-k ones, except the optimum, have a fitness decreasing with the
+// number of ones. Crossing the gap requires to flip k bits at once.
+type Jump struct {
+	length
+	k uint
+}
+
+// NewJump returns the Jump problem on n bits, with a gap of width k, where
+// 1 <= k <= n.
+func NewJump(n, k uint) (*Jump, error) {
+	if n == 0 {
+		return nil, errZeroLength
+	}
+	if k < 1 || k > n {
+		return nil, fmt.Errorf("invalid gap width %d for %d bits", k, n)
+	}
+	return &Jump{length: length(n), k: k}, nil
+}
+
+// Fitness implements evolve.Evaluator.
+func (p *Jump) Fitness(cand interface{}, pop []interface{}) float64 {
+	n, ones := uint(p.length), p.bits(cand).OnesCount()
+	if ones <= n-p.k || ones == n {
+		return float64(p.k + ones)
+	}
+	return float64(n - ones)
+}
+
+// Optimum returns n+k.
+func (p *Jump) Optimum() float64 { return float64(uint(p.length) + p.k) }
@@ -0,0 +1,182 @@
+package binary
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/arl/evolve"
+	"github.com/arl/evolve/pkg/bitstring"
+)
+
+func bits(t *testing.T, s string) *bitstring.Bitstring {
+	t.Helper()
+	bs, err := bitstring.MakeFromString(s)
+	require.NoError(t, err)
+	return bs
+}
+
+// ones returns a bit string of n ones.
+func ones(n uint) *bitstring.Bitstring {
+	bs := bitstring.New(n)
+	for i := uint(0); i < n; i++ {
+		bs.SetBit(i)
+	}
+	return bs
+}
+
+func TestProblems(t *testing.T) {
+	must := func(p Problem, err error) Problem {
+		require.NoError(t, err)
+		return p
+	}
+	tests := []struct {
+		name    string
+		p       Problem
+		cands   map[string]float64
+		optimum float64
+	}{
+		{
+			name:    "onemax",
+			p:       must(NewOneMax(8)),
+			cands:   map[string]float64{"00000000": 0, "01100100": 3},
+			optimum: 8,
+		},
+		{
+			name:    "leading-ones",
+			p:       must(NewLeadingOnes(8)),
+			cands:   map[string]float64{"00000000": 0, "01100111": 3, "01111111": 7},
+			optimum: 8,
+		},
+		{
+			name:    "jump",
+			p:       must(NewJump(8, 3)),
+			cands:   map[string]float64{"00000000": 3, "00011111": 8, "00111111": 2, "01111111": 1},
+			optimum: 11,
+		},
+		{
+			name:    "trap",
+			p:       must(NewTrap(8, 4)),
+			cands:   map[string]float64{"00000000": 6, "00011111": 4 + 2, "01111111": 4 + 0, "00010001": 4},
+			optimum: 8,
+		},
+		{
+			name:    "royal-road",
+			p:       must(NewRoyalRoad(8, 4)),
+			cands:   map[string]float64{"00000000": 0, "01111111": 4, "11101111": 4},
+			optimum: 8,
+		},
+		{
+			name:    "hiff",
+			p:       must(NewHIFF(8)),
+			cands:   map[string]float64{"00000000": 32, "00001111": 8 + 8 + 8, "01010101": 8, "00110011": 8 + 8},
+			optimum: 32,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.optimum, tt.p.Optimum())
+			assert.Equal(t, tt.optimum, tt.p.Fitness(ones(tt.p.Len()), nil))
+			assert.True(t, tt.p.IsNatural())
+			for s, want := range tt.cands {
+				assert.Equal(t, want, tt.p.Fitness(bits(t, s), nil), s)
+			}
+
+			// The optimum is the best fitness.
+			rng := rand.New(rand.NewSource(1))
+			for i := 0; i < 100; i++ {
+				assert.LessOrEqual(t, tt.p.Fitness(tt.p.New(rng), nil), tt.optimum)
+			}
+			assert.Panics(t, func() { tt.p.Fitness(bitstring.New(tt.p.Len()+1), nil) })
+		})
+	}
+}
+
+func TestProblemErrors(t *testing.T) {
+	var err error
+	_, err = NewOneMax(0)
+	assert.Error(t, err)
+	_, err = NewJump(8, 0)
+	assert.Error(t, err)
+	_, err = NewJump(8, 9)
+	assert.Error(t, err)
+	_, err = NewTrap(10, 4)
+	assert.Error(t, err)
+	_, err = NewRoyalRoad(10, 0)
+	assert.Error(t, err)
+	_, err = NewHIFF(12)
+	assert.Error(t, err)
+	_, err = NewNK(10, 10, Adjacent, 1)
+	assert.Error(t, err)
+	_, err = NewNK(10, 2, Neighbourhood(5), 1)
+	assert.Error(t, err)
+	_, err = NewNK(100, MaxNKK+1, Adjacent, 1)
+	assert.Error(t, err)
+}
+
+func TestNK(t *testing.T) {
+	for _, nb := range []Neighbourhood{Adjacent, Random} {
+		for k := uint(0); k < 5; k++ {
+			p, err := NewNK(12, k, nb, int64(k))
+			require.NoError(t, err)
+
+			// Neighbourhoods are made of distinct bits.
+			for i, neigh := range p.neigh {
+				seen := make(map[uint]bool)
+				for _, b := range neigh {
+					assert.False(t, seen[b], "bit %d: neighbours %v", i, neigh)
+					seen[b] = true
+				}
+			}
+
+			// Same seed, same landscape.
+			q, err := NewNK(12, k, nb, int64(k))
+			require.NoError(t, err)
+			assert.Equal(t, p.table, q.table)
+			assert.Equal(t, p.neigh, q.neigh)
+
+			// The optimum is the best fitness of all bit strings.
+			assert.Equal(t, p.exhaustiveOptimum(), p.Optimum(), "%v k=%d", nb, k)
+		}
+	}
+
+	// Short landscapes, in which the last contributions overlap the first
+	// ones.
+	for n := uint(2); n < 8; n++ {
+		for k := uint(0); k < n; k++ {
+			p, err := NewNK(n, k, Adjacent, int64(n))
+			require.NoError(t, err)
+			assert.Equal(t, p.exhaustiveOptimum(), p.Optimum(), "n=%d k=%d", n, k)
+		}
+	}
+
+	// Adjacent neighbourhoods wrap around.
+	p, err := NewNK(5, 2, Adjacent, 1)
+	require.NoError(t, err)
+	assert.Equal(t, []uint{4, 0, 1}, p.neigh[4])
+
+	// The optimum of large landscapes with random neighbourhoods is unknown.
+	p, err = NewNK(MaxRandomNKLen+1, 2, Random, 1)
+	require.NoError(t, err)
+	assert.True(t, math.IsNaN(p.Optimum()))
+
+	p, err = NewNK(200, 4, Adjacent, 1)
+	require.NoError(t, err)
+	assert.False(t, math.IsNaN(p.Optimum()))
+
+	// Neither is that of landscapes with many interactions.
+	p, err = NewNK(100, MaxAdjacentNKK+1, Adjacent, 1)
+	require.NoError(t, err)
+	assert.True(t, math.IsNaN(p.Optimum()))
+}
+
+func TestTarget(t *testing.T) {
+	p, err := NewTrap(20, 5)
+	require.NoError(t, err)
+	cond := Target(p)
+	assert.True(t, cond.IsSatisfied(&evolve.PopulationStats{BestFitness: 20, Natural: true}))
+	assert.False(t, cond.IsSatisfied(&evolve.PopulationStats{BestFitness: 19, Natural: true}))
+}
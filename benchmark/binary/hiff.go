@@ -0,0 +1,54 @@
+package binary
+
+import "fmt"
+
+// HIFF is the Hierarchical If-and-only-If function of Watson et al. The bit
+// string is recursively split in halves, forming a binary tree of blocks, every
+// block made only of zeros or only of ones contributes its size to the
+// fitness. The two optima are the all-zeros and all-ones bit strings.
+type HIFF struct{ length }
+
+// NewHIFF returns the HIFF problem on n bits. n must be a power of 2.
+func NewHIFF(n uint) (*HIFF, error) {
+	if n == 0 || n&(n-1) != 0 {
+		return nil, fmt.Errorf("length %d is not a power of 2", n)
+	}
+	return &HIFF{length(n)}, nil
+}
+
+// Fitness implements evolve.Evaluator.
+func (p *HIFF) Fitness(cand interface{}, pop []interface{}) float64 {
+	bs := p.bits(cand)
+
+	// Blocks of the current level: 0 for zeros, 1 for ones, 2 for mixed.
+	blocks := make([]uint8, p.length)
+	for i := range blocks {
+		if bs.Bit(uint(i)) {
+			blocks[i] = 1
+		}
+	}
+	fitness, size := uint(p.length), uint(1)
+	for len(blocks) > 1 {
+		size *= 2
+		for i := range blocks[:len(blocks)/2] {
+			l, r := blocks[2*i], blocks[2*i+1]
+			if l == r && l != 2 {
+				fitness += size
+				blocks[i] = l
+			} else {
+				blocks[i] = 2
+			}
+		}
+		blocks = blocks[:len(blocks)/2]
+	}
+	return float64(fitness)
+}
+
+// Optimum returns n(log₂(n)+1).
+func (p *HIFF) Optimum() float64 {
+	n, levels := uint(p.length), uint(1)
+	for s := n; s > 1; s /= 2 {
+		levels++
+	}
+	return float64(n * levels)
+}
@@ -0,0 +1,211 @@
+package binary
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"sync"
+
+	"github.com/arl/evolve/pkg/mt19937"
+)
+
+// Neighbourhood is the way the interacting bits of an NK landscape are
+// chosen.
+type Neighbourhood int
+
+const (
+	// Adjacent neighbourhoods: bit i interacts with bits i+1, ..., i+k,
+	// wrapping around the end of the bit string.
+	Adjacent Neighbourhood = iota
+
+	// Random neighbourhoods: bit i interacts with k other bits chosen at
+	// random.
+	Random
+)
+
+// MaxNKK is the maximum number of bits each bit of an NK landscape interacts
+// with. Each bit has a table of 2ᵏ⁺¹ contributions.
+const MaxNKK = 16
+
+// MaxRandomNKLen is the maximum length of an NK landscape with random
+// neighbourhoods whose optimum is known. Finding the optimum of such
+// landscapes is NP-hard, it is found by exhaustive search.
+const MaxRandomNKLen = 20
+
+// MaxAdjacentNKK is the maximum k of an NK landscape with adjacent
+// neighbourhoods whose optimum is known, its computation time growing as 4ᵏ.
+const MaxAdjacentNKK = 10
+
+// NK is an NK landscape of Kauffman: a tunably rugged fitness landscape in
+// which each of the n bits interacts with k other bits. The fitness is the
+// mean of the contributions of the n bits, each contribution being drawn at
+// random in [0, 1) for each combination of the values of the bit and its
+// neighbourhood.
+//
+// The optimum of landscapes with adjacent neighbourhoods is found by dynamic
+// programming in O(n·4ᵏ), and is NaN if k > MaxAdjacentNKK. That of landscapes
+// with random neighbourhoods is found by exhaustive search, and is NaN if
+// n > MaxRandomNKLen. It is computed the first time Optimum is called.
+type NK struct {
+	length
+	k     uint
+	nb    Neighbourhood
+	neigh [][]uint    // neigh[i] holds i, then its neighbours
+	table [][]float64 // contributions, indexed by the bits of neigh[i]
+
+	once sync.Once
+	opt  float64
+}
+
+// NewNK returns a random NK landscape on n bits, in which every bit interacts
+// with k < n other bits, k being at most MaxNKK. The landscape is entirely determined by n, k, the
+// neighbourhood and the seed.
+func NewNK(n, k uint, nb Neighbourhood, seed int64) (*NK, error) {
+	if n == 0 {
+		return nil, errZeroLength
+	}
+	if k >= n {
+		return nil, fmt.Errorf("invalid k %d for %d bits", k, n)
+	}
+	if k > MaxNKK {
+		return nil, fmt.Errorf("k %d is too large, the maximum is %d", k, MaxNKK)
+	}
+	if nb != Adjacent && nb != Random {
+		return nil, fmt.Errorf("invalid neighbourhood %d", nb)
+	}
+
+	rng := rand.New(mt19937.New(seed))
+	p := &NK{length: length(n), k: k, nb: nb}
+	p.neigh = make([][]uint, n)
+	for i := range p.neigh {
+		p.neigh[i] = make([]uint, k+1)
+		p.neigh[i][0] = uint(i)
+		if nb == Adjacent {
+			for j := uint(1); j <= k; j++ {
+				p.neigh[i][j] = (uint(i) + j) % n
+			}
+			continue
+		}
+		// Draw k distinct bits among the n-1 others.
+		others := rng.Perm(int(n) - 1)
+		for j := uint(1); j <= k; j++ {
+			o := uint(others[j-1])
+			if o >= uint(i) {
+				o++
+			}
+			p.neigh[i][j] = o
+		}
+	}
+	p.table = make([][]float64, n)
+	for i := range p.table {
+		p.table[i] = make([]float64, 1<<(k+1))
+		for j := range p.table[i] {
+			p.table[i][j] = rng.Float64()
+		}
+	}
+	return p, nil
+}
+
+// Fitness implements evolve.Evaluator.
+func (p *NK) Fitness(cand interface{}, pop []interface{}) float64 {
+	bs := p.bits(cand)
+	return p.fitness(func(i uint) bool { return bs.Bit(i) })
+}
+
+// fitness returns the fitness of the bit string whose bits are given by bit.
+func (p *NK) fitness(bit func(uint) bool) float64 {
+	sum := 0.0
+	for i, neigh := range p.neigh {
+		idx := 0
+		for j, b := range neigh {
+			if bit(b) {
+				idx |= 1 << j
+			}
+		}
+		sum += p.table[i][idx]
+	}
+	return sum / float64(p.length)
+}
+
+// Optimum returns the fitness of the global optimum, or NaN if it's unknown.
+func (p *NK) Optimum() float64 {
+	p.once.Do(func() {
+		switch {
+		case p.nb == Adjacent && p.k <= MaxAdjacentNKK:
+			p.opt = p.adjacentOptimum()
+		case p.nb == Random && uint(p.length) <= MaxRandomNKLen:
+			p.opt = p.exhaustiveOptimum()
+		default:
+			p.opt = math.NaN()
+		}
+	})
+	return p.opt
+}
+
+// exhaustiveOptimum evaluates every bit string.
+func (p *NK) exhaustiveOptimum() float64 {
+	best := math.Inf(-1)
+	for x := uint64(0); x < 1<<uint(p.length); x++ {
+		f := p.fitness(func(i uint) bool { return x&(1<<i) != 0 })
+		best = math.Max(best, f)
+	}
+	return best
+}
+
+// adjacentOptimum finds the optimum of a landscape with adjacent
+// neighbourhoods by dynamic programming.
+//
+// The contribution of bit i only depends on bits i to i+k. For each value of
+// the first k bits, on which the last contributions depend, bits are chosen
+// one after the other, keeping the best sum of the contributions for each
+// value of the last k chosen bits.
+//
+// Contributions are summed in the same order as Fitness does, so that the
+// optimum has exactly the fitness of the optimal bit strings.
+func (p *NK) adjacentOptimum() float64 {
+	n, k := uint(p.length), p.k
+	nstates := 1 << k
+	mask := nstates - 1
+	best := math.Inf(-1)
+
+	dp := make([]float64, nstates)
+	next := make([]float64, nstates)
+	for prefix := 0; prefix < nstates; prefix++ {
+		// state holds bits t-k to t-1, bit t-k being the lowest.
+		for s := range dp {
+			dp[s] = math.Inf(-1)
+		}
+		dp[prefix] = 0
+		for t := k; t < n; t++ {
+			for s := range next {
+				next[s] = math.Inf(-1)
+			}
+			for s, sum := range dp {
+				if math.IsInf(sum, -1) {
+					continue
+				}
+				for b := 0; b < 2; b++ {
+					w := s | b<<k
+					if v := sum + p.table[t-k][w]; v > next[w>>1] {
+						next[w>>1] = v
+					}
+				}
+			}
+			dp, next = next, dp
+		}
+
+		// Add the contributions of the last k bits, which wrap around to
+		// the prefix. The state holds bits n-k to n-1.
+		for s, sum := range dp {
+			if math.IsInf(sum, -1) {
+				continue
+			}
+			bits := s | prefix<<k // bits n-k to n-1, then 0 to k-1
+			for m := uint(0); m < k; m++ {
+				sum += p.table[n-k+m][(bits>>m)&(mask<<1|1)]
+			}
+			best = math.Max(best, sum)
+		}
+	}
+	return best / float64(n)
+}
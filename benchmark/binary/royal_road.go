@@ -0,0 +1,42 @@
+package binary
+
+import "fmt"
+
+// RoyalRoad is the Royal Road function R1 of Mitchell, Forrest and Holland.
+// The bit string is split in blocks of b bits, every block made only of ones
+// contributes b to the fitness.
+type RoyalRoad struct {
+	length
+	b uint
+}
+
+// NewRoyalRoad returns the Royal Road problem on n bits, with blocks of b bits.
+// n must be a multiple of b.
+func NewRoyalRoad(n, b uint) (*RoyalRoad, error) {
+	if n == 0 {
+		return nil, errZeroLength
+	}
+	if b == 0 || n%b != 0 {
+		return nil, fmt.Errorf("length %d is not a multiple of the block size %d", n, b)
+	}
+	return &RoyalRoad{length: length(n), b: b}, nil
+}
+
+// Fitness implements evolve.Evaluator.
+func (p *RoyalRoad) Fitness(cand interface{}, pop []interface{}) float64 {
+	bs := p.bits(cand)
+	fitness := uint(0)
+blocks:
+	for i := uint(0); i < uint(p.length); i += p.b {
+		for j := i; j < i+p.b; j++ {
+			if !bs.Bit(j) {
+				continue blocks
+			}
+		}
+		fitness += p.b
+	}
+	return float64(fitness)
+}
+
+// Optimum returns n.
+func (p *RoyalRoad) Optimum() float64 { return float64(p.length) }
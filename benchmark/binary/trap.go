@@ -0,0 +1,47 @@
+package binary
+
+import "fmt"
+
+// Trap is the concatenation of deceptive trap functions of order k. The bit
+// string is split in blocks of k bits, a block with u ones contributes k to
+// the fitness if u = k, or k-1-u otherwise. Within a block, the fitness
+// therefore leads away from the optimum, towards the all-zeros local optimum.
+type Trap struct {
+	length
+	k uint
+}
+
+// NewTrap returns the concatenated trap problem on n bits, with blocks of k
+// bits. n must be a multiple of k.
+func NewTrap(n, k uint) (*Trap, error) {
+	if n == 0 {
+		return nil, errZeroLength
+	}
+	if k == 0 || n%k != 0 {
+		return nil, fmt.Errorf("length %d is not a multiple of the block size %d", n, k)
+	}
+	return &Trap{length: length(n), k: k}, nil
+}
+
+// Fitness implements evolve.Evaluator.
+func (p *Trap) Fitness(cand interface{}, pop []interface{}) float64 {
+	bs := p.bits(cand)
+	fitness := uint(0)
+	for i := uint(0); i < uint(p.length); i += p.k {
+		u := uint(0)
+		for j := i; j < i+p.k; j++ {
+			if bs.Bit(j) {
+				u++
+			}
+		}
+		if u == p.k {
+			fitness += p.k
+		} else {
+			fitness += p.k - 1 - u
+		}
+	}
+	return float64(fitness)
+}
+
+// Optimum returns n.
+func (p *Trap) Optimum() float64 { return float64(p.length) }
@@ -0,0 +1,208 @@
+// Package continuous provides a suite of benchmark functions of real
+// variables, to assess and compare evolutionary algorithms and operators.
+//
+// Every function is minimized over a hypercube, has a known global minimum, and
+// a configurable dimension. In the style of the BBOB testbed, the functions can
+// be randomly shifted and rotated, so that algorithms can't exploit the
+// location of the optimum or the separability of the function.
+//
+// A Function is an evolve.Evaluator of []float64 candidates, and an
+// evolve.Factory that generates them uniformly in the search domain. Gray is
+// the equivalent for *bitstring.Bitstring candidates, decoded as Gray-coded
+// vectors.
+//
+// Fitness scores are the distance to the optimum value, f(x) - f(x*), so they're
+// non-negative and 0 is the target fitness.
+package continuous
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+)
+
+// Names returns the sorted names of the available functions.
+func Names() []string {
+	names := make([]string, 0, len(definitions))
+	for name := range definitions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Function is a benchmark function, to minimize.
+//
+// Function is safe for concurrent use.
+type Function struct {
+	name string
+	def  definition
+	dim  int
+
+	xopt  []float64   // location of the global minimum
+	shift []float64   // optimum shift, or nil
+	rot   [][]float64 // rotation matrix, or nil
+}
+
+// New returns the function named name, in dimension dim (see Names for the
+// available functions).
+func New(name string, dim int, options ...func(*Function) error) (*Function, error) {
+	def, ok := definitions[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown function %q", name)
+	}
+	if dim < 1 || dim < def.minDim {
+		return nil, fmt.Errorf("%s: invalid dimension %d", name, dim)
+	}
+
+	f := &Function{name: name, def: def, dim: dim}
+	for _, opt := range options {
+		if err := opt(f); err != nil {
+			return nil, fmt.Errorf("%s: %v", name, err)
+		}
+	}
+
+	f.xopt = make([]float64, dim)
+	for i := range f.xopt {
+		f.xopt[i] = def.argmin
+		if f.shift != nil {
+			f.xopt[i] += f.shift[i]
+		}
+	}
+	return f, nil
+}
+
+// Shift randomly moves the optimum. The optimum is moved by an offset drawn
+// uniformly in [-4, 4] in every dimension, the range being reduced when
+// necessary so that the optimum stays well within the search domain.
+func Shift(rng *rand.Rand) func(*Function) error {
+	return func(f *Function) error {
+		if rng == nil {
+			return errors.New("nil random number generator")
+		}
+		r := math.Min(4, math.Min(f.def.upper-f.def.argmin, f.def.argmin-f.def.lower)/2)
+		f.shift = make([]float64, f.dim)
+		for i := range f.shift {
+			f.shift[i] = r * (2*rng.Float64() - 1)
+		}
+		return nil
+	}
+}
+
+// Rotate applies a random rotation, around the optimum, to the search space.
+// The rotation matrix is drawn uniformly among orthogonal matrices.
+func Rotate(rng *rand.Rand) func(*Function) error {
+	return func(f *Function) error {
+		if rng == nil {
+			return errors.New("nil random number generator")
+		}
+		f.rot = randomRotation(f.dim, rng)
+		return nil
+	}
+}
+
+// randomRotation returns a random n×n orthogonal matrix, obtained by
+// Gram-Schmidt orthonormalization of a matrix of normal deviates.
+func randomRotation(n int, rng *rand.Rand) [][]float64 {
+	m := make([][]float64, n)
+	for i := range m {
+		for {
+			m[i] = make([]float64, n)
+			for j := range m[i] {
+				m[i][j] = rng.NormFloat64()
+			}
+			for k := 0; k < i; k++ {
+				d := dot(m[i], m[k])
+				for j := range m[i] {
+					m[i][j] -= d * m[k][j]
+				}
+			}
+			// Draw again the (very unlikely) degenerate rows.
+			if norm := math.Sqrt(dot(m[i], m[i])); norm > 1e-8 {
+				for j := range m[i] {
+					m[i][j] /= norm
+				}
+				break
+			}
+		}
+	}
+	return m
+}
+
+func dot(a, b []float64) float64 {
+	sum := 0.0
+	for i := range a {
+		sum += a[i] * b[i]
+	}
+	return sum
+}
+
+// Name returns the name of the function.
+func (f *Function) Name() string { return f.name }
+
+// String returns the name and dimension of the function.
+func (f *Function) String() string { return fmt.Sprintf("%s-%d", f.name, f.dim) }
+
+// Dim returns the dimension of the function.
+func (f *Function) Dim() int { return f.dim }
+
+// Bounds returns the bounds of the search domain, which are the same in every
+// dimension.
+func (f *Function) Bounds() (lower, upper float64) { return f.def.lower, f.def.upper }
+
+// Optimum returns the location of the global minimum.
+func (f *Function) Optimum() []float64 { return append([]float64(nil), f.xopt...) }
+
+// Min returns the value of the global minimum.
+func (f *Function) Min() float64 { return f.def.fmin * float64(f.dim) }
+
+// Value returns the value of the function at x. It panics if the length of x
+// is not the function dimension.
+func (f *Function) Value(x []float64) float64 {
+	if len(x) != f.dim {
+		panic(fmt.Sprintf("%s: %d-dimensional point, want %d", f, len(x), f.dim))
+	}
+	if f.shift == nil && f.rot == nil {
+		return f.def.f(x)
+	}
+
+	// Evaluate the untransformed function at argmin + R(x - xopt).
+	y := make([]float64, f.dim)
+	for i := range y {
+		y[i] = x[i] - f.xopt[i]
+	}
+	z := y
+	if f.rot != nil {
+		z = make([]float64, f.dim)
+		for i := range z {
+			z[i] = dot(f.rot[i], y)
+		}
+	}
+	for i := range z {
+		z[i] += f.def.argmin
+	}
+	return f.def.f(z)
+}
+
+// Fitness implements evolve.Evaluator for []float64 candidates, it returns
+// f(cand) - f(x*), x* being the global minimum.
+func (f *Function) Fitness(cand interface{}, pop []interface{}) float64 {
+	// Floating-point rounding may produce slightly negative values near the
+	// optimum.
+	return math.Max(0, f.Value(cand.([]float64))-f.Min())
+}
+
+// IsNatural implements evolve.Evaluator, it returns false.
+func (f *Function) IsNatural() bool { return false }
+
+// New implements evolve.Factory, it returns a []float64 drawn uniformly in the
+// search domain.
+func (f *Function) New(rng *rand.Rand) interface{} {
+	x := make([]float64, f.dim)
+	for i := range x {
+		x[i] = f.def.lower + rng.Float64()*(f.def.upper-f.def.lower)
+	}
+	return x
+}
@@ -0,0 +1,147 @@
+package continuous
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/arl/evolve"
+	"github.com/arl/evolve/pkg/bitstring"
+)
+
+var (
+	_ evolve.Evaluator = (*Function)(nil)
+	_ evolve.Factory   = (*Function)(nil)
+	_ evolve.Evaluator = (*Gray)(nil)
+	_ evolve.Factory   = (*Gray)(nil)
+)
+
+func TestFunctionsOptimum(t *testing.T) {
+	for _, name := range Names() {
+		for _, dim := range []int{2, 5, 10} {
+			rng := rand.New(rand.NewSource(int64(dim)))
+			variants := map[string][]func(*Function) error{
+				"plain":           nil,
+				"shifted":         {Shift(rng)},
+				"rotated":         {Rotate(rng)},
+				"shifted+rotated": {Shift(rng), Rotate(rng)},
+			}
+			for variant, opts := range variants {
+				f, err := New(name, dim, opts...)
+				require.NoError(t, err)
+
+				xopt := f.Optimum()
+				lower, upper := f.Bounds()
+				for _, xi := range xopt {
+					require.True(t, xi > lower && xi < upper, "%s %s: optimum out of bounds", f, variant)
+				}
+
+				fopt := f.Fitness(xopt, nil)
+				assert.InDelta(t, 0, fopt, 1e-6, "%s %s: fitness at optimum", f, variant)
+
+				// Random points are worse than the optimum.
+				for i := 0; i < 100; i++ {
+					x := f.New(rng).([]float64)
+					assert.Greater(t, f.Fitness(x, nil), fopt, "%s %s: f(%v)", f, variant, x)
+				}
+			}
+		}
+	}
+}
+
+func TestFunctionsValues(t *testing.T) {
+	tests := []struct {
+		name string
+		x    []float64
+		want float64
+	}{
+		{"sphere", []float64{1, 2}, 5},
+		{"ellipsoid", []float64{1, 1}, 1 + 1e6},
+		{"rosenbrock", []float64{0, 0}, 1},
+		{"rastrigin", []float64{1, 0}, 1},
+		{"ackley", []float64{0, 0}, 0},
+		{"griewank", []float64{0, 0}, 0},
+		{"styblinski-tang", []float64{0, 1}, -5},
+		{"levy", []float64{1, 1}, 0},
+		{"zakharov", []float64{1, 1}, 2 + 1.5*1.5 + math.Pow(1.5, 4)},
+		{"schwefel", []float64{0, 0}, 2 * schwefelMin},
+	}
+	for _, tt := range tests {
+		f, err := New(tt.name, len(tt.x))
+		require.NoError(t, err)
+		assert.InDelta(t, tt.want, f.Value(tt.x), 1e-12, "%s(%v)", tt.name, tt.x)
+	}
+}
+
+func TestNewErrors(t *testing.T) {
+	_, err := New("foo", 2)
+	assert.EqualError(t, err, `unknown function "foo"`)
+	_, err = New("rosenbrock", 1)
+	assert.EqualError(t, err, "rosenbrock: invalid dimension 1")
+	_, err = New("lunacek", 1)
+	assert.EqualError(t, err, "lunacek: invalid dimension 1")
+	_, err = New("sphere", 2, Shift(nil))
+	assert.EqualError(t, err, "sphere: nil random number generator")
+}
+
+func TestRotation(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	const n = 8
+	m := randomRotation(n, rng)
+	for i := 0; i < n; i++ {
+		for j := 0; j < n; j++ {
+			want := 0.0
+			if i == j {
+				want = 1
+			}
+			assert.InDelta(t, want, dot(m[i], m[j]), 1e-12)
+		}
+	}
+}
+
+func TestGray(t *testing.T) {
+	f, err := New("rastrigin", 3)
+	require.NoError(t, err)
+	g, err := NewGray(f, 10)
+	require.NoError(t, err)
+	require.Equal(t, uint(30), g.Len())
+
+	// Gray-encode 0, 1023 and 512.
+	bs := bitstring.New(30)
+	for i, v := range []uint{0, 1023, 512} {
+		bs.SetUintn(10, uint(i)*10, v^(v>>1))
+	}
+	x := g.Decode(bs)
+	assert.InDelta(t, -5.12, x[0], 1e-12)
+	assert.InDelta(t, 5.12, x[1], 1e-12)
+	assert.InDelta(t, -5.12+512*10.24/1023, x[2], 1e-12)
+	assert.Equal(t, f.Fitness(x, nil), g.Fitness(bs, nil))
+
+	_, err = NewGray(f, 0)
+	assert.Error(t, err)
+	_, err = NewGray(f, 65)
+	assert.Error(t, err)
+}
+
+func TestGrayEvolve(t *testing.T) {
+	f, err := New("sphere", 4)
+	require.NoError(t, err)
+	g, err := NewGray(f, 16)
+	require.NoError(t, err)
+
+	// The best of a random population is suboptimal, but decoded vectors are
+	// within the domain.
+	rng := rand.New(rand.NewSource(2))
+	best := math.Inf(1)
+	for i := 0; i < 100; i++ {
+		bs := g.New(rng).(*bitstring.Bitstring)
+		for _, xi := range g.Decode(bs) {
+			require.True(t, xi >= -5.12 && xi <= 5.12)
+		}
+		best = math.Min(best, g.Fitness(bs, nil))
+	}
+	assert.Greater(t, best, 0.0)
+}
@@ -0,0 +1,171 @@
+package continuous
+
+import "math"
+
+// definition defines a benchmark function, whose global minimum is at
+// (argmin, ..., argmin) and has value fmin×dimension.
+type definition struct {
+	f            func(x []float64) float64
+	lower, upper float64 // search domain, in every dimension
+	argmin       float64
+	fmin         float64
+	minDim       int
+}
+
+var definitions = map[string]definition{
+	"sphere":          {f: sphere, lower: -5.12, upper: 5.12},
+	"ellipsoid":       {f: ellipsoid, lower: -5, upper: 5},
+	"rosenbrock":      {f: rosenbrock, lower: -5, upper: 10, argmin: 1, minDim: 2},
+	"rastrigin":       {f: rastrigin, lower: -5.12, upper: 5.12},
+	"ackley":          {f: ackley, lower: -32.768, upper: 32.768},
+	"griewank":        {f: griewank, lower: -600, upper: 600},
+	"schwefel":        {f: schwefel, lower: -500, upper: 500, argmin: schwefelArgmin},
+	"lunacek":         {f: lunacek, lower: -5.12, upper: 5.12, argmin: lunacekMu0, minDim: 2},
+	"styblinski-tang": {f: styblinskiTang, lower: -5, upper: 5, argmin: styblinskiTangArgmin, fmin: styblinskiTangMin},
+	"levy":            {f: levy, lower: -10, upper: 10, argmin: 1},
+	"zakharov":        {f: zakharov, lower: -5, upper: 10},
+}
+
+// sphere is the sum of the squares of the coordinates.
+func sphere(x []float64) float64 {
+	sum := 0.0
+	for _, xi := range x {
+		sum += xi * xi
+	}
+	return sum
+}
+
+// ellipsoid is an ill-conditioned sphere, the conditioning is 10⁶.
+func ellipsoid(x []float64) float64 {
+	if len(x) == 1 {
+		return x[0] * x[0]
+	}
+	sum := 0.0
+	for i, xi := range x {
+		sum += math.Pow(10, 6*float64(i)/float64(len(x)-1)) * xi * xi
+	}
+	return sum
+}
+
+// rosenbrock has its minimum in a narrow curved valley.
+func rosenbrock(x []float64) float64 {
+	sum := 0.0
+	for i := 0; i < len(x)-1; i++ {
+		a, b := x[i+1]-x[i]*x[i], 1-x[i]
+		sum += 100*a*a + b*b
+	}
+	return sum
+}
+
+// rastrigin is a sphere with a regular grid of local minima.
+func rastrigin(x []float64) float64 {
+	sum := 10 * float64(len(x))
+	for _, xi := range x {
+		sum += xi*xi - 10*math.Cos(2*math.Pi*xi)
+	}
+	return sum
+}
+
+// ackley is nearly flat far from the origin, with many local minima.
+func ackley(x []float64) float64 {
+	var sq, cos float64
+	for _, xi := range x {
+		sq += xi * xi
+		cos += math.Cos(2 * math.Pi * xi)
+	}
+	n := float64(len(x))
+	return -20*math.Exp(-0.2*math.Sqrt(sq/n)) - math.Exp(cos/n) + 20 + math.E
+}
+
+// griewank has many widespread and regularly distributed local minima.
+func griewank(x []float64) float64 {
+	sum, prod := 0.0, 1.0
+	for i, xi := range x {
+		sum += xi * xi / 4000
+		prod *= math.Cos(xi / math.Sqrt(float64(i+1)))
+	}
+	return 1 + sum - prod
+}
+
+const (
+	schwefelArgmin = 420.9687462275036
+	schwefelMin    = 418.9828872724339 // -f(argmin) per dimension, without offset
+)
+
+// schwefel is deceptive, the second best local minimum is far from the global
+// one, which is near the bounds of the domain.
+//
+// The function is unbounded outside of the domain, coordinates are therefore
+// clamped to the domain, and a quadratic penalty is added for each coordinate
+// outside of it, which is required by rotations.
+func schwefel(x []float64) float64 {
+	sum := schwefelMin * float64(len(x))
+	for _, xi := range x {
+		if d := math.Abs(xi) - 500; d > 0 {
+			sum += d * d
+			xi = math.Copysign(500, xi)
+		}
+		sum -= xi * math.Sin(math.Sqrt(math.Abs(xi)))
+	}
+	return sum
+}
+
+const lunacekMu0 = 2.5
+
+// lunacek is the double-funnel bi-Rastrigin function of Lunacek et al. The
+// global minimum is in the smaller funnel, centered on lunacekMu0. It's only
+// defined from dimension 2, below which s is negative.
+func lunacek(x []float64) float64 {
+	n := float64(len(x))
+	s := 1 - 1/(2*math.Sqrt(n+20)-8.2)
+	mu1 := -math.Sqrt((lunacekMu0*lunacekMu0 - 1) / s)
+
+	var sph0, sph1, ras float64
+	for _, xi := range x {
+		sph0 += (xi - lunacekMu0) * (xi - lunacekMu0)
+		sph1 += (xi - mu1) * (xi - mu1)
+		ras += 1 - math.Cos(2*math.Pi*(xi-lunacekMu0))
+	}
+	return math.Min(sph0, n+s*sph1) + 10*ras
+}
+
+const (
+	styblinskiTangArgmin = -2.903534027771178
+	styblinskiTangMin    = -39.16616570377142
+)
+
+// styblinskiTang is a multimodal separable function.
+func styblinskiTang(x []float64) float64 {
+	sum := 0.0
+	for _, xi := range x {
+		x2 := xi * xi
+		sum += x2*x2 - 16*x2 + 5*xi
+	}
+	return sum / 2
+}
+
+// levy is multimodal, its global minimum is at (1, ..., 1).
+func levy(x []float64) float64 {
+	w := func(i int) float64 { return 1 + (x[i]-1)/4 }
+	sin2 := func(v float64) float64 { s := math.Sin(v); return s * s }
+
+	n := len(x)
+	sum := sin2(math.Pi * w(0))
+	for i := 0; i < n-1; i++ {
+		wi := w(i)
+		sum += (wi - 1) * (wi - 1) * (1 + 10*sin2(math.Pi*wi+1))
+	}
+	wn := w(n - 1)
+	return sum + (wn-1)*(wn-1)*(1+sin2(2*math.Pi*wn))
+}
+
+// zakharov is unimodal, with a plate-shaped valley.
+func zakharov(x []float64) float64 {
+	var sq, lin float64
+	for i, xi := range x {
+		sq += xi * xi
+		lin += 0.5 * float64(i+1) * xi
+	}
+	lin2 := lin * lin
+	return sq + lin2 + lin2*lin2
+}
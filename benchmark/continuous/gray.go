@@ -0,0 +1,65 @@
+package continuous
+
+import (
+	"fmt"
+	"math"
+	"math/bits"
+	"math/rand"
+
+	"github.com/arl/evolve/pkg/bitstring"
+)
+
+// Gray evaluates a Function on *bitstring.Bitstring candidates, made of one
+// Gray-coded unsigned integer per dimension, that is linearly mapped onto the
+// search domain.
+//
+// Gray is safe for concurrent use.
+type Gray struct {
+	f    *Function
+	bits uint
+}
+
+// NewGray returns a Gray evaluator of f, encoding every coordinate on nbits
+// bits. nbits must be in [1, bits.UintSize].
+func NewGray(f *Function, nbits uint) (*Gray, error) {
+	if nbits < 1 || nbits > bits.UintSize {
+		return nil, fmt.Errorf("invalid number of bits per coordinate: %d", nbits)
+	}
+	return &Gray{f: f, bits: nbits}, nil
+}
+
+// Function returns the evaluated function.
+func (g *Gray) Function() *Function { return g.f }
+
+// Len returns the length of the bit strings.
+func (g *Gray) Len() uint { return uint(g.f.dim) * g.bits }
+
+// Decode decodes the bit string bs into a vector.
+func (g *Gray) Decode(bs *bitstring.Bitstring) []float64 {
+	if uint(bs.Len()) != g.Len() {
+		panic(fmt.Sprintf("%s: %d-bit string, want %d", g.f, bs.Len(), g.Len()))
+	}
+	lower, upper := g.f.Bounds()
+	max := float64(uint64(math.MaxUint64) >> (64 - g.bits))
+	x := make([]float64, g.f.dim)
+	for i := range x {
+		v := bs.Grayn(g.bits, uint(i)*g.bits)
+		x[i] = lower + float64(v)/max*(upper-lower)
+	}
+	return x
+}
+
+// Fitness implements evolve.Evaluator for *bitstring.Bitstring candidates. It
+// returns the fitness of the decoded vector (see Function.Fitness).
+func (g *Gray) Fitness(cand interface{}, pop []interface{}) float64 {
+	return g.f.Fitness(g.Decode(cand.(*bitstring.Bitstring)), nil)
+}
+
+// IsNatural implements evolve.Evaluator, it returns false.
+func (g *Gray) IsNatural() bool { return false }
+
+// New implements evolve.Factory, it returns a random bit string of the right
+// length.
+func (g *Gray) New(rng *rand.Rand) interface{} {
+	return bitstring.Random(g.Len(), rng)
+}
@@ -0,0 +1,49 @@
+package tsp
+
+import (
+	"embed"
+	"fmt"
+	"sort"
+)
+
+//go:embed instances/*.tsp
+var instances embed.FS
+
+// Optima holds the optimal tour lengths of the bundled TSPLIB instances,
+// indexed by name.
+var Optima = map[string]int{
+	"burma14":   3323,
+	"ulysses16": 6859,
+	"gr17":      2085,
+	"att48":     10628,
+	"berlin52":  7542,
+}
+
+// BundledNames returns the sorted names of the bundled instances.
+func BundledNames() []string {
+	names := make([]string, 0, len(Optima))
+	for name := range Optima {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Bundled returns the bundled TSPLIB instance named name. The length of its
+// optimal tours is Optima[name].
+func Bundled(name string) (*Instance, error) {
+	if _, ok := Optima[name]; !ok {
+		return nil, fmt.Errorf("unknown instance %q", name)
+	}
+	f, err := instances.Open("instances/" + name + ".tsp")
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	inst, err := Parse(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", name, err)
+	}
+	return inst, nil
+}
@@ -0,0 +1,170 @@
+package tsp
+
+import (
+	"math/rand"
+
+	"github.com/arl/evolve/generator"
+)
+
+// TwoOpt is a local search operator improving tours with 2-opt moves: two
+// edges of the tour are replaced by the two edges that reconnect it the other
+// way, reversing the path between them, if that shortens the tour.
+//
+// Each selected tour is improved with probability Probability, by applying
+// improving moves until none exists, or MaxPasses passes over the tour have
+// been made. Selected tours are not modified, improved tours are copies.
+type TwoOpt struct {
+	Instance    *Instance
+	Probability generator.Float
+
+	// MaxPasses is the maximum number of passes over a tour, 0 meaning no
+	// limit.
+	MaxPasses int
+}
+
+// NewTwoOpt returns a TwoOpt operator improving every tour of inst up to a
+// local optimum.
+func NewTwoOpt(inst *Instance) *TwoOpt {
+	return &TwoOpt{Instance: inst, Probability: generator.ConstFloat64(1)}
+}
+
+// Apply applies the operator to each selected tour.
+func (op *TwoOpt) Apply(sel []interface{}, rng *rand.Rand) []interface{} {
+	return improve(sel, rng, op.Probability, op.MaxPasses, op.Instance.twoOpt)
+}
+
+// String returns "2-opt".
+func (op *TwoOpt) String() string { return "2-opt" }
+
+// OrOpt is a local search operator improving tours with Or-opt moves: a
+// segment of 1 to 3 consecutive nodes is moved, possibly reversed, to another
+// place in the tour, if that shortens the tour.
+//
+// Each selected tour is improved with probability Probability, by applying
+// improving moves until none exists, or MaxPasses passes over the tour have
+// been made. Selected tours are not modified, improved tours are copies.
+type OrOpt struct {
+	Instance    *Instance
+	Probability generator.Float
+
+	// MaxPasses is the maximum number of passes over a tour, 0 meaning no
+	// limit.
+	MaxPasses int
+}
+
+// NewOrOpt returns an OrOpt operator improving every tour of inst up to a
+// local optimum.
+func NewOrOpt(inst *Instance) *OrOpt {
+	return &OrOpt{Instance: inst, Probability: generator.ConstFloat64(1)}
+}
+
+// Apply applies the operator to each selected tour.
+func (op *OrOpt) Apply(sel []interface{}, rng *rand.Rand) []interface{} {
+	return improve(sel, rng, op.Probability, op.MaxPasses, op.Instance.orOpt)
+}
+
+// String returns "or-opt".
+func (op *OrOpt) String() string { return "or-opt" }
+
+// improve applies pass, with probability prob, to copies of the selected
+// tours, until pass reports no improvement or maxPasses is reached.
+func improve(sel []interface{}, rng *rand.Rand, prob generator.Float, maxPasses int, pass func([]int) bool) []interface{} {
+	off := make([]interface{}, len(sel))
+	for i, cand := range sel {
+		if rng.Float64() >= prob.Next() {
+			off[i] = cand
+			continue
+		}
+		tour := append([]int(nil), cand.([]int)...)
+		for n := 0; maxPasses == 0 || n < maxPasses; n++ {
+			if !pass(tour) {
+				break
+			}
+		}
+		off[i] = tour
+	}
+	return off
+}
+
+// twoOpt makes a pass of 2-opt moves over tour, and reports whether it has
+// been improved.
+func (inst *Instance) twoOpt(tour []int) bool {
+	n := len(tour)
+	improved := false
+	for i := 0; i < n-2; i++ {
+		a, b := tour[i], tour[i+1]
+		for j := i + 2; j < n; j++ {
+			if i == 0 && j == n-1 {
+				continue // adjacent edges
+			}
+			c, d := tour[j], tour[(j+1)%n]
+			delta := inst.Distance(a, c) + inst.Distance(b, d) - inst.Distance(a, b) - inst.Distance(c, d)
+			if delta < 0 {
+				reverse(tour[i+1 : j+1])
+				b = tour[i+1]
+				improved = true
+			}
+		}
+	}
+	return improved
+}
+
+// orOpt makes a pass of Or-opt moves over tour, and reports whether it has
+// been improved.
+func (inst *Instance) orOpt(tour []int) bool {
+	n := len(tour)
+	improved := false
+	for seglen := 1; seglen <= 3; seglen++ {
+		if n < seglen+3 {
+			break
+		}
+		for i := 0; i+seglen <= n; i++ {
+			// The segment is tour[i:i+seglen], between p and q.
+			first, last := tour[i], tour[i+seglen-1]
+			p, q := tour[(i+n-1)%n], tour[(i+seglen)%n]
+			gain := inst.Distance(p, first) + inst.Distance(last, q) - inst.Distance(p, q)
+
+			// Look for an edge (u, v), outside of the segment and not
+			// adjacent to it, where inserting the segment costs less.
+			for k := 0; k < n-seglen-1; k++ {
+				j := (i + seglen + k) % n
+				u, v := tour[j], tour[(j+1)%n]
+				cost := inst.Distance(u, first) + inst.Distance(last, v) - inst.Distance(u, v)
+				rcost := inst.Distance(u, last) + inst.Distance(first, v) - inst.Distance(u, v)
+				if cost < gain || rcost < gain {
+					moveSegment(tour, i, seglen, u, rcost < cost)
+					improved = true
+					break
+				}
+			}
+		}
+	}
+	return improved
+}
+
+// moveSegment moves the segment of tour of length seglen starting at index i
+// after node u, reversing it if rev is true.
+func moveSegment(tour []int, i, seglen, u int, rev bool) {
+	seg := append([]int(nil), tour[i:i+seglen]...)
+	if rev {
+		reverse(seg)
+	}
+	rest := make([]int, 0, len(tour))
+	rest = append(rest, tour[:i]...)
+	rest = append(rest, tour[i+seglen:]...)
+
+	k := 0
+	for k < len(rest) && rest[k] != u {
+		k++
+	}
+	k++
+	n := copy(tour, rest[:k])
+	n += copy(tour[n:], seg)
+	copy(tour[n:], rest[k:])
+}
+
+func reverse(s []int) {
+	for i, j := 0, len(s)-1; i < j; i, j = i+1, j-1 {
+		s[i], s[j] = s[j], s[i]
+	}
+}
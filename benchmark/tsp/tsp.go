@@ -0,0 +1,89 @@
+// Package tsp provides support for the symmetric Travelling Salesman Problem:
+// a parser of instances in the TSPLIB format, an evaluator of tours, local
+// search operators, and a set of bundled TSPLIB instances of known optimal
+// tour length.
+//
+// Tours are []int candidates, permutations of the 0-based indices of the
+// nodes, that can be evolved with permutation operators such as xover.PMX and
+// mutation.ListOrder, and improved with the TwoOpt and OrOpt local search
+// operators.
+package tsp
+
+import (
+	"fmt"
+	"math/rand"
+)
+
+// Instance is a symmetric TSP instance. Distances between nodes are integers,
+// and precomputed in a matrix, so evaluating a tour of n nodes is O(n), but an
+// Instance requires O(n²) memory.
+//
+// An Instance is an evolve.Evaluator of tours, whose fitness is their length,
+// and an evolve.Factory generating random tours. It is safe for concurrent
+// use.
+type Instance struct {
+	Name    string
+	Comment string
+
+	// EdgeWeightType is the TSPLIB type of distance, one of EUC_2D, GEO,
+	// ATT or EXPLICIT.
+	EdgeWeightType string
+
+	// Coords holds the coordinates of the nodes, it's nil for instances with
+	// explicit distances.
+	Coords [][2]float64
+
+	n    int
+	dist []int // n×n distance matrix
+}
+
+// Dim returns the number of nodes.
+func (inst *Instance) Dim() int { return inst.n }
+
+// Distance returns the distance between the nodes of indices i and j.
+func (inst *Instance) Distance(i, j int) int { return inst.dist[i*inst.n+j] }
+
+// TourLength returns the length of the closed tour, that goes through the
+// nodes in order, then back to the first one.
+func (inst *Instance) TourLength(tour []int) int {
+	if len(tour) != inst.n {
+		panic(fmt.Sprintf("tsp: %d-node tour, want %d", len(tour), inst.n))
+	}
+	length := inst.dist[tour[len(tour)-1]*inst.n+tour[0]]
+	for i := 1; i < len(tour); i++ {
+		length += inst.dist[tour[i-1]*inst.n+tour[i]]
+	}
+	return length
+}
+
+// Fitness implements evolve.Evaluator, it returns the length of the tour.
+func (inst *Instance) Fitness(cand interface{}, pop []interface{}) float64 {
+	return float64(inst.TourLength(cand.([]int)))
+}
+
+// IsNatural implements evolve.Evaluator, it returns false.
+func (inst *Instance) IsNatural() bool { return false }
+
+// New implements evolve.Factory, it returns a random tour.
+func (inst *Instance) New(rng *rand.Rand) interface{} { return rng.Perm(inst.n) }
+
+// String returns the name and the number of nodes of the instance.
+func (inst *Instance) String() string {
+	return fmt.Sprintf("%s (%d nodes)", inst.Name, inst.n)
+}
+
+// checkPermutation returns an error if tour is not a permutation of
+// [0, len(tour)).
+func checkPermutation(tour []int) error {
+	seen := make([]bool, len(tour))
+	for _, node := range tour {
+		if node < 0 || node >= len(tour) {
+			return fmt.Errorf("invalid node %d in %d-node tour", node+1, len(tour))
+		}
+		if seen[node] {
+			return fmt.Errorf("node %d visited twice", node+1)
+		}
+		seen[node] = true
+	}
+	return nil
+}
@@ -0,0 +1,317 @@
+package tsp
+
+import (
+	"math/rand"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/arl/evolve"
+	"github.com/arl/evolve/generator"
+)
+
+var (
+	_ evolve.Evaluator = (*Instance)(nil)
+	_ evolve.Factory   = (*Instance)(nil)
+	_ evolve.Operator  = (*TwoOpt)(nil)
+	_ evolve.Operator  = (*OrOpt)(nil)
+)
+
+func TestBundled(t *testing.T) {
+	for _, name := range BundledNames() {
+		t.Run(name, func(t *testing.T) {
+			inst, err := Bundled(name)
+			require.NoError(t, err)
+			assert.Contains(t, inst.Name, name)
+			for i := 0; i < inst.Dim(); i++ {
+				assert.Zero(t, inst.Distance(i, i))
+				for j := 0; j < i; j++ {
+					assert.Equal(t, inst.Distance(i, j), inst.Distance(j, i))
+				}
+			}
+
+			// Instances small enough to be solved exactly.
+			if inst.Dim() <= 17 {
+				assert.Equal(t, Optima[name], heldKarp(inst))
+			}
+		})
+	}
+
+	_, err := Bundled("foo")
+	assert.EqualError(t, err, `unknown instance "foo"`)
+}
+
+func TestOptimalTours(t *testing.T) {
+	for _, name := range []string{"att48", "berlin52"} {
+		t.Run(name, func(t *testing.T) {
+			inst, err := Bundled(name)
+			require.NoError(t, err)
+
+			f, err := os.Open("testdata/" + name + ".opt.tour")
+			require.NoError(t, err)
+			defer f.Close()
+
+			tour, err := ParseTour(f)
+			require.NoError(t, err)
+			assert.Equal(t, Optima[name], inst.TourLength(tour))
+			assert.Equal(t, float64(Optima[name]), inst.Fitness(tour, nil))
+		})
+	}
+}
+
+// heldKarp returns the length of the optimal tours of inst, computed by
+// dynamic programming in O(2ⁿn²).
+func heldKarp(inst *Instance) int {
+	n := inst.Dim() - 1 // node n is the start of the tour
+	const inf = int(^uint(0) >> 1)
+	cost := make([]int, (1<<n)*n)
+	for i := range cost {
+		cost[i] = inf
+	}
+	for i := 0; i < n; i++ {
+		cost[(1<<i)*n+i] = inst.Distance(n, i)
+	}
+	for set := 1; set < 1<<n; set++ {
+		for last := 0; last < n; last++ {
+			c := cost[set*n+last]
+			if c == inf {
+				continue
+			}
+			for next := 0; next < n; next++ {
+				if set&(1<<next) != 0 {
+					continue
+				}
+				i := (set|1<<next)*n + next
+				if d := c + inst.Distance(last, next); d < cost[i] {
+					cost[i] = d
+				}
+			}
+		}
+	}
+	best := inf
+	for last := 0; last < n; last++ {
+		if d := cost[(1<<n-1)*n+last] + inst.Distance(last, n); d < best {
+			best = d
+		}
+	}
+	return best
+}
+
+const explicitHeader = `NAME: small
+TYPE: TSP
+DIMENSION: 4
+EDGE_WEIGHT_TYPE: EXPLICIT
+`
+
+func TestParseExplicit(t *testing.T) {
+	want := [][]int{
+		{0, 1, 2, 3},
+		{1, 0, 4, 5},
+		{2, 4, 0, 6},
+		{3, 5, 6, 0},
+	}
+	formats := map[string]string{
+		"FULL_MATRIX":    "0 1 2 3\n1 0 4 5\n2 4 0 6\n3 5 6 0",
+		"UPPER_ROW":      "1 2 3\n4 5\n6",
+		"LOWER_ROW":      "1\n2 4\n3 5 6",
+		"UPPER_DIAG_ROW": "0 1 2 3\n0 4 5\n0 6\n0",
+		"LOWER_DIAG_ROW": "0\n1 0\n2 4 0\n3 5 6 0",
+		"UPPER_COL":      "1\n2 4\n3 5 6",
+		"LOWER_COL":      "1 2 3 4 5 6", // numbers can span lines
+		"UPPER_DIAG_COL": "0\n1 0\n2 4 0\n3 5 6 0",
+		"LOWER_DIAG_COL": "0 1 2 3\n0 4 5\n0 6\n0",
+	}
+	for format, weights := range formats {
+		t.Run(format, func(t *testing.T) {
+			src := explicitHeader + "EDGE_WEIGHT_FORMAT: " + format + "\nEDGE_WEIGHT_SECTION\n" + weights + "\nEOF\n"
+			inst, err := Parse(strings.NewReader(src))
+			require.NoError(t, err)
+			assert.Equal(t, "small", inst.Name)
+			assert.Equal(t, Explicit, inst.EdgeWeightType)
+			require.Equal(t, 4, inst.Dim())
+			for i := range want {
+				for j := range want[i] {
+					assert.Equal(t, want[i][j], inst.Distance(i, j), "distance(%d, %d)", i, j)
+				}
+			}
+			assert.Equal(t, 1+4+6+3, inst.TourLength([]int{0, 1, 2, 3}))
+		})
+	}
+}
+
+func TestParseCoords(t *testing.T) {
+	src := `NAME: square
+TYPE: TSP
+DIMENSION: 4
+EDGE_WEIGHT_TYPE: EUC_2D
+NODE_COORD_SECTION
+1 0 0
+2 3 0
+3 3 4
+4 0 4
+EOF
+`
+	inst, err := Parse(strings.NewReader(src))
+	require.NoError(t, err)
+	assert.Equal(t, [][2]float64{{0, 0}, {3, 0}, {3, 4}, {0, 4}}, inst.Coords)
+	assert.Equal(t, 5, inst.Distance(0, 2))
+	assert.Equal(t, 14, inst.TourLength([]int{0, 1, 2, 3}))
+	assert.Equal(t, 18, inst.TourLength([]int{0, 2, 1, 3}))
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name, src, wantErr string
+	}{
+		{
+			name:    "type",
+			src:     "TYPE: ATSP\nDIMENSION: 2\nEDGE_WEIGHT_TYPE: EXPLICIT\nEDGE_WEIGHT_FORMAT: FULL_MATRIX\nEDGE_WEIGHT_SECTION\n0 1 1 0\n",
+			wantErr: `unsupported problem type "ATSP"`,
+		},
+		{
+			name:    "edge weight type",
+			src:     "TYPE: TSP\nDIMENSION: 2\nEDGE_WEIGHT_TYPE: CEIL_2D\nNODE_COORD_SECTION\n1 0 0\n2 1 1\n",
+			wantErr: `unsupported EDGE_WEIGHT_TYPE "CEIL_2D"`,
+		},
+		{
+			name:    "edge weight format",
+			src:     "TYPE: TSP\nDIMENSION: 2\nEDGE_WEIGHT_TYPE: EXPLICIT\nEDGE_WEIGHT_FORMAT: FUNCTION\nEDGE_WEIGHT_SECTION\n",
+			wantErr: `line 5: unsupported EDGE_WEIGHT_FORMAT "FUNCTION"`,
+		},
+		{
+			name:    "dimension",
+			src:     "TYPE: TSP\nEDGE_WEIGHT_TYPE: EUC_2D\nNODE_COORD_SECTION\n1 0 0\n",
+			wantErr: `line 3: invalid DIMENSION ""`,
+		},
+		{
+			name:    "missing coords",
+			src:     "TYPE: TSP\nDIMENSION: 2\nEDGE_WEIGHT_TYPE: EUC_2D\nEOF\n",
+			wantErr: "missing NODE_COORD_SECTION",
+		},
+		{
+			name:    "missing weights",
+			src:     "TYPE: TSP\nDIMENSION: 2\nEDGE_WEIGHT_TYPE: EXPLICIT\nEOF\n",
+			wantErr: "missing EDGE_WEIGHT_SECTION",
+		},
+		{
+			name:    "node number",
+			src:     "TYPE: TSP\nDIMENSION: 2\nEDGE_WEIGHT_TYPE: EUC_2D\nNODE_COORD_SECTION\n1 0 0\n3 1 1\n",
+			wantErr: "line 6: node 3, want node 2",
+		},
+		{
+			name:    "truncated",
+			src:     "TYPE: TSP\nDIMENSION: 2\nEDGE_WEIGHT_TYPE: EUC_2D\nNODE_COORD_SECTION\n1 0 0\n2 1\n",
+			wantErr: "line 6: unexpected EOF",
+		},
+		{
+			name:    "invalid number",
+			src:     "TYPE: TSP\nDIMENSION: 2\nEDGE_WEIGHT_TYPE: EUC_2D\nNODE_COORD_SECTION\n1 0 0\n2 1 x\n",
+			wantErr: `line 6: invalid number "x"`,
+		},
+		{
+			name:    "section",
+			src:     "TYPE: TSP\nDIMENSION: 2\nFIXED_EDGES_SECTION\n",
+			wantErr: "line 3: unsupported section FIXED_EDGES_SECTION",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse(strings.NewReader(tt.src))
+			assert.EqualError(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestParseTour(t *testing.T) {
+	tour, err := ParseTour(strings.NewReader("NAME: t\nTYPE: TOUR\nDIMENSION: 4\nTOUR_SECTION\n1\n3\n2\n4\n-1\nEOF\n"))
+	require.NoError(t, err)
+	assert.Equal(t, []int{0, 2, 1, 3}, tour)
+
+	_, err = ParseTour(strings.NewReader("TYPE: TOUR\nTOUR_SECTION\n1 3 1 2\n-1\n"))
+	assert.EqualError(t, err, "node 1 visited twice")
+
+	_, err = ParseTour(strings.NewReader("TYPE: TSP\nTOUR_SECTION\n1 2\n-1\n"))
+	assert.EqualError(t, err, `unsupported type "TSP"`)
+}
+
+func isPermutation(tour []int, n int) bool {
+	return len(tour) == n && checkPermutation(tour) == nil
+}
+
+func TestLocalSearch(t *testing.T) {
+	inst, err := Bundled("berlin52")
+	require.NoError(t, err)
+
+	rng := rand.New(rand.NewSource(1))
+	sel := make([]interface{}, 20)
+	for i := range sel {
+		sel[i] = inst.New(rng)
+	}
+	orig := make([][]int, len(sel))
+	for i := range sel {
+		orig[i] = append([]int(nil), sel[i].([]int)...)
+	}
+
+	ops := []evolve.Operator{NewTwoOpt(inst), NewOrOpt(inst)}
+	for _, op := range ops {
+		t.Run(op.(interface{ String() string }).String(), func(t *testing.T) {
+			off := op.Apply(sel, rng)
+			require.Len(t, off, len(sel))
+			for i := range off {
+				tour := off[i].([]int)
+				require.True(t, isPermutation(tour, inst.Dim()))
+				assert.Less(t, inst.TourLength(tour), inst.TourLength(orig[i]))
+
+				// The selected tours are not modified.
+				assert.Equal(t, orig[i], sel[i])
+			}
+		})
+	}
+
+	// 2-opt stops on local optima.
+	off := NewTwoOpt(inst).Apply(sel[:1], rng)
+	tour := off[0].([]int)
+	assert.False(t, inst.twoOpt(append([]int(nil), tour...)))
+
+	// Probability 0 leaves tours untouched.
+	op := NewOrOpt(inst)
+	op.Probability = generator.ConstFloat64(0)
+	off = op.Apply(sel, rng)
+	for i := range off {
+		assert.Equal(t, sel[i], off[i])
+	}
+
+	// A single pass may not reach a local optimum, but improves the tour.
+	two := NewTwoOpt(inst)
+	two.MaxPasses = 1
+	off = two.Apply(sel[:1], rng)
+	assert.Less(t, inst.TourLength(off[0].([]int)), inst.TourLength(orig[0]))
+}
+
+func TestMoveSegment(t *testing.T) {
+	tests := []struct {
+		i, seglen, u int
+		rev          bool
+		want         []int
+	}{
+		{i: 1, seglen: 2, u: 4, want: []int{0, 3, 4, 1, 2, 5}},
+		{i: 1, seglen: 2, u: 4, rev: true, want: []int{0, 3, 4, 2, 1, 5}},
+		{i: 3, seglen: 3, u: 0, want: []int{0, 3, 4, 5, 1, 2}},
+		{i: 4, seglen: 1, u: 0, rev: true, want: []int{0, 4, 1, 2, 3, 5}},
+		{i: 0, seglen: 1, u: 5, want: []int{1, 2, 3, 4, 5, 0}},
+	}
+	for _, tt := range tests {
+		tour := []int{0, 1, 2, 3, 4, 5}
+		moveSegment(tour, tt.i, tt.seglen, tt.u, tt.rev)
+		assert.Equal(t, tt.want, tour, "moveSegment(%d, %d, %d, %t)", tt.i, tt.seglen, tt.u, tt.rev)
+	}
+}
+
+func TestTourLengthPanics(t *testing.T) {
+	inst, err := Bundled("burma14")
+	require.NoError(t, err)
+	assert.Panics(t, func() { inst.TourLength([]int{0, 1, 2}) })
+}
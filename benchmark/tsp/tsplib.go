@@ -0,0 +1,371 @@
+package tsp
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Edge weight types supported by Parse.
+const (
+	Euc2D    = "EUC_2D"
+	Geo      = "GEO"
+	Att      = "ATT"
+	Explicit = "EXPLICIT"
+)
+
+// Load parses the TSPLIB file at path (see Parse).
+func Load(path string) (*Instance, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	inst, err := Parse(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	return inst, nil
+}
+
+// Parse parses a symmetric TSP instance in the TSPLIB format, and computes its
+// distance matrix.
+//
+// Supported edge weight types are EUC_2D, GEO, ATT and EXPLICIT. Explicit
+// weights can be given in any of the FULL_MATRIX, UPPER_ROW, LOWER_ROW,
+// UPPER_DIAG_ROW, LOWER_DIAG_ROW formats, and their column-wise equivalents.
+func Parse(r io.Reader) (*Instance, error) {
+	p := parser{sc: bufio.NewScanner(r)}
+	inst, err := p.parse()
+	if err != nil {
+		if p.line > 0 {
+			return nil, fmt.Errorf("line %d: %v", p.line, err)
+		}
+		return nil, err
+	}
+	return inst, nil
+}
+
+// ParseTour parses a tour in the TSPLIB format, such as the optimal tours
+// provided with the TSPLIB instances. The returned tour holds the 0-based
+// indices of the nodes.
+func ParseTour(r io.Reader) ([]int, error) {
+	p := parser{sc: bufio.NewScanner(r)}
+	tour, err := p.parseTour()
+	if err != nil {
+		if p.line > 0 {
+			return nil, fmt.Errorf("line %d: %v", p.line, err)
+		}
+		return nil, err
+	}
+	return tour, nil
+}
+
+type parser struct {
+	sc     *bufio.Scanner
+	line   int
+	fields []string // remaining fields of the current line
+	header map[string]string
+}
+
+// next returns the next line, or io.EOF.
+func (p *parser) next() (string, error) {
+	if !p.sc.Scan() {
+		if err := p.sc.Err(); err != nil {
+			return "", err
+		}
+		return "", io.EOF
+	}
+	p.line++
+	return strings.TrimSpace(p.sc.Text()), nil
+}
+
+// number returns the next number in a data section, that can span several
+// lines.
+func (p *parser) number() (float64, error) {
+	for len(p.fields) == 0 {
+		line, err := p.next()
+		if err == io.EOF {
+			return 0, io.ErrUnexpectedEOF
+		}
+		if err != nil {
+			return 0, err
+		}
+		p.fields = strings.Fields(line)
+	}
+	f, err := strconv.ParseFloat(p.fields[0], 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid number %q", p.fields[0])
+	}
+	p.fields = p.fields[1:]
+	return f, nil
+}
+
+// parseSections parses the file, calling section for each data section. A
+// section callback must consume all the numbers of the section.
+func (p *parser) parseSections(section func(name string) error) error {
+	p.header = make(map[string]string)
+	for {
+		line, err := p.next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if line == "" {
+			continue
+		}
+		if line == "EOF" {
+			return nil
+		}
+		if i := strings.IndexByte(line, ':'); i >= 0 {
+			key := strings.TrimSpace(line[:i])
+			p.header[key] = strings.TrimSpace(line[i+1:])
+			continue
+		}
+		if err := section(line); err != nil {
+			return err
+		}
+		if len(p.fields) != 0 {
+			return fmt.Errorf("%s: unexpected %q", line, p.fields[0])
+		}
+	}
+}
+
+func (p *parser) parse() (*Instance, error) {
+	var (
+		n      int
+		coords [][2]float64
+		dist   []int
+	)
+	dimension := func() (int, error) {
+		if n > 0 {
+			return n, nil
+		}
+		dim, err := strconv.Atoi(p.header["DIMENSION"])
+		if err != nil || dim < 2 {
+			return 0, fmt.Errorf("invalid DIMENSION %q", p.header["DIMENSION"])
+		}
+		n = dim
+		return n, nil
+	}
+
+	err := p.parseSections(func(name string) error {
+		switch name {
+		case "NODE_COORD_SECTION":
+			dim, err := dimension()
+			if err != nil {
+				return err
+			}
+			coords = make([][2]float64, dim)
+			for i := 0; i < dim; i++ {
+				id, err := p.number()
+				if err != nil {
+					return err
+				}
+				if int(id) != i+1 {
+					return fmt.Errorf("node %v, want node %d", id, i+1)
+				}
+				for j := range coords[i] {
+					if coords[i][j], err = p.number(); err != nil {
+						return err
+					}
+				}
+			}
+		case "EDGE_WEIGHT_SECTION":
+			dim, err := dimension()
+			if err != nil {
+				return err
+			}
+			dist, err = p.weights(dim, p.header["EDGE_WEIGHT_FORMAT"])
+			if err != nil {
+				return err
+			}
+		case "DISPLAY_DATA_SECTION":
+			dim, err := dimension()
+			if err != nil {
+				return err
+			}
+			for i := 0; i < 3*dim; i++ {
+				if _, err := p.number(); err != nil {
+					return err
+				}
+			}
+		default:
+			return fmt.Errorf("unsupported section %s", name)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	p.line = 0
+
+	if typ := p.header["TYPE"]; typ != "TSP" {
+		return nil, fmt.Errorf("unsupported problem type %q", typ)
+	}
+	inst := &Instance{
+		Name:           p.header["NAME"],
+		Comment:        p.header["COMMENT"],
+		EdgeWeightType: p.header["EDGE_WEIGHT_TYPE"],
+		Coords:         coords,
+		n:              n,
+		dist:           dist,
+	}
+
+	var distance func(a, b [2]float64) int
+	switch inst.EdgeWeightType {
+	case Explicit:
+		if dist == nil {
+			return nil, errors.New("missing EDGE_WEIGHT_SECTION")
+		}
+		return inst, nil
+	case Euc2D:
+		distance = euc2D
+	case Geo:
+		distance = geo
+	case Att:
+		distance = att
+	default:
+		return nil, fmt.Errorf("unsupported EDGE_WEIGHT_TYPE %q", inst.EdgeWeightType)
+	}
+	if coords == nil {
+		return nil, errors.New("missing NODE_COORD_SECTION")
+	}
+	inst.dist = make([]int, n*n)
+	for i := 0; i < n; i++ {
+		for j := 0; j < i; j++ {
+			d := distance(coords[i], coords[j])
+			inst.dist[i*n+j], inst.dist[j*n+i] = d, d
+		}
+	}
+	return inst, nil
+}
+
+// weights reads the explicit weights of a n-node instance in the given
+// format, and returns the full distance matrix.
+func (p *parser) weights(n int, format string) ([]int, error) {
+	// Column-wise formats of symmetric matrices are equivalent to the
+	// transposed row-wise formats.
+	switch format {
+	case "UPPER_COL":
+		format = "LOWER_ROW"
+	case "LOWER_COL":
+		format = "UPPER_ROW"
+	case "UPPER_DIAG_COL":
+		format = "LOWER_DIAG_ROW"
+	case "LOWER_DIAG_COL":
+		format = "UPPER_DIAG_ROW"
+	}
+
+	// bounds returns the range of columns of row i.
+	var bounds func(i int) (int, int)
+	switch format {
+	case "FULL_MATRIX":
+		bounds = func(i int) (int, int) { return 0, n }
+	case "UPPER_ROW":
+		bounds = func(i int) (int, int) { return i + 1, n }
+	case "LOWER_ROW":
+		bounds = func(i int) (int, int) { return 0, i }
+	case "UPPER_DIAG_ROW":
+		bounds = func(i int) (int, int) { return i, n }
+	case "LOWER_DIAG_ROW":
+		bounds = func(i int) (int, int) { return 0, i + 1 }
+	default:
+		return nil, fmt.Errorf("unsupported EDGE_WEIGHT_FORMAT %q", format)
+	}
+
+	dist := make([]int, n*n)
+	for i := 0; i < n; i++ {
+		from, to := bounds(i)
+		for j := from; j < to; j++ {
+			w, err := p.number()
+			if err != nil {
+				return nil, err
+			}
+			dist[i*n+j], dist[j*n+i] = int(w), int(w)
+		}
+	}
+	return dist, nil
+}
+
+func (p *parser) parseTour() ([]int, error) {
+	var tour []int
+	err := p.parseSections(func(name string) error {
+		if name != "TOUR_SECTION" {
+			return fmt.Errorf("unsupported section %s", name)
+		}
+		for {
+			id, err := p.number()
+			if err != nil {
+				return err
+			}
+			if id == -1 {
+				return nil
+			}
+			if id < 1 || id != math.Trunc(id) {
+				return fmt.Errorf("invalid node %v", id)
+			}
+			tour = append(tour, int(id)-1)
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	p.line = 0
+	if typ := p.header["TYPE"]; typ != "TOUR" {
+		return nil, fmt.Errorf("unsupported type %q", typ)
+	}
+	if err := checkPermutation(tour); err != nil {
+		return nil, err
+	}
+	return tour, nil
+}
+
+// nint rounds x to the nearest integer.
+func nint(x float64) int { return int(x + 0.5) }
+
+func euc2D(a, b [2]float64) int {
+	dx, dy := a[0]-b[0], a[1]-b[1]
+	return nint(math.Sqrt(dx*dx + dy*dy))
+}
+
+// att is the pseudo-Euclidean distance of the att48 and att532 instances.
+func att(a, b [2]float64) int {
+	dx, dy := a[0]-b[0], a[1]-b[1]
+	r := math.Sqrt((dx*dx + dy*dy) / 10)
+	t := nint(r)
+	if float64(t) < r {
+		t++
+	}
+	return t
+}
+
+// geo is the geographical distance, in kilometers, between two points whose
+// coordinates are latitude and longitude in the DDD.MM format (degrees and
+// minutes).
+func geo(a, b [2]float64) int {
+	const rrr = 6378.388
+	lat1, lon1 := radians(a[0]), radians(a[1])
+	lat2, lon2 := radians(b[0]), radians(b[1])
+	q1 := math.Cos(lon1 - lon2)
+	q2 := math.Cos(lat1 - lat2)
+	q3 := math.Cos(lat1 + lat2)
+	return int(rrr*math.Acos(0.5*((1+q1)*q2-(1-q1)*q3)) + 1)
+}
+
+// radians converts a DDD.MM coordinate into radians, using the value of π of
+// the TSPLIB reference implementation.
+func radians(x float64) float64 {
+	const pi = 3.141592
+	deg := math.Trunc(x)
+	min := x - deg
+	return pi * (deg + 5*min/3) / 180
+}
@@ -0,0 +1,92 @@
+package evolve
+
+import (
+	"encoding/binary"
+	"math"
+	"strings"
+
+	"github.com/arl/evolve/pkg/bitstring"
+)
+
+// A Keyer is a candidate able to identify its own content.
+//
+// Implementing Keyer allows candidates of any type to be used with the fitness
+// caches, even when they are not comparable or when they are pointers.
+type Keyer interface {
+	// CacheKey returns a string identifying the content of the candidate.
+	// Two candidates with the same content must have the same key, while
+	// candidates with different contents must have different keys. The key
+	// should not depend on the process, so that it can be persisted.
+	CacheKey() string
+}
+
+// CandidateKey returns a canonical encoding of the content of cand, suitable
+// for use as a key identifying the candidate.
+//
+// Keys are built for candidates implementing Keyer and for the candidate types
+// provided by this project: string, []byte, []int, []float64 and
+// *bitstring.Bitstring. Keys are stable: they don't depend on the process nor
+// on the platform, and they are different for candidates of different types.
+//
+// ok is false if cand is of any other type.
+func CandidateKey(cand interface{}) (key string, ok bool) {
+	var sb strings.Builder
+	if !writeKey(&sb, cand) {
+		return "", false
+	}
+	return sb.String(), true
+}
+
+// writeKey writes the key of cand to w, the first byte identifies the type of
+// the candidate.
+func writeKey(w *strings.Builder, cand interface{}) bool {
+	var buf [binary.MaxVarintLen64]byte
+	writeUint := func(x uint64) {
+		n := binary.PutUvarint(buf[:], x)
+		w.Write(buf[:n])
+	}
+
+	switch c := cand.(type) {
+	case Keyer:
+		w.WriteByte('k')
+		w.WriteString(c.CacheKey())
+	case string:
+		w.WriteByte('s')
+		w.WriteString(c)
+	case []byte:
+		w.WriteByte('b')
+		w.Write(c)
+	case []int:
+		w.WriteByte('i')
+		for _, v := range c {
+			// zig-zag encoding of signed integers
+			x := int64(v)
+			writeUint(uint64(x<<1) ^ uint64(x>>63))
+		}
+	case []float64:
+		w.WriteByte('f')
+		for _, v := range c {
+			binary.LittleEndian.PutUint64(buf[:8], math.Float64bits(v))
+			w.Write(buf[:8])
+		}
+	case *bitstring.Bitstring:
+		w.WriteByte('B')
+		writeUint(uint64(c.Len()))
+
+		// Write the bits packed in bytes, least significant first, which
+		// doesn't depend on the machine word size.
+		nbytes := (c.Len() + 7) / 8
+		for _, word := range c.Data() {
+			for i := 0; i < bitstringWordSize/8 && nbytes > 0; i++ {
+				w.WriteByte(byte(word >> (8 * i)))
+				nbytes--
+			}
+		}
+	default:
+		return false
+	}
+	return true
+}
+
+// bitstringWordSize is the size in bits of the words of bitstring.Bitstring.
+const bitstringWordSize = 32 << (^uint(0) >> 63)
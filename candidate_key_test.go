@@ -0,0 +1,48 @@
+package evolve
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/arl/evolve/pkg/bitstring"
+)
+
+func TestCandidateKey(t *testing.T) {
+	bs, _ := bitstring.MakeFromString("1000000001")
+
+	tests := []struct {
+		cand interface{}
+		want string
+		ok   bool
+	}{
+		{cand: "abc", want: "sabc", ok: true},
+		{cand: []byte("abc"), want: "babc", ok: true},
+		{cand: []int{0, -1, 1, 300}, want: "i\x00\x01\x02\xd8\x04", ok: true},
+		{cand: []float64{1}, want: "f\x00\x00\x00\x00\x00\x00\xf0\x3f", ok: true},
+		{cand: bs, want: "B\x0a\x01\x02", ok: true},
+		{cand: keyer{1, 2}, want: "k1 2", ok: true},
+		{cand: 3, ok: false},
+	}
+	for _, tt := range tests {
+		key, ok := CandidateKey(tt.cand)
+		assert.Equalf(t, tt.ok, ok, "CandidateKey(%#v) ok", tt.cand)
+		assert.Equalf(t, tt.want, key, "CandidateKey(%#v)", tt.cand)
+	}
+}
+
+func TestCandidateKeyBitstring(t *testing.T) {
+	rng := rand.New(rand.NewSource(99))
+	bs1 := bitstring.Random(100, rng)
+	bs2 := bitstring.Copy(bs1)
+
+	k1, ok1 := CandidateKey(bs1)
+	k2, ok2 := CandidateKey(bs2)
+	assert.True(t, ok1 && ok2)
+	assert.Equal(t, k1, k2, "copies of a bit string should have the same key")
+
+	bs2.FlipBit(99)
+	k2, _ = CandidateKey(bs2)
+	assert.NotEqual(t, k1, k2, "different bit strings should have different keys")
+}
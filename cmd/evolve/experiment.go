@@ -0,0 +1,229 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/arl/evolve"
+	"github.com/arl/evolve/condition"
+	"github.com/arl/evolve/config"
+	"github.com/arl/evolve/engine"
+	"github.com/arl/evolve/observer"
+)
+
+// options configures the runs of the experiments.
+type options struct {
+	seeds    int    // runs per experiment
+	parallel int    // concurrent runs
+	logdir   string // per-generation logs directory, if not empty
+}
+
+// An experiment is a configuration, run over several seeds.
+type experiment struct {
+	name string
+	cfg  *config.Config
+
+	target    float64
+	hasTarget bool
+
+	runs []result
+}
+
+// result is the result of a single run.
+type result struct {
+	seed    int64
+	gens    int
+	evals   int64
+	best    float64 // best fitness ever
+	elapsed time.Duration
+
+	// hit reports whether the target was reached, after evalsToTarget
+	// fitness evaluations.
+	hit           bool
+	evalsToTarget int64
+}
+
+// newExperiment loads the configuration file at path. target, if not empty,
+// overrides the target fitness of the configuration.
+func newExperiment(path, target string) (*experiment, error) {
+	cfg, err := config.Load(path)
+	if err != nil {
+		return nil, err
+	}
+	exp := &experiment{name: cfg.Name, cfg: cfg}
+	if exp.name == "" {
+		exp.name = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	}
+
+	if target != "" {
+		exp.target, err = strconv.ParseFloat(target, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid target: %v", err)
+		}
+		exp.hasTarget = true
+		return exp, nil
+	}
+
+	// The target is the fitness of the first target-fitness condition, which
+	// may be nested in combinators (see the target-fitness condition of the
+	// config package for its default).
+	eval, err := config.BuildEvaluator(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	conds, err := config.BuildConditions(cfg, eval)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	exp.target, exp.hasTarget = targetFitness(conds)
+	return exp, nil
+}
+
+// targetFitness returns the fitness of the first target fitness condition
+// found, depth first, in conds and the combinators they nest, but those
+// negated by Not.
+func targetFitness(conds []evolve.Condition) (float64, bool) {
+	for _, cond := range conds {
+		switch c := cond.(type) {
+		case condition.TargetFitness:
+			return c.Fitness, true
+		case *condition.Combinator:
+			if c.Op() == condition.OpNot {
+				continue
+			}
+			if fitness, ok := targetFitness(c.Conditions()); ok {
+				return fitness, true
+			}
+		}
+	}
+	return 0, false
+}
+
+// runAll performs all the runs of all the experiments, opts.parallel at a
+// time. It returns the first error that occurred.
+func runAll(exps []*experiment, opts options) error {
+	names := make(map[string]bool)
+	for _, exp := range exps {
+		if names[exp.name] {
+			return fmt.Errorf("several configurations are named %q", exp.name)
+		}
+		names[exp.name] = true
+		exp.runs = make([]result, opts.seeds)
+	}
+
+	type job struct {
+		exp *experiment
+		i   int
+	}
+	jobs := make(chan job)
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		firstErr error
+	)
+	for w := 0; w < opts.parallel; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range jobs {
+				res, err := j.exp.run(j.i, opts.logdir)
+				if err != nil {
+					mu.Lock()
+					if firstErr == nil {
+						firstErr = fmt.Errorf("%s, seed %d: %v", j.exp.name, res.seed, err)
+					}
+					mu.Unlock()
+				}
+				j.exp.runs[j.i] = res
+			}
+		}()
+	}
+	for _, exp := range exps {
+		for i := 0; i < opts.seeds; i++ {
+			jobs <- job{exp, i}
+		}
+	}
+	close(jobs)
+	wg.Wait()
+	return firstErr
+}
+
+// run performs the i-th run of the experiment. If logdir is not empty, the
+// per-generation statistics are logged in a CSV file.
+func (exp *experiment) run(i int, logdir string) (result, error) {
+	cfg := *exp.cfg
+	cfg.Seed += int64(i)
+
+	rec := &recorder{exp: exp, res: result{seed: cfg.Seed}}
+	opts := []func(*engine.Engine) error{engine.Observe(rec)}
+
+	var log *observer.Log
+	if logdir != "" {
+		dir := filepath.Join(logdir, exp.name)
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return rec.res, err
+		}
+		f, err := os.Create(filepath.Join(dir, fmt.Sprintf("seed-%d.csv", cfg.Seed)))
+		if err != nil {
+			return rec.res, err
+		}
+		defer f.Close()
+
+		log, err = observer.NewCSV(f, observer.Columns(
+			observer.Generation,
+			observer.Elapsed,
+			observer.Evaluations,
+			observer.Best,
+			observer.Mean,
+			observer.StdDev,
+		))
+		if err != nil {
+			return rec.res, err
+		}
+		opts = append(opts, engine.Observe(log))
+	}
+
+	r, err := config.Build(&cfg, opts...)
+	if err != nil {
+		return rec.res, err
+	}
+	if _, _, err := r.Evolve(); err != nil {
+		return rec.res, err
+	}
+	if log != nil {
+		if err := log.Err(); err != nil {
+			return rec.res, err
+		}
+	}
+	return rec.res, nil
+}
+
+// reached reports whether fitness reaches the target of the experiment.
+func (exp *experiment) reached(fitness float64, natural bool) bool {
+	if natural {
+		return fitness >= exp.target
+	}
+	return fitness <= exp.target
+}
+
+// recorder is an observer recording the result of a run.
+type recorder struct {
+	exp *experiment
+	res result
+}
+
+func (r *recorder) Observe(stats *evolve.PopulationStats) {
+	r.res.gens = stats.GenNumber + 1
+	r.res.evals = stats.Evaluations
+	r.res.best = stats.BestEverFitness
+	r.res.elapsed = stats.Elapsed
+	if r.exp.hasTarget && !r.res.hit && r.exp.reached(stats.BestFitness, stats.Natural) {
+		r.res.hit = true
+		r.res.evalsToTarget = stats.Evaluations
+	}
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const stringMatch = `{
+  "seed": 10,
+  "population": 100,
+  "elites": 2,
+  "factory": {"type": "string", "alphabet": "ABCDEFGHIJKLMNOPQRSTUVWXYZ ", "length": 11},
+  "evaluator": {"type": "string-match", "target": "HELLO WORLD"},
+  "selection": "roulette-wheel",
+  "operators": [
+    {"type": "crossover", "mater": "string"},
+    {"type": "string-mutation", "alphabet": "ABCDEFGHIJKLMNOPQRSTUVWXYZ ", "probability": 0.02}
+  ],
+  "conditions": [
+    {"type": "target-fitness", "fitness": 0},
+    {"type": "generation-count", "n": 1000}
+  ]
+}`
+
+func TestExperiment(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "hello.json")
+	require.NoError(t, os.WriteFile(path, []byte(stringMatch), 0o644))
+
+	exp, err := newExperiment(path, "")
+	require.NoError(t, err)
+	assert.Equal(t, "hello", exp.name)
+	assert.True(t, exp.hasTarget)
+	assert.Equal(t, 0.0, exp.target)
+
+	logdir := filepath.Join(dir, "logs")
+	require.NoError(t, runAll([]*experiment{exp}, options{seeds: 3, parallel: 2, logdir: logdir}))
+
+	require.Len(t, exp.runs, 3)
+	for i, res := range exp.runs {
+		assert.Equal(t, int64(10+i), res.seed)
+		assert.True(t, res.hit)
+		assert.Equal(t, 0.0, res.best)
+		assert.Equal(t, res.evals, res.evalsToTarget)
+
+		logs, err := os.ReadFile(filepath.Join(logdir, "hello", "seed-"+[]string{"10", "11", "12"}[i]+".csv"))
+		require.NoError(t, err)
+		lines := strings.Split(strings.TrimSpace(string(logs)), "\n")
+		assert.Equal(t, "generation,elapsed,evaluations,best,mean,stddev", lines[0])
+		assert.Len(t, lines, res.gens+1)
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, writeComparison(&buf, []*experiment{exp}))
+	assert.Contains(t, buf.String(), "3/3 (100%)")
+}
+
+func TestExperimentTarget(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "hello.json")
+	require.NoError(t, os.WriteFile(path, []byte(stringMatch), 0o644))
+
+	exp, err := newExperiment(path, "3")
+	require.NoError(t, err)
+	require.NoError(t, runAll([]*experiment{exp}, options{seeds: 2, parallel: 1}))
+	for _, res := range exp.runs {
+		assert.True(t, res.hit)
+		assert.Less(t, res.evalsToTarget, res.evals)
+	}
+
+	_, err = newExperiment(path, "foo")
+	assert.Error(t, err)
+
+	err = runAll([]*experiment{exp, exp}, options{seeds: 1, parallel: 1})
+	assert.EqualError(t, err, `several configurations are named "hello"`)
+}
+
+func TestExperimentNestedTarget(t *testing.T) {
+	conds := `{"type": "target-fitness", "fitness": 0},
+    {"type": "generation-count", "n": 1000}`
+
+	tests := []struct {
+		conds     string
+		hasTarget bool
+		target    float64
+	}{
+		{`{"type": "or", "conditions": [
+      {"type": "and", "conditions": [{"type": "target-fitness", "fitness": 2}, {"type": "generation-count", "n": 10}]},
+      {"type": "generation-count", "n": 1000}
+    ]}`, true, 2},
+		{`{"type": "not", "condition": {"type": "target-fitness", "fitness": 2}},
+    {"type": "generation-count", "n": 1000}`, false, 0},
+	}
+	for _, tt := range tests {
+		dir := t.TempDir()
+		path := filepath.Join(dir, "hello.json")
+		cfg := strings.Replace(stringMatch, conds, tt.conds, 1)
+		require.NoError(t, os.WriteFile(path, []byte(cfg), 0o644))
+
+		exp, err := newExperiment(path, "")
+		require.NoError(t, err)
+		assert.Equal(t, tt.hasTarget, exp.hasTarget)
+		assert.Equal(t, tt.target, exp.target)
+	}
+}
+
+func TestMedian(t *testing.T) {
+	assert.Equal(t, 2.0, median([]float64{3, 1, 2}))
+	assert.Equal(t, 2.5, median([]float64{4, 1, 3, 2}))
+	assert.Equal(t, 2.5, mean([]float64{4, 1, 3, 2}))
+}
@@ -0,0 +1,176 @@
+// Command evolve runs evolution experiments described by configuration files
+// (see the config package), and compares their results.
+//
+// Usage:
+//
+//	evolve run [flags] config
+//	evolve compare [flags] config...
+//	evolve list [-plugin file]...
+//
+// The run command repeats the experiment described by config over several
+// seeds, in parallel, and prints the result of every run followed by a
+// summary. The compare command runs every configuration in the same way and
+// prints a table comparing them: success rate, mean and median best fitness,
+// mean and median number of fitness evaluations needed to reach the target.
+// The list command prints the names of the available components.
+//
+// The seeds of the runs of a configuration are seed, seed+1, ... seed+n-1,
+// where seed is the seed given in the configuration file. A run is successful
+// if the best fitness reaches the target, given by the -target flag or, by
+// default, by the first "target-fitness" termination condition of the
+// configuration.
+//
+// With -log dir, the statistics of every generation are written in CSV files,
+// one per run, named dir/<config>/seed-<seed>.csv, where config is the name of
+// the configuration, or the base name of its file.
+//
+// Besides the components provided by this project, built-in problems provide
+// the following evaluators:
+//
+//	onemax         number of ones in a bit string
+//	string-match   number of characters of a string that differ from
+//	               {"target": "..."}
+//	continuous     benchmark function of the continuous package, on
+//	               Gray-coded bit strings of dim×bits bits {"function": "...",
+//	               "dim": n, "bits": 16, "shift": false, "rotate": false,
+//	               "seed": 0}
+//	leading-ones   problems of the binary package, on bit strings of
+//	jump           {"length": n} bits, and the parameters of the problem
+//	trap           ({"k": k} for jump and trap, {"block": b} for royal-road)
+//	royal-road
+//	hiff
+//	nk             NK landscape of the binary package {"n": n, "k": k,
+//	               "neighbourhood": "adjacent" or "random", "seed": 0}
+//
+// The fitness of a "target-fitness" condition of the binary problems can be
+// omitted, it defaults to the optimum of the problem.
+//
+// On Linux, other components can be provided by Go plugins, loaded with the
+// -plugin flag. A plugin is a main package built with -buildmode=plugin, that
+// registers its components in the config.Default registry from an init
+// function. A plugin must be built with the same version of this module as the
+// command.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"runtime"
+	"strings"
+
+	"github.com/arl/evolve/config"
+)
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: evolve run [flags] config")
+	fmt.Fprintln(os.Stderr, "       evolve compare [flags] config...")
+	fmt.Fprintln(os.Stderr, "       evolve list [-plugin file]...")
+	fmt.Fprintln(os.Stderr, "run 'evolve run -h' for the list of flags")
+	os.Exit(2)
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+	}
+
+	var err error
+	switch cmd, args := os.Args[1], os.Args[2:]; cmd {
+	case "run":
+		err = run(args, false)
+	case "compare":
+		err = run(args, true)
+	case "list":
+		err = list(args)
+	default:
+		usage()
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "evolve:", err)
+		os.Exit(1)
+	}
+}
+
+// plugins is a flag.Value holding the paths of the plugins to load.
+type plugins []string
+
+func (p *plugins) String() string { return strings.Join(*p, ",") }
+
+func (p *plugins) Set(path string) error {
+	*p = append(*p, path)
+	return nil
+}
+
+func (p plugins) load() error {
+	for _, path := range p {
+		if err := loadPlugin(path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func run(args []string, compare bool) error {
+	fs := flag.NewFlagSet("run", flag.ExitOnError)
+	var opts options
+	var plugs plugins
+	fs.IntVar(&opts.seeds, "seeds", 10, "repeat each experiment over `n` seeds")
+	fs.IntVar(&opts.parallel, "parallel", runtime.GOMAXPROCS(0), "perform `n` runs in parallel")
+	fs.StringVar(&opts.logdir, "log", "", "write per-generation logs in `dir`")
+	target := fs.String("target", "", "target `fitness`, overrides the configuration target")
+	fs.Var(&plugs, "plugin", "load the plugin `file` (repeatable)")
+	fs.Parse(args)
+	if fs.NArg() == 0 || (!compare && fs.NArg() != 1) {
+		usage()
+	}
+	if opts.seeds <= 0 || opts.parallel <= 0 {
+		return fmt.Errorf("-seeds and -parallel must be strictly positive")
+	}
+	if err := plugs.load(); err != nil {
+		return err
+	}
+
+	var exps []*experiment
+	for _, path := range fs.Args() {
+		exp, err := newExperiment(path, *target)
+		if err != nil {
+			return err
+		}
+		exps = append(exps, exp)
+	}
+	if err := runAll(exps, opts); err != nil {
+		return err
+	}
+
+	if compare {
+		return writeComparison(os.Stdout, exps)
+	}
+	if err := writeRuns(os.Stdout, exps[0]); err != nil {
+		return err
+	}
+	fmt.Println()
+	return writeComparison(os.Stdout, exps)
+}
+
+func list(args []string) error {
+	fs := flag.NewFlagSet("list", flag.ExitOnError)
+	var plugs plugins
+	fs.Var(&plugs, "plugin", "load the plugin `file` (repeatable)")
+	fs.Parse(args)
+	if fs.NArg() != 0 {
+		usage()
+	}
+	if err := plugs.load(); err != nil {
+		return err
+	}
+
+	kinds := []config.Kind{config.Factory, config.Evaluator, config.Selection, config.Operator, config.Mater, config.Condition}
+	for _, kind := range kinds {
+		fmt.Printf("%s:\n", kind)
+		for _, name := range config.Default.Names(kind) {
+			fmt.Printf("\t%s\n", name)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,18 @@
+//go:build linux
+// +build linux
+
+package main
+
+import (
+	"fmt"
+	"plugin"
+)
+
+// loadPlugin loads the Go plugin at path. Plugins register their components
+// when they're initialized.
+func loadPlugin(path string) error {
+	if _, err := plugin.Open(path); err != nil {
+		return fmt.Errorf("can't load plugin: %v", err)
+	}
+	return nil
+}
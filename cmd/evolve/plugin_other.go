@@ -0,0 +1,11 @@
+//go:build !linux
+// +build !linux
+
+package main
+
+import "fmt"
+
+// loadPlugin reports an error, plugins are only supported on Linux.
+func loadPlugin(path string) error {
+	return fmt.Errorf("can't load plugin %s: plugins are only supported on linux", path)
+}
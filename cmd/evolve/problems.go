@@ -0,0 +1,141 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+
+	"github.com/arl/evolve"
+	"github.com/arl/evolve/benchmark/binary"
+	"github.com/arl/evolve/benchmark/continuous"
+	"github.com/arl/evolve/config"
+	"github.com/arl/evolve/pkg/bitstring"
+	"github.com/arl/evolve/pkg/mt19937"
+)
+
+// Register the built-in problems.
+func init() {
+	// {"type": "onemax"}, to maximize.
+	config.RegisterEvaluator("onemax", func(p config.Params) (evolve.Evaluator, error) {
+		return evolve.EvaluatorFunc(true, func(cand interface{}, pop []interface{}) float64 {
+			return float64(cand.(*bitstring.Bitstring).OnesCount())
+		}), p.Decode(&struct{}{})
+	})
+
+	// {"type": "string-match", "target": "HELLO WORLD"}, to minimize.
+	config.RegisterEvaluator("string-match", func(p config.Params) (evolve.Evaluator, error) {
+		var params struct{ Target string }
+		if err := p.Decode(&params); err != nil {
+			return nil, err
+		}
+		if params.Target == "" {
+			return nil, errors.New("empty target string")
+		}
+		return evolve.EvaluatorFunc(false, func(cand interface{}, pop []interface{}) float64 {
+			s := cand.(string)
+			errs := 0
+			for i := range s {
+				if i >= len(params.Target) || s[i] != params.Target[i] {
+					errs++
+				}
+			}
+			if len(s) < len(params.Target) {
+				errs += len(params.Target) - len(s)
+			}
+			return float64(errs)
+		}), nil
+	})
+
+	// {"type": "continuous", "function": "rastrigin", "dim": 10, "bits": 16,
+	// "shift": false, "rotate": false, "seed": 0}, to minimize, on bit strings
+	// of dim×bits bits.
+	config.RegisterEvaluator("continuous", func(p config.Params) (evolve.Evaluator, error) {
+		params := struct {
+			Function      string
+			Dim           int
+			Bits          uint
+			Shift, Rotate bool
+			Seed          int64
+		}{Bits: 16}
+		if err := p.Decode(&params); err != nil {
+			return nil, err
+		}
+		rng := rand.New(mt19937.New(params.Seed))
+		var opts []func(*continuous.Function) error
+		if params.Shift {
+			opts = append(opts, continuous.Shift(rng))
+		}
+		if params.Rotate {
+			opts = append(opts, continuous.Rotate(rng))
+		}
+		f, err := continuous.New(params.Function, params.Dim, opts...)
+		if err != nil {
+			return nil, err
+		}
+		return continuous.NewGray(f, params.Bits)
+	})
+	// Bit string problems of the binary package, to maximize. The length of
+	// the bit strings is given by "length", or "n" for NK landscapes.
+
+	// {"type": "leading-ones", "length": 100}
+	config.RegisterEvaluator("leading-ones", func(p config.Params) (evolve.Evaluator, error) {
+		var params struct{ Length uint }
+		if err := p.Decode(&params); err != nil {
+			return nil, err
+		}
+		return binary.NewLeadingOnes(params.Length)
+	})
+	// {"type": "jump", "length": 100, "k": 3}
+	config.RegisterEvaluator("jump", func(p config.Params) (evolve.Evaluator, error) {
+		var params struct{ Length, K uint }
+		if err := p.Decode(&params); err != nil {
+			return nil, err
+		}
+		return binary.NewJump(params.Length, params.K)
+	})
+	// {"type": "trap", "length": 100, "k": 5}
+	config.RegisterEvaluator("trap", func(p config.Params) (evolve.Evaluator, error) {
+		var params struct{ Length, K uint }
+		if err := p.Decode(&params); err != nil {
+			return nil, err
+		}
+		return binary.NewTrap(params.Length, params.K)
+	})
+	// {"type": "royal-road", "length": 64, "block": 8}
+	config.RegisterEvaluator("royal-road", func(p config.Params) (evolve.Evaluator, error) {
+		var params struct{ Length, Block uint }
+		if err := p.Decode(&params); err != nil {
+			return nil, err
+		}
+		return binary.NewRoyalRoad(params.Length, params.Block)
+	})
+	// {"type": "hiff", "length": 64}
+	config.RegisterEvaluator("hiff", func(p config.Params) (evolve.Evaluator, error) {
+		var params struct{ Length uint }
+		if err := p.Decode(&params); err != nil {
+			return nil, err
+		}
+		return binary.NewHIFF(params.Length)
+	})
+	// {"type": "nk", "n": 100, "k": 4, "neighbourhood": "adjacent", "seed": 0}
+	config.RegisterEvaluator("nk", func(p config.Params) (evolve.Evaluator, error) {
+		params := struct {
+			N, K          uint
+			Neighbourhood string
+			Seed          int64
+		}{Neighbourhood: "adjacent"}
+		if err := p.Decode(&params); err != nil {
+			return nil, err
+		}
+		var nb binary.Neighbourhood
+		switch params.Neighbourhood {
+		case "adjacent":
+			nb = binary.Adjacent
+		case "random":
+			nb = binary.Random
+		default:
+			return nil, fmt.Errorf("unknown neighbourhood %q", params.Neighbourhood)
+		}
+		return binary.NewNK(params.N, params.K, nb, params.Seed)
+	})
+}
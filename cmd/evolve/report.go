@@ -0,0 +1,88 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"text/tabwriter"
+	"time"
+)
+
+// writeRuns writes the result of every run of exp to w.
+func writeRuns(w io.Writer, exp *experiment) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "seed\tgenerations\tevaluations\tbest\ttarget\telapsed\t\n")
+	for _, res := range exp.runs {
+		hit := "-"
+		if exp.hasTarget {
+			hit = "no"
+			if res.hit {
+				hit = fmt.Sprintf("yes (%d evals)", res.evalsToTarget)
+			}
+		}
+		fmt.Fprintf(tw, "%d\t%d\t%d\t%.6g\t%s\t%v\t\n",
+			res.seed, res.gens, res.evals, res.best, hit, res.elapsed.Round(time.Millisecond))
+	}
+	return tw.Flush()
+}
+
+// writeComparison writes to w a table comparing the results of the
+// experiments. The number of evaluations to the target only accounts for the
+// successful runs.
+func writeComparison(w io.Writer, exps []*experiment) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "config\truns\tsuccess\tmean best\tmedian best\tmean evals\tmedian evals\t\n")
+	for _, exp := range exps {
+		s := summarize(exp)
+		success := "-"
+		if exp.hasTarget {
+			success = fmt.Sprintf("%d/%d (%.0f%%)", len(s.evals), len(exp.runs), 100*float64(len(s.evals))/float64(len(exp.runs)))
+		}
+		fmt.Fprintf(tw, "%s\t%d\t%s\t%.6g\t%.6g\t%s\t%s\t\n",
+			exp.name, len(exp.runs), success, mean(s.best), median(s.best), orDash(s.evals, mean), orDash(s.evals, median))
+	}
+	return tw.Flush()
+}
+
+// summary gathers the results of the runs of an experiment.
+type summary struct {
+	best  []float64 // best fitness of every run
+	evals []float64 // evaluations to target of the successful runs
+}
+
+func summarize(exp *experiment) summary {
+	var s summary
+	for _, res := range exp.runs {
+		s.best = append(s.best, res.best)
+		if res.hit {
+			s.evals = append(s.evals, float64(res.evalsToTarget))
+		}
+	}
+	return s
+}
+
+// orDash formats f(xs), or a dash if xs is empty.
+func orDash(xs []float64, f func([]float64) float64) string {
+	if len(xs) == 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%.6g", f(xs))
+}
+
+func mean(xs []float64) float64 {
+	sum := 0.0
+	for _, x := range xs {
+		sum += x
+	}
+	return sum / float64(len(xs))
+}
+
+func median(xs []float64) float64 {
+	sorted := append([]float64(nil), xs...)
+	sort.Float64s(sorted)
+	n := len(sorted)
+	if n%2 == 1 {
+		return sorted[n/2]
+	}
+	return (sorted[n/2-1] + sorted[n/2]) / 2
+}
@@ -0,0 +1,110 @@
+// Command evolvecache inspects and compacts the persistent fitness caches
+// created by the diskcache package.
+//
+// Usage:
+//
+//	evolvecache inspect [-keys n] dir
+//	evolvecache compact dir
+//
+// The inspect command prints information about the cache stored in dir and,
+// optionally, its first n entries. The compact command rewrites the cache log
+// so that it only contains one record per candidate and no trailing garbage.
+// Compacting a cache that is in use fails, the cache being locked by its user.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/arl/evolve/evaluator/diskcache"
+)
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: evolvecache inspect [-keys n] dir")
+	fmt.Fprintln(os.Stderr, "       evolvecache compact dir")
+	os.Exit(2)
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+	}
+
+	var err error
+	switch cmd, args := os.Args[1], os.Args[2:]; cmd {
+	case "inspect":
+		err = inspect(args)
+	case "compact":
+		err = compact(args)
+	default:
+		usage()
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "evolvecache:", err)
+		os.Exit(1)
+	}
+}
+
+func inspect(args []string) error {
+	fs := flag.NewFlagSet("inspect", flag.ExitOnError)
+	nkeys := fs.Int("keys", 0, "print the first `n` entries")
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		usage()
+	}
+	dir := fs.Arg(0)
+
+	info, err := diskcache.Inspect(dir)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("size:     %d bytes\n", info.Size)
+	fmt.Printf("records:  %d\n", info.Records)
+	fmt.Printf("keys:     %d\n", info.Keys)
+	fmt.Printf("garbage:  %d bytes\n", info.Garbage)
+	if info.Records > info.Keys || info.Garbage > 0 {
+		fmt.Println("(compaction would reclaim some space)")
+	}
+
+	if *nkeys <= 0 {
+		return nil
+	}
+	n := 0
+	return diskcache.Range(dir, func(key string, fitness float64) bool {
+		fmt.Printf("%s\t%s\n", strconv.FormatFloat(fitness, 'g', -1, 64), printableKey(key))
+		n++
+		return n < *nkeys
+	})
+}
+
+// printableKey returns a printable representation of a canonical key, long
+// keys are truncated.
+func printableKey(key string) string {
+	const max = 64
+	if len(key) > max {
+		return strconv.Quote(key[:max]) + "..."
+	}
+	return strconv.Quote(key)
+}
+
+func compact(args []string) error {
+	if len(args) != 1 {
+		usage()
+	}
+	before, err := diskcache.Inspect(args[0])
+	if err != nil {
+		return err
+	}
+	if err := diskcache.Compact(args[0]); err != nil {
+		return err
+	}
+	after, err := diskcache.Inspect(args[0])
+	if err != nil {
+		return err
+	}
+	fmt.Printf("compacted %s: %d -> %d bytes, %d -> %d records\n",
+		args[0], before.Size, after.Size, before.Records, after.Records)
+	return nil
+}
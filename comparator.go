@@ -0,0 +1,78 @@
+package evolve
+
+import "sort"
+
+// A Comparator defines the order of the individuals of a population, from the
+// fittest to the weakest.
+//
+// The engine sorts every generation with its comparator, so that the fittest
+// individual comes first. Elitism and selection strategies then rely on the
+// order of the sorted population.
+type Comparator interface {
+	// Compare returns a negative number if a is fitter than b, a positive
+	// number if b is fitter than a, and 0 if they are equivalent.
+	Compare(a, b *Individual) int
+}
+
+// A PopulationComparator is a Comparator for which the relative order of two
+// individuals depends on the rest of the population, such as a comparator
+// based on Pareto ranks.
+type PopulationComparator interface {
+	Comparator
+
+	// Prepare is called with the whole population before it gets sorted.
+	// Compare is then only called with individuals of that population.
+	Prepare(pop Population)
+}
+
+// A CheckedComparator is a Comparator whose configuration depends on the
+// number of scores of the individuals, such as a comparator expecting the
+// direction of every score to be specified.
+//
+// Before sorting the initial population, the engine calls Check with the
+// number of scores of its individuals, 1 if they have no vector of scores, and
+// fails if it returns an error.
+type CheckedComparator interface {
+	Comparator
+
+	// Check returns an error if the comparator can't compare individuals
+	// having nscores scores.
+	Check(nscores int) error
+}
+
+// ByFitness returns the Comparator ordering individuals by their fitness
+// score. If natural is true, higher fitness scores are fitter, otherwise lower
+// fitness scores are fitter.
+//
+// It is the comparator used by default by the engine.
+func ByFitness(natural bool) Comparator { return byFitness(natural) }
+
+type byFitness bool
+
+func (natural byFitness) Compare(a, b *Individual) int {
+	switch {
+	case a.Fitness == b.Fitness:
+		return 0
+	case (a.Fitness > b.Fitness) == bool(natural):
+		return -1
+	}
+	return 1
+}
+
+// SortPopulation sorts pop according to cmp, the fittest individual first.
+// The sort is stable: equivalent individuals keep their relative order.
+func SortPopulation(pop Population, cmp Comparator) {
+	if pc, ok := cmp.(PopulationComparator); ok {
+		pc.Prepare(pop)
+	}
+	sort.Stable(sorter{pop, cmp})
+}
+
+type sorter struct {
+	pop Population
+	cmp Comparator
+}
+
+func (s sorter) Len() int           { return len(s.pop) }
+func (s sorter) Less(i, j int) bool { return s.cmp.Compare(s.pop[i], s.pop[j]) < 0 }
+func (s sorter) Swap(i, j int)      { s.pop[i], s.pop[j] = s.pop[j], s.pop[i] }
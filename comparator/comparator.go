@@ -0,0 +1,52 @@
+// Package comparator provides comparators ordering individuals according to
+// their vector of scores, as computed by an evolve.VectorEvaluator.
+//
+// Comparators are set on the engine with engine.Compare. Individuals having no
+// vector of scores are compared as if their only score was their fitness.
+//
+// The direction of the scores, natural or not, is never implied: comparators
+// taking it into account require it for every score, the engine fails
+// otherwise (see evolve.CheckedComparator).
+package comparator
+
+import (
+	"fmt"
+
+	"github.com/arl/evolve"
+)
+
+// scores returns the scores of ind.
+func scores(ind *evolve.Individual) []float64 {
+	if ind.Scores == nil {
+		return []float64{ind.Fitness}
+	}
+	return ind.Scores
+}
+
+// compare compares 2 scores. It returns a negative number if a is better than
+// b, a positive one if b is better than a, 0 if they are equal, or if their
+// difference is not greater than tol.
+func compare(a, b float64, natural bool, tol float64) int {
+	d := a - b
+	switch {
+	case d <= tol && d >= -tol:
+		return 0
+	case (d > 0) == natural:
+		return -1
+	}
+	return 1
+}
+
+// isNatural returns natural[i], or false if natural has no i-th element.
+func isNatural(natural []bool, i int) bool {
+	return i < len(natural) && natural[i]
+}
+
+// checkNatural returns an error if natural doesn't specify the direction of
+// exactly nscores scores.
+func checkNatural(natural []bool, nscores int) error {
+	if len(natural) != nscores {
+		return fmt.Errorf("comparator: Natural specifies %d scores, want %d", len(natural), nscores)
+	}
+	return nil
+}
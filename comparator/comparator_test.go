@@ -0,0 +1,122 @@
+package comparator
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/arl/evolve"
+)
+
+func ind(name string, scores ...float64) *evolve.Individual {
+	return &evolve.Individual{Candidate: name, Fitness: scores[0], Scores: scores}
+}
+
+// order sorts pop with cmp and returns the candidates in order.
+func order(cmp evolve.Comparator, pop ...*evolve.Individual) []string {
+	evolve.SortPopulation(pop, cmp)
+	var names []string
+	for _, ind := range pop {
+		names = append(names, ind.Candidate.(string))
+	}
+	return names
+}
+
+func TestLexicographic(t *testing.T) {
+	pop := []*evolve.Individual{
+		ind("a", 10, 5),
+		ind("b", 10.05, 1),
+		ind("c", 9, 8),
+		ind("d", 10, 3),
+	}
+
+	cmp := Lexicographic{Natural: []bool{true, false}}
+	assert.Equal(t, []string{"b", "d", "a", "c"}, order(cmp, pop...))
+
+	// With a tolerance, a, b and d are tied on the first score.
+	cmp.Tolerances = []float64{0.1}
+	assert.Equal(t, []string{"b", "d", "a", "c"}, order(cmp, pop...))
+	cmp.Natural = []bool{true, true}
+	assert.Equal(t, []string{"a", "d", "b", "c"}, order(cmp, pop...))
+}
+
+func TestLexicographicFitnessOnly(t *testing.T) {
+	a := &evolve.Individual{Fitness: 1}
+	b := &evolve.Individual{Fitness: 2}
+	assert.Equal(t, -1, Lexicographic{}.Compare(a, b))
+	assert.Equal(t, 1, Lexicographic{Natural: []bool{true}}.Compare(a, b))
+	assert.Equal(t, 0, Lexicographic{}.Compare(a, a))
+}
+
+func TestCheck(t *testing.T) {
+	lex := Lexicographic{Natural: []bool{true, false}}
+	assert.NoError(t, lex.Check(2))
+	assert.EqualError(t, Lexicographic{}.Check(2), "comparator: Natural specifies 0 scores, want 2")
+	assert.EqualError(t, lex.Check(3), "comparator: Natural specifies 2 scores, want 3")
+
+	assert.NoError(t, (&Pareto{Natural: []bool{false, false}}).Check(2))
+	assert.Error(t, (&Pareto{Natural: []bool{false}}).Check(2))
+
+	assert.NoError(t, ConstrainedFirst{Violation: 1, Then: lex}.Check(2))
+	assert.EqualError(t, ConstrainedFirst{Violation: 2, Then: lex}.Check(2), "comparator: violation score 2 out of 2 scores")
+	assert.Error(t, ConstrainedFirst{Violation: 1, Then: lex}.Check(3))
+	assert.NoError(t, ConstrainedFirst{Violation: 0, Then: evolve.ByFitness(false)}.Check(1))
+}
+
+func TestWeightedSum(t *testing.T) {
+	cmp := WeightedSum{Weights: []float64{1, -2}, Natural: true}
+	assert.Equal(t, []string{"c", "b", "a"}, order(cmp,
+		ind("a", 10, 5), // 0
+		ind("b", 4, 1),  // 2
+		ind("c", 7, 0),  // 7
+	))
+	assert.Equal(t, 7.0, cmp.Sum(ind("", 7, 0, 100)))
+}
+
+func TestConstrainedFirst(t *testing.T) {
+	cmp := ConstrainedFirst{
+		Violation: 1,
+		Then:      evolve.ByFitness(false),
+	}
+	assert.Equal(t, []string{"c", "a", "d", "b"}, order(cmp,
+		ind("a", 10, 0),
+		ind("b", 1, 5),
+		ind("c", 7, 0),
+		ind("d", 0, 0.5),
+	))
+
+	// Without Then, feasible individuals are compared by natural fitness.
+	cmp.Then = nil
+	assert.Equal(t, []string{"a", "c", "d", "b"}, order(cmp,
+		ind("a", 10, 0),
+		ind("b", 1, 5),
+		ind("c", 7, 0),
+		ind("d", 0, 0.5),
+	))
+}
+
+func TestPareto(t *testing.T) {
+	cmp := &Pareto{}
+	pop := []*evolve.Individual{
+		ind("a", 1, 5),
+		ind("b", 2, 6), // dominated by a and c
+		ind("c", 2, 2),
+		ind("d", 5, 1),
+		ind("e", 6, 6), // dominated by all
+		ind("f", 3, 3), // dominated by c
+	}
+	names := order(cmp, pop...)
+	assert.ElementsMatch(t, []string{"a", "c", "d"}, names[:3])
+	assert.ElementsMatch(t, []string{"b", "f"}, names[3:5])
+	assert.Equal(t, "e", names[5])
+
+	fronts := map[string]int{}
+	for _, ind := range pop {
+		fronts[ind.Candidate.(string)] = cmp.Front(ind)
+	}
+	assert.Equal(t, map[string]int{"a": 0, "c": 0, "d": 0, "b": 1, "f": 1, "e": 2}, fronts)
+
+	// Boundary individuals of the front have an infinite crowding distance,
+	// and are thus preferred: c is the least fit of the first front.
+	assert.Equal(t, "c", names[2])
+}
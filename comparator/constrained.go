@@ -0,0 +1,60 @@
+package comparator
+
+import (
+	"fmt"
+
+	"github.com/arl/evolve"
+)
+
+// ConstrainedFirst compares individuals of a constrained problem, for which
+// one of the scores measures the violation of the constraints, 0 meaning the
+// candidate is feasible.
+//
+// Feasible individuals are always fitter than infeasible ones. Two infeasible
+// individuals are compared by their constraint violation, the lower the
+// better, while two feasible individuals are compared by Then.
+type ConstrainedFirst struct {
+	// Violation is the index of the score measuring the constraints violation.
+	Violation int
+
+	// Then compares feasible individuals. If nil, they're compared by fitness,
+	// higher fitness scores being fitter (see evolve.ByFitness).
+	Then evolve.Comparator
+}
+
+// Compare implements evolve.Comparator.
+func (c ConstrainedFirst) Compare(a, b *evolve.Individual) int {
+	va, vb := scores(a)[c.Violation], scores(b)[c.Violation]
+	if va > 0 || vb > 0 {
+		return compare(va, vb, false, 0)
+	}
+	return c.then().Compare(a, b)
+}
+
+// then returns the comparator of feasible individuals.
+func (c ConstrainedFirst) then() evolve.Comparator {
+	if c.Then == nil {
+		return evolve.ByFitness(true)
+	}
+	return c.Then
+}
+
+// Check implements evolve.CheckedComparator. It returns an error if Violation
+// is not the index of a score, or if Then is a CheckedComparator returning an
+// error.
+func (c ConstrainedFirst) Check(nscores int) error {
+	if c.Violation < 0 || c.Violation >= nscores {
+		return fmt.Errorf("comparator: violation score %d out of %d scores", c.Violation, nscores)
+	}
+	if cc, ok := c.Then.(evolve.CheckedComparator); ok {
+		return cc.Check(nscores)
+	}
+	return nil
+}
+
+// Prepare implements evolve.PopulationComparator, if Then does.
+func (c ConstrainedFirst) Prepare(pop evolve.Population) {
+	if pc, ok := c.Then.(evolve.PopulationComparator); ok {
+		pc.Prepare(pop)
+	}
+}
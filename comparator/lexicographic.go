@@ -0,0 +1,37 @@
+package comparator
+
+import "github.com/arl/evolve"
+
+// Lexicographic compares individuals score after score: the first score
+// decides, unless the scores are considered equal, in which case the second
+// score decides, and so on.
+type Lexicographic struct {
+	// Natural indicates, for each score, whether higher values are better.
+	// It's required, with one element per score (see Check).
+	Natural []bool
+
+	// Tolerances are the absolute tolerances of the scores: two scores are
+	// considered equal if they differ by no more than their tolerance. Scores
+	// with no corresponding tolerance must be strictly equal.
+	Tolerances []float64
+}
+
+// Check implements evolve.CheckedComparator. It returns an error if Natural
+// doesn't have exactly nscores elements.
+func (l Lexicographic) Check(nscores int) error { return checkNatural(l.Natural, nscores) }
+
+// Compare implements evolve.Comparator. Scores with no corresponding element
+// in Natural are considered non-natural.
+func (l Lexicographic) Compare(a, b *evolve.Individual) int {
+	sa, sb := scores(a), scores(b)
+	for i := 0; i < len(sa) && i < len(sb); i++ {
+		var tol float64
+		if i < len(l.Tolerances) {
+			tol = l.Tolerances[i]
+		}
+		if c := compare(sa[i], sb[i], isNatural(l.Natural, i), tol); c != 0 {
+			return c
+		}
+	}
+	return 0
+}
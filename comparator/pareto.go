@@ -0,0 +1,147 @@
+package comparator
+
+import (
+	"math"
+	"sort"
+
+	"github.com/arl/evolve"
+)
+
+// Pareto compares individuals by Pareto rank, as in NSGA-II: individuals of
+// the population are sorted into successive non-dominated fronts, the first
+// front being the fittest. Within a front, individuals located in less crowded
+// regions of the objective space, i.e having a greater crowding distance, are
+// fitter.
+//
+// An individual dominates another if none of its scores is worse, and at least
+// one of them is better.
+//
+// Pareto is an evolve.PopulationComparator, the ranks are computed when the
+// population gets sorted. Pareto is not safe for concurrent use by multiple
+// goroutines.
+type Pareto struct {
+	// Natural indicates, for each score, whether higher values are better.
+	// It's required, with one element per score (see Check).
+	Natural []bool
+
+	ranks map[*evolve.Individual]rank
+}
+
+type rank struct {
+	front    int
+	crowding float64
+}
+
+// Check implements evolve.CheckedComparator. It returns an error if Natural
+// doesn't have exactly nscores elements.
+func (p *Pareto) Check(nscores int) error { return checkNatural(p.Natural, nscores) }
+
+// Compare implements evolve.Comparator.
+func (p *Pareto) Compare(a, b *evolve.Individual) int {
+	ra, rb := p.ranks[a], p.ranks[b]
+	switch {
+	case ra.front != rb.front:
+		return ra.front - rb.front
+	case ra.crowding > rb.crowding:
+		return -1
+	case ra.crowding < rb.crowding:
+		return 1
+	}
+	return 0
+}
+
+// Front returns the zero-based index of the non-dominated front of ind, in
+// the last prepared population.
+func (p *Pareto) Front(ind *evolve.Individual) int { return p.ranks[ind].front }
+
+// Prepare implements evolve.PopulationComparator. It computes the Pareto
+// fronts and crowding distances of the individuals of pop.
+func (p *Pareto) Prepare(pop evolve.Population) {
+	p.ranks = make(map[*evolve.Individual]rank, len(pop))
+
+	// Fast non-dominated sort.
+	dominated := make([][]int, len(pop)) // individuals dominated by i
+	ndom := make([]int, len(pop))        // number of individuals dominating i
+	for i := range pop {
+		for j := i + 1; j < len(pop); j++ {
+			switch p.dominance(pop[i], pop[j]) {
+			case -1:
+				dominated[i] = append(dominated[i], j)
+				ndom[j]++
+			case 1:
+				dominated[j] = append(dominated[j], i)
+				ndom[i]++
+			}
+		}
+	}
+	var front []int
+	for i := range pop {
+		if ndom[i] == 0 {
+			front = append(front, i)
+		}
+	}
+
+	for nfront := 0; len(front) > 0; nfront++ {
+		crowding := p.crowding(pop, front)
+		var next []int
+		for k, i := range front {
+			p.ranks[pop[i]] = rank{front: nfront, crowding: crowding[k]}
+			for _, j := range dominated[i] {
+				ndom[j]--
+				if ndom[j] == 0 {
+					next = append(next, j)
+				}
+			}
+		}
+		front = next
+	}
+}
+
+// dominance returns -1 if a dominates b, 1 if b dominates a, 0 otherwise.
+func (p *Pareto) dominance(a, b *evolve.Individual) int {
+	sa, sb := scores(a), scores(b)
+	var abetter, bbetter bool
+	for i := 0; i < len(sa) && i < len(sb); i++ {
+		switch compare(sa[i], sb[i], isNatural(p.Natural, i), 0) {
+		case -1:
+			abetter = true
+		case 1:
+			bbetter = true
+		}
+	}
+	switch {
+	case abetter && !bbetter:
+		return -1
+	case bbetter && !abetter:
+		return 1
+	}
+	return 0
+}
+
+// crowding returns the crowding distances of the individuals of a front.
+func (p *Pareto) crowding(pop evolve.Population, front []int) []float64 {
+	dist := make([]float64, len(front))
+	if len(front) == 0 {
+		return dist
+	}
+	nscores := len(scores(pop[front[0]]))
+	idx := make([]int, len(front))
+	for m := 0; m < nscores; m++ {
+		for k := range idx {
+			idx[k] = k
+		}
+		score := func(k int) float64 { return scores(pop[front[k]])[m] }
+		sort.SliceStable(idx, func(i, j int) bool { return score(idx[i]) < score(idx[j]) })
+
+		lo, hi := score(idx[0]), score(idx[len(idx)-1])
+		dist[idx[0]] = math.Inf(1)
+		dist[idx[len(idx)-1]] = math.Inf(1)
+		if hi == lo {
+			continue
+		}
+		for k := 1; k < len(idx)-1; k++ {
+			dist[idx[k]] += (score(idx[k+1]) - score(idx[k-1])) / (hi - lo)
+		}
+	}
+	return dist
+}
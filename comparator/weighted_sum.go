@@ -0,0 +1,30 @@
+package comparator
+
+import "github.com/arl/evolve"
+
+// WeightedSum compares individuals by the weighted sum of their scores.
+type WeightedSum struct {
+	// Weights are the weights of the scores. Scores with no corresponding
+	// weight are ignored. Negative weights can be used to combine natural
+	// and non-natural scores.
+	Weights []float64
+
+	// Natural indicates whether higher weighted sums are better.
+	Natural bool
+}
+
+// Compare implements evolve.Comparator.
+func (w WeightedSum) Compare(a, b *evolve.Individual) int {
+	return compare(w.Sum(a), w.Sum(b), w.Natural, 0)
+}
+
+// Sum returns the weighted sum of the scores of ind.
+func (w WeightedSum) Sum(ind *evolve.Individual) float64 {
+	var sum float64
+	for i, s := range scores(ind) {
+		if i < len(w.Weights) {
+			sum += w.Weights[i] * s
+		}
+	}
+	return sum
+}
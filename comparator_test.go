@@ -0,0 +1,39 @@
+package evolve
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSortPopulationByFitness(t *testing.T) {
+	fitnesses := func(pop Population) []float64 {
+		var f []float64
+		for _, ind := range pop {
+			f = append(f, ind.Fitness)
+		}
+		return f
+	}
+	pop := Population{{Fitness: 2}, {Fitness: 0}, {Fitness: 3}, {Fitness: 1}}
+
+	SortPopulation(pop, ByFitness(true))
+	assert.Equal(t, []float64{3, 2, 1, 0}, fitnesses(pop))
+
+	SortPopulation(pop, ByFitness(false))
+	assert.Equal(t, []float64{0, 1, 2, 3}, fitnesses(pop))
+}
+
+func TestSortPopulationStable(t *testing.T) {
+	pop := Population{
+		{Candidate: "a", Fitness: 1},
+		{Candidate: "b", Fitness: 2},
+		{Candidate: "c", Fitness: 1},
+		{Candidate: "d", Fitness: 2},
+	}
+	SortPopulation(pop, ByFitness(true))
+	var cands []interface{}
+	for _, ind := range pop {
+		cands = append(cands, ind.Candidate)
+	}
+	assert.Equal(t, []interface{}{"b", "d", "a", "c"}, cands)
+}
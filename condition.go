@@ -13,3 +13,30 @@ type Condition interface {
 	// returns true if it satisfies some predetermined condition.
 	IsSatisfied(pdata *PopulationStats) bool
 }
+
+// A Resetter is a Condition that holds a state, built from the statistics of
+// the successive generations, such as the history of the best fitness.
+//
+// The engine resets the conditions implementing Resetter when the evolution
+// starts, so that a condition can be reused for several runs. The method isn't
+// named Reset, so that conditions whose state is set by the user, such as
+// condition.UserAbort, don't become Resetters inadvertently.
+type Resetter interface {
+	Condition
+
+	// ResetCondition clears the state of the condition.
+	ResetCondition()
+}
+
+// A BudgetCondition is a Condition limiting the number of fitness evaluations
+// of an evolution.
+//
+// The engine honours the budget of its termination conditions exactly, it
+// stops evaluating candidates once the budget is exhausted, even in the middle
+// of a generation.
+type BudgetCondition interface {
+	Condition
+
+	// MaxEvaluations returns the maximum number of fitness evaluations.
+	MaxEvaluations() int64
+}
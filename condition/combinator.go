@@ -0,0 +1,195 @@
+package condition
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/arl/evolve"
+)
+
+// A Combinator is a condition combining other conditions with a boolean
+// operator. Combinators are created with And, Or, Not and After, and can be
+// nested to express composite termination rules, for example:
+//
+//	// Stop when the target fitness is reached, but not before 100
+//	// generations, or after 1 hour.
+//	cond := condition.Or(
+//		condition.And(
+//			condition.TargetFitness{Fitness: 0},
+//			condition.GenerationCount(100),
+//		),
+//		condition.ElapsedTime(time.Hour),
+//	)
+//
+// Every sub-condition is evaluated at every generation, without short-circuit,
+// so that stateful sub-conditions see all the generations, and the combinator
+// records which of them were satisfied (see Satisfied and Tree).
+//
+// Combinators implement evolve.Resetter, resetting a combinator resets its
+// sub-conditions.
+//
+// The engine honours the evaluation budgets of the sub-conditions (see
+// EvaluationCount) after which a combinator is certainly satisfied: the
+// smallest budget of an Or, and the largest budget of an And whose
+// sub-conditions all set a budget.
+type Combinator struct {
+	op    Operator
+	n     int // number of generations, for After
+	conds []evolve.Condition
+	sat   []bool // sub-conditions satisfied at the last evaluation
+	ok    bool   // result of the last evaluation
+}
+
+// An Operator is the boolean operator of a Combinator.
+type Operator string
+
+// Operators of the combinators.
+const (
+	OpAnd   Operator = "AND"   // all the sub-conditions are satisfied
+	OpOr    Operator = "OR"    // at least one sub-condition is satisfied
+	OpNot   Operator = "NOT"   // the sub-condition is not satisfied
+	OpAfter Operator = "AFTER" // the sub-condition is satisfied after n generations
+)
+
+// And returns a condition satisfied when all conds are satisfied.
+func And(conds ...evolve.Condition) *Combinator {
+	return &Combinator{op: OpAnd, conds: conds, sat: make([]bool, len(conds))}
+}
+
+// Or returns a condition satisfied when at least one of conds is satisfied.
+func Or(conds ...evolve.Condition) *Combinator {
+	return &Combinator{op: OpOr, conds: conds, sat: make([]bool, len(conds))}
+}
+
+// Not returns a condition satisfied when cond is not.
+func Not(cond evolve.Condition) *Combinator {
+	return &Combinator{op: OpNot, conds: []evolve.Condition{cond}, sat: make([]bool, 1)}
+}
+
+// After returns a condition satisfied when cond is satisfied and at least n
+// generations have passed.
+func After(n int, cond evolve.Condition) *Combinator {
+	return &Combinator{op: OpAfter, n: n, conds: []evolve.Condition{cond}, sat: make([]bool, 1)}
+}
+
+// IsSatisfied evaluates all the sub-conditions and combines their results.
+func (c *Combinator) IsSatisfied(stats *evolve.PopulationStats) bool {
+	nsat := 0
+	for i, cond := range c.conds {
+		c.sat[i] = cond.IsSatisfied(stats)
+		if c.sat[i] {
+			nsat++
+		}
+	}
+
+	switch c.op {
+	case OpAnd:
+		c.ok = nsat == len(c.conds)
+	case OpOr:
+		c.ok = nsat > 0
+	case OpNot:
+		c.ok = nsat == 0
+	case OpAfter:
+		c.ok = nsat == 1 && stats.GenNumber+1 >= c.n
+	}
+	return c.ok
+}
+
+// Op returns the boolean operator of the combinator.
+func (c *Combinator) Op() Operator { return c.op }
+
+// Conditions returns the sub-conditions.
+func (c *Combinator) Conditions() []evolve.Condition {
+	return append([]evolve.Condition(nil), c.conds...)
+}
+
+// Satisfied returns the sub-conditions that were satisfied at the last
+// evaluation.
+func (c *Combinator) Satisfied() []evolve.Condition {
+	var sat []evolve.Condition
+	for i, cond := range c.conds {
+		if c.sat[i] {
+			sat = append(sat, cond)
+		}
+	}
+	return sat
+}
+
+// ResetCondition implements evolve.Resetter.
+func (c *Combinator) ResetCondition() {
+	c.ok = false
+	for i, cond := range c.conds {
+		c.sat[i] = false
+		if r, ok := cond.(evolve.Resetter); ok {
+			r.ResetCondition()
+		}
+	}
+}
+
+// String returns a string representation of the condition tree, on a single
+// line, nested combinators being enclosed in parentheses, for example:
+//
+//	(Reached target fitness of 0.000000 AND Reached 100 generations) OR Elapsed Time (1h0m0s)
+func (c *Combinator) String() string {
+	sub := func(cond evolve.Condition) string {
+		if _, ok := cond.(*Combinator); ok {
+			return "(" + cond.String() + ")"
+		}
+		return cond.String()
+	}
+
+	switch c.op {
+	case OpNot:
+		return "NOT " + sub(c.conds[0])
+	case OpAfter:
+		return fmt.Sprintf("AFTER %d generations THEN %s", c.n, sub(c.conds[0]))
+	}
+	strs := make([]string, len(c.conds))
+	for i, cond := range c.conds {
+		strs[i] = sub(cond)
+	}
+	return strings.Join(strs, " "+string(c.op)+" ")
+}
+
+// Tree returns a multi-line representation of the condition tree, in which
+// the conditions satisfied at the last evaluation are marked, for example:
+//
+//	OR [satisfied]
+//	├── AND [satisfied]
+//	│   ├── Reached target fitness of 0.000000 [satisfied]
+//	│   └── Reached 100 generations [satisfied]
+//	└── Elapsed Time (1h0m0s)
+func (c *Combinator) Tree() string {
+	var sb strings.Builder
+	c.tree(&sb, "", "")
+	return sb.String()
+}
+
+func (c *Combinator) tree(sb *strings.Builder, prefix, childPrefix string) {
+	sb.WriteString(prefix)
+	if c.op == OpAfter {
+		fmt.Fprintf(sb, "AFTER %d generations", c.n)
+	} else {
+		sb.WriteString(string(c.op))
+	}
+	if c.ok {
+		sb.WriteString(" [satisfied]")
+	}
+	sb.WriteByte('\n')
+
+	for i, cond := range c.conds {
+		branch, next := "├── ", "│   "
+		if i == len(c.conds)-1 {
+			branch, next = "└── ", "    "
+		}
+		if sub, ok := cond.(*Combinator); ok {
+			sub.tree(sb, childPrefix+branch, childPrefix+next)
+			continue
+		}
+		sb.WriteString(childPrefix + branch + cond.String())
+		if c.sat[i] {
+			sb.WriteString(" [satisfied]")
+		}
+		sb.WriteByte('\n')
+	}
+}
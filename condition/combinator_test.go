@@ -0,0 +1,84 @@
+package condition
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/arl/evolve"
+)
+
+func TestCombinators(t *testing.T) {
+	target := TargetFitness{Fitness: 10, Natural: true}
+	gens := GenerationCount(100)
+	elapsed := ElapsedTime(time.Hour)
+
+	tests := []struct {
+		name  string
+		cond  *Combinator
+		stats evolve.PopulationStats
+		want  bool
+		sat   []evolve.Condition
+	}{
+		{"and/none", And(target, gens), evolve.PopulationStats{GenNumber: 10, BestFitness: 5}, false, nil},
+		{"and/one", And(target, gens), evolve.PopulationStats{GenNumber: 10, BestFitness: 10}, false, []evolve.Condition{target}},
+		{"and/all", And(target, gens), evolve.PopulationStats{GenNumber: 99, BestFitness: 10}, true, []evolve.Condition{target, gens}},
+		{"or/none", Or(target, gens), evolve.PopulationStats{GenNumber: 10, BestFitness: 5}, false, nil},
+		{"or/one", Or(target, gens), evolve.PopulationStats{GenNumber: 99, BestFitness: 5}, true, []evolve.Condition{gens}},
+		{"not/satisfied", Not(target), evolve.PopulationStats{BestFitness: 5}, true, nil},
+		{"not/unsatisfied", Not(target), evolve.PopulationStats{BestFitness: 10}, false, []evolve.Condition{target}},
+		{"after/early", After(100, target), evolve.PopulationStats{GenNumber: 10, BestFitness: 10}, false, []evolve.Condition{target}},
+		{"after/late", After(100, target), evolve.PopulationStats{GenNumber: 99, BestFitness: 10}, true, []evolve.Condition{target}},
+		{"nested", Or(And(target, gens), elapsed), evolve.PopulationStats{GenNumber: 99, BestFitness: 10}, true, []evolve.Condition{And(target, gens)}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stats := tt.stats
+			stats.Natural = true
+			assert.Equal(t, tt.want, tt.cond.IsSatisfied(&stats))
+			assert.Equal(t, len(tt.sat), len(tt.cond.Satisfied()))
+			for i, sat := range tt.cond.Satisfied() {
+				assert.Equal(t, tt.sat[i].String(), sat.String())
+			}
+		})
+	}
+
+	assert.Equal(t, OpAnd, And(target).Op())
+	assert.Equal(t, OpOr, Or(target).Op())
+	assert.Equal(t, OpNot, Not(target).Op())
+	assert.Equal(t, OpAfter, After(1, target).Op())
+}
+
+func TestCombinatorString(t *testing.T) {
+	cond := Or(
+		And(TargetFitness{Fitness: 10, Natural: true}, GenerationCount(100)),
+		Not(After(5, ElapsedTime(time.Hour))),
+	)
+	assert.Equal(t, "(Reached target fitness of 10.000000 AND Reached 100 generations) OR "+
+		"(NOT (AFTER 5 generations THEN Elapsed Time (1h0m0s)))", cond.String())
+
+	cond.IsSatisfied(&evolve.PopulationStats{GenNumber: 99, BestFitness: 10, Natural: true})
+	assert.Equal(t, `OR [satisfied]
+├── AND [satisfied]
+│   ├── Reached target fitness of 10.000000 [satisfied]
+│   └── Reached 100 generations [satisfied]
+└── NOT [satisfied]
+    └── AFTER 5 generations
+        └── Elapsed Time (1h0m0s)
+`, cond.Tree())
+}
+
+func TestCombinatorReset(t *testing.T) {
+	stagnation := &Stagnation{Generations: 1}
+	cond := And(stagnation, GenerationCount(1))
+	for gen := 0; gen < 2; gen++ {
+		cond.IsSatisfied(&evolve.PopulationStats{GenNumber: gen, Natural: true})
+	}
+	assert.Len(t, cond.Satisfied(), 2)
+
+	cond.ResetCondition()
+	assert.Empty(t, cond.Satisfied())
+	assert.False(t, cond.IsSatisfied(&evolve.PopulationStats{GenNumber: 0, BestFitness: 1, Natural: true}))
+	assert.Equal(t, []evolve.Condition{GenerationCount(1)}, cond.Satisfied())
+}
@@ -0,0 +1,29 @@
+package condition
+
+import (
+	"fmt"
+
+	"github.com/arl/evolve"
+)
+
+// EvaluationCount is a condition satisfied when a number of fitness
+// evaluations have been performed. Fitness scores served by a fitness cache
+// are not counted.
+//
+// EvaluationCount implements evolve.BudgetCondition, the engine thus never
+// performs more evaluations than n, when it's given as a termination condition.
+type EvaluationCount int64
+
+// IsSatisfied reports whether the number of fitness evaluations has been
+// reached.
+func (n EvaluationCount) IsSatisfied(stats *evolve.PopulationStats) bool {
+	return stats.Evaluations >= int64(n)
+}
+
+// MaxEvaluations implements evolve.BudgetCondition.
+func (n EvaluationCount) MaxEvaluations() int64 { return int64(n) }
+
+// String returns a string representation of this condition.
+func (n EvaluationCount) String() string {
+	return fmt.Sprintf("Reached %d fitness evaluations", n)
+}
@@ -0,0 +1,24 @@
+package condition
+
+import (
+	"testing"
+
+	"github.com/arl/evolve"
+)
+
+func TestEvaluationCount(t *testing.T) {
+	cond := EvaluationCount(1000)
+	stats := &evolve.PopulationStats{}
+
+	stats.Evaluations, stats.CacheHits = 999, 100
+	if cond.IsSatisfied(stats) {
+		t.Errorf("should not terminate before 1000 evaluations")
+	}
+
+	stats.Evaluations = 1000
+	if !cond.IsSatisfied(stats) {
+		t.Errorf("should terminate after 1000 evaluations")
+	}
+
+	var _ evolve.BudgetCondition = cond
+}
@@ -0,0 +1,47 @@
+package condition
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/arl/evolve"
+)
+
+// MeanPlateau is a condition satisfied when the mean fitness of the population
+// has stayed within Epsilon of the same value for a number of consecutive
+// generations.
+//
+// MeanPlateau holds the history of the mean fitness, it must be used by
+// pointer. It implements evolve.Resetter, the engine thus resets it when the
+// evolution starts.
+type MeanPlateau struct {
+	// Generations is the number of generations of the plateau after which the
+	// condition is satisfied.
+	Generations int
+
+	// Epsilon is the maximum change of the mean fitness, in either
+	// direction, on the plateau.
+	Epsilon float64
+
+	started bool
+	mean    float64 // mean fitness at the start of the plateau
+	gen     int     // generation of the start of the plateau
+}
+
+// IsSatisfied reports whether the mean fitness has been on a plateau for
+// Generations generations.
+func (mp *MeanPlateau) IsSatisfied(stats *evolve.PopulationStats) bool {
+	if !mp.started || math.Abs(stats.Mean-mp.mean) > mp.Epsilon {
+		mp.started = true
+		mp.mean, mp.gen = stats.Mean, stats.GenNumber
+	}
+	return stats.GenNumber-mp.gen >= mp.Generations
+}
+
+// ResetCondition implements evolve.Resetter.
+func (mp *MeanPlateau) ResetCondition() { mp.started = false }
+
+// String returns a string representation of this condition.
+func (mp *MeanPlateau) String() string {
+	return fmt.Sprintf("Mean fitness on a plateau (±%v) for %d generations", mp.Epsilon, mp.Generations)
+}
@@ -0,0 +1,24 @@
+package condition
+
+import (
+	"testing"
+
+	"github.com/arl/evolve"
+)
+
+func TestMeanPlateau(t *testing.T) {
+	cond := &MeanPlateau{Generations: 3, Epsilon: 0.5}
+	means := []float64{1, 2, 2.4, 1.6, 2.6, 2.5, 2.2, 2.9}
+	want := []bool{false, false, false, false, false, false, false, true}
+	for gen, mean := range means {
+		got := cond.IsSatisfied(&evolve.PopulationStats{GenNumber: gen, Mean: mean})
+		if got != want[gen] {
+			t.Errorf("generation %d: IsSatisfied() = %t, want %t", gen, got, want[gen])
+		}
+	}
+
+	cond.ResetCondition()
+	if cond.IsSatisfied(&evolve.PopulationStats{GenNumber: 0, Mean: 2.5}) {
+		t.Errorf("should not be satisfied after reset")
+	}
+}
@@ -0,0 +1,49 @@
+package condition
+
+import (
+	"fmt"
+
+	"github.com/arl/evolve"
+)
+
+// Stagnation is a condition satisfied when the best fitness hasn't improved by
+// more than Epsilon for a number of consecutive generations.
+//
+// Stagnation holds the history of the best fitness, it must be used by
+// pointer. It implements evolve.Resetter, the engine thus resets it when the
+// evolution starts.
+type Stagnation struct {
+	// Generations is the number of generations without improvement after
+	// which the condition is satisfied.
+	Generations int
+
+	// Epsilon is the fitness improvement below which the best fitness is
+	// considered not to improve.
+	Epsilon float64
+
+	started bool
+	best    float64 // best fitness at the last improvement
+	gen     int     // generation of the last improvement
+}
+
+// IsSatisfied reports whether the best fitness has stagnated for Generations
+// generations.
+func (s *Stagnation) IsSatisfied(stats *evolve.PopulationStats) bool {
+	improved := stats.BestFitness-s.best > s.Epsilon
+	if !stats.Natural {
+		improved = s.best-stats.BestFitness > s.Epsilon
+	}
+	if !s.started || improved {
+		s.started = true
+		s.best, s.gen = stats.BestFitness, stats.GenNumber
+	}
+	return stats.GenNumber-s.gen >= s.Generations
+}
+
+// ResetCondition implements evolve.Resetter.
+func (s *Stagnation) ResetCondition() { s.started = false }
+
+// String returns a string representation of this condition.
+func (s *Stagnation) String() string {
+	return fmt.Sprintf("Best fitness stagnated (±%v) for %d generations", s.Epsilon, s.Generations)
+}
@@ -0,0 +1,47 @@
+package condition
+
+import (
+	"testing"
+
+	"github.com/arl/evolve"
+)
+
+func TestStagnation(t *testing.T) {
+	tests := []struct {
+		name    string
+		natural bool
+		best    []float64
+		want    int // generation at which the condition is satisfied
+	}{
+		{"natural", true, []float64{1, 2, 2.05, 2.08, 2.3}, 3},
+		{"non-natural", false, []float64{5, 4, 3.95, 3, 3, 2.95, 3}, 5},
+		{"worse", true, []float64{5, 4, 3}, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cond := &Stagnation{Generations: 2, Epsilon: 0.1}
+			got := -1
+			for gen, best := range tt.best {
+				stats := &evolve.PopulationStats{GenNumber: gen, BestFitness: best, Natural: tt.natural}
+				if cond.IsSatisfied(stats) {
+					got = gen
+					break
+				}
+			}
+			if got != tt.want {
+				t.Errorf("satisfied at generation %d, want %d", got, tt.want)
+			}
+		})
+	}
+
+	t.Run("reset", func(t *testing.T) {
+		cond := &Stagnation{Generations: 2}
+		for gen := 0; gen < 3; gen++ {
+			cond.IsSatisfied(&evolve.PopulationStats{GenNumber: gen, BestFitness: 10, Natural: true})
+		}
+		cond.ResetCondition()
+		if cond.IsSatisfied(&evolve.PopulationStats{GenNumber: 0, BestFitness: 1, Natural: true}) {
+			t.Errorf("should not be satisfied after reset")
+		}
+	})
+}
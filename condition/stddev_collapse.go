@@ -0,0 +1,48 @@
+package condition
+
+import (
+	"fmt"
+
+	"github.com/arl/evolve"
+)
+
+// StdDevCollapse is a condition satisfied when the standard deviation of the
+// fitness scores of the population has collapsed, that is when it has been at
+// most Threshold for a number of consecutive generations. A collapsed standard
+// deviation is a sign that the population has converged.
+//
+// StdDevCollapse counts the consecutive generations, it must be used by
+// pointer. It implements evolve.Resetter, the engine thus resets it when the
+// evolution starts.
+type StdDevCollapse struct {
+	// Threshold is the standard deviation at or below which the fitness
+	// scores are considered collapsed.
+	Threshold float64
+
+	// Generations is the number of consecutive generations the standard
+	// deviation must be collapsed for the condition to be satisfied. If it's
+	// 0, the condition is satisfied as soon as the standard deviation
+	// collapses.
+	Generations int
+
+	count int // consecutive generations with a collapsed std dev
+}
+
+// IsSatisfied reports whether the standard deviation of the fitness scores has
+// collapsed.
+func (sc *StdDevCollapse) IsSatisfied(stats *evolve.PopulationStats) bool {
+	if stats.StdDev <= sc.Threshold {
+		sc.count++
+	} else {
+		sc.count = 0
+	}
+	return sc.count > 0 && sc.count >= sc.Generations
+}
+
+// ResetCondition implements evolve.Resetter.
+func (sc *StdDevCollapse) ResetCondition() { sc.count = 0 }
+
+// String returns a string representation of this condition.
+func (sc *StdDevCollapse) String() string {
+	return fmt.Sprintf("Fitness std dev collapsed (<= %v) for %d generations", sc.Threshold, sc.Generations)
+}
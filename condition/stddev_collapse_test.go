@@ -0,0 +1,35 @@
+package condition
+
+import (
+	"testing"
+
+	"github.com/arl/evolve"
+)
+
+func TestStdDevCollapse(t *testing.T) {
+	stddevs := []float64{3, 0.1, 2, 0.1, 0.05, 0}
+
+	t.Run("immediate", func(t *testing.T) {
+		cond := &StdDevCollapse{Threshold: 0.1}
+		want := []bool{false, true, false, true, true, true}
+		for gen, sd := range stddevs {
+			if got := cond.IsSatisfied(&evolve.PopulationStats{GenNumber: gen, StdDev: sd}); got != want[gen] {
+				t.Errorf("generation %d: IsSatisfied() = %t, want %t", gen, got, want[gen])
+			}
+		}
+	})
+
+	t.Run("consecutive", func(t *testing.T) {
+		cond := &StdDevCollapse{Threshold: 0.1, Generations: 3}
+		want := []bool{false, false, false, false, false, true}
+		for gen, sd := range stddevs {
+			if got := cond.IsSatisfied(&evolve.PopulationStats{GenNumber: gen, StdDev: sd}); got != want[gen] {
+				t.Errorf("generation %d: IsSatisfied() = %t, want %t", gen, got, want[gen])
+			}
+		}
+		cond.ResetCondition()
+		if cond.IsSatisfied(&evolve.PopulationStats{StdDev: 0}) {
+			t.Errorf("should not be satisfied after reset")
+		}
+	})
+}
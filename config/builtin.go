@@ -0,0 +1,284 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/arl/evolve"
+	"github.com/arl/evolve/condition"
+	"github.com/arl/evolve/factory"
+	"github.com/arl/evolve/generator"
+	"github.com/arl/evolve/operator/mutation"
+	"github.com/arl/evolve/operator/xover"
+	"github.com/arl/evolve/selection"
+)
+
+// Register the components provided by this project in the Default registry.
+//
+// Parameters, and their default values, are documented next to each
+// component.
+func init() {
+	// {"type": "bitstring", "length": 64}
+	RegisterFactory("bitstring", func(p Params) (evolve.Factory, error) {
+		var params struct{ Length uint }
+		if err := p.Decode(&params); err != nil {
+			return nil, err
+		}
+		if params.Length == 0 {
+			return nil, errors.New("length must be strictly positive")
+		}
+		return factory.Bitstring(params.Length), nil
+	})
+	// {"type": "string", "alphabet": "ABC", "length": 10}
+	RegisterFactory("string", func(p Params) (evolve.Factory, error) {
+		var params struct {
+			Alphabet string
+			Length   int
+		}
+		if err := p.Decode(&params); err != nil {
+			return nil, err
+		}
+		return factory.NewString(params.Alphabet, params.Length)
+	})
+
+	// {"type": "tournament", "probability": 0.7}
+	RegisterSelection("tournament", func(p Params) (evolve.Selection, error) {
+		params := struct{ Probability float64 }{0.7}
+		if err := p.Decode(&params); err != nil {
+			return nil, err
+		}
+		ts := selection.NewTournament()
+		return ts, ts.SetProb(params.Probability)
+	})
+	// {"type": "truncation", "ratio": 0.5}, the ratio varies in [0.5, 1] if
+	// not set.
+	RegisterSelection("truncation", func(p Params) (evolve.Selection, error) {
+		var params struct{ Ratio float64 }
+		if err := p.Decode(&params); err != nil {
+			return nil, err
+		}
+		ts := selection.NewTruncation()
+		if params.Ratio != 0 {
+			return ts, ts.SetRatio(params.Ratio)
+		}
+		return ts, nil
+	})
+	simpleSelection := func(sel evolve.Selection) func(Params) (evolve.Selection, error) {
+		return func(p Params) (evolve.Selection, error) {
+			return sel, p.Decode(&struct{}{})
+		}
+	}
+	RegisterSelection("roulette-wheel", simpleSelection(selection.RouletteWheel))
+	RegisterSelection("rank", simpleSelection(selection.Rank))
+	RegisterSelection("sigma-scaling", simpleSelection(selection.SigmaScaling))
+	RegisterSelection("stochastic-universal-sampling", simpleSelection(selection.StochasticUniversalSampling{}))
+	RegisterSelection("identity", simpleSelection(selection.Identity{}))
+
+	// {"type": "crossover", "mater": "bitstring", "probability": 1, "points": 1}
+	RegisterOperator("crossover", func(p Params) (evolve.Operator, error) {
+		params := struct {
+			Mater       Spec
+			Probability float64
+			Points      int64
+		}{Probability: 1, Points: 1}
+		if err := p.Decode(&params); err != nil {
+			return nil, err
+		}
+		if params.Probability < 0 || params.Probability > 1 {
+			return nil, fmt.Errorf("invalid crossover probability %v", params.Probability)
+		}
+		mater, err := p.Mater(params.Mater)
+		if err != nil {
+			return nil, err
+		}
+		xo := xover.New(mater)
+		xo.Probability = generator.ConstFloat64(params.Probability)
+		xo.Points = generator.ConstInt(params.Points)
+		return xo, nil
+	})
+	// {"type": "bitstring-mutation", "probability": 1, "flips": 1}
+	RegisterOperator("bitstring-mutation", func(p Params) (evolve.Operator, error) {
+		params := struct {
+			Probability float64
+			Flips       int64
+		}{1, 1}
+		if err := p.Decode(&params); err != nil {
+			return nil, err
+		}
+		return mutation.New(&mutation.Bitstring{
+			Probability: generator.ConstFloat64(params.Probability),
+			FlipCount:   generator.ConstInt(params.Flips),
+		}), nil
+	})
+	// {"type": "string-mutation", "alphabet": "ABC", "probability": 0.01}
+	RegisterOperator("string-mutation", func(p Params) (evolve.Operator, error) {
+		var params struct {
+			Alphabet    string
+			Probability float64
+		}
+		if err := p.Decode(&params); err != nil {
+			return nil, err
+		}
+		if params.Alphabet == "" {
+			return nil, errors.New("empty alphabet")
+		}
+		return mutation.New(&mutation.String{
+			Alphabet:    params.Alphabet,
+			Probability: generator.ConstFloat64(params.Probability),
+		}), nil
+	})
+	// {"type": "list-order", "count": 1, "amount": 1}
+	RegisterOperator("list-order", func(p Params) (evolve.Operator, error) {
+		params := struct{ Count, Amount int64 }{1, 1}
+		if err := p.Decode(&params); err != nil {
+			return nil, err
+		}
+		return &mutation.ListOrder{
+			Count:          generator.ConstInt(params.Count),
+			MutationAmount: generator.ConstInt(params.Amount),
+		}, nil
+	})
+
+	simpleMater := func(m xover.Mater) func(Params) (xover.Mater, error) {
+		return func(p Params) (xover.Mater, error) {
+			return m, p.Decode(&struct{}{})
+		}
+	}
+	RegisterMater("bitstring", simpleMater(xover.BitstringMater{}))
+	RegisterMater("byteslice", simpleMater(xover.ByteSliceMater{}))
+	RegisterMater("intslice", simpleMater(xover.IntSliceMater{}))
+	RegisterMater("string", simpleMater(xover.StringMater{}))
+	RegisterMater("pmx", simpleMater(xover.PMX{}))
+
+	// {"type": "generation-count", "n": 100}
+	RegisterCondition("generation-count", func(p Params) (evolve.Condition, error) {
+		var params struct{ N int }
+		if err := p.Decode(&params); err != nil {
+			return nil, err
+		}
+		return condition.GenerationCount(params.N), nil
+	})
+	// {"type": "elapsed-time", "duration": "1m30s"}
+	RegisterCondition("elapsed-time", func(p Params) (evolve.Condition, error) {
+		var params struct{ Duration string }
+		if err := p.Decode(&params); err != nil {
+			return nil, err
+		}
+		d, err := time.ParseDuration(params.Duration)
+		if err != nil {
+			return nil, err
+		}
+		return condition.ElapsedTime(d), nil
+	})
+	// {"type": "target-fitness", "fitness": 0}, natural if the evaluator is. The
+	// fitness defaults to the optimum of evaluators having an Optimum() float64
+	// method, such as the problems of the benchmark/binary package, and to 0
+	// for other evaluators.
+	RegisterCondition("target-fitness", func(p Params) (evolve.Condition, error) {
+		var params struct{ Fitness *float64 }
+		if err := p.Decode(&params); err != nil {
+			return nil, err
+		}
+		if params.Fitness == nil {
+			var opt float64
+			if eval, ok := p.Evaluator.(interface{ Optimum() float64 }); ok {
+				opt = eval.Optimum()
+			}
+			if math.IsNaN(opt) {
+				return nil, errors.New("missing fitness, the optimum of the evaluator is unknown")
+			}
+			params.Fitness = &opt
+		}
+		return condition.TargetFitness{Fitness: *params.Fitness, Natural: p.Evaluator.IsNatural()}, nil
+	})
+	// {"type": "evaluation-count", "n": 10000}
+	RegisterCondition("evaluation-count", func(p Params) (evolve.Condition, error) {
+		var params struct{ N int64 }
+		if err := p.Decode(&params); err != nil {
+			return nil, err
+		}
+		return condition.EvaluationCount(params.N), nil
+	})
+	// {"type": "stagnation", "generations": 50, "epsilon": 0}
+	RegisterCondition("stagnation", func(p Params) (evolve.Condition, error) {
+		var params struct {
+			Generations int
+			Epsilon     float64
+		}
+		if err := p.Decode(&params); err != nil {
+			return nil, err
+		}
+		return &condition.Stagnation{Generations: params.Generations, Epsilon: params.Epsilon}, nil
+	})
+	// {"type": "mean-plateau", "generations": 50, "epsilon": 0}
+	RegisterCondition("mean-plateau", func(p Params) (evolve.Condition, error) {
+		var params struct {
+			Generations int
+			Epsilon     float64
+		}
+		if err := p.Decode(&params); err != nil {
+			return nil, err
+		}
+		return &condition.MeanPlateau{Generations: params.Generations, Epsilon: params.Epsilon}, nil
+	})
+	// {"type": "stddev-collapse", "threshold": 0.01, "generations": 0}
+	RegisterCondition("stddev-collapse", func(p Params) (evolve.Condition, error) {
+		var params struct {
+			Threshold   float64
+			Generations int
+		}
+		if err := p.Decode(&params); err != nil {
+			return nil, err
+		}
+		return &condition.StdDevCollapse{Threshold: params.Threshold, Generations: params.Generations}, nil
+	})
+	// {"type": "and", "conditions": [...]} and {"type": "or", "conditions": [...]}
+	combinator := func(combine func(...evolve.Condition) *condition.Combinator) func(Params) (evolve.Condition, error) {
+		return func(p Params) (evolve.Condition, error) {
+			var params struct{ Conditions []Spec }
+			if err := p.Decode(&params); err != nil {
+				return nil, err
+			}
+			conds := make([]evolve.Condition, len(params.Conditions))
+			for i, spec := range params.Conditions {
+				cond, err := p.Condition(spec)
+				if err != nil {
+					return nil, fmt.Errorf("conditions[%d]: %v", i, err)
+				}
+				conds[i] = cond
+			}
+			return combine(conds...), nil
+		}
+	}
+	RegisterCondition("and", combinator(condition.And))
+	RegisterCondition("or", combinator(condition.Or))
+	// {"type": "not", "condition": {...}}
+	RegisterCondition("not", func(p Params) (evolve.Condition, error) {
+		var params struct{ Condition Spec }
+		if err := p.Decode(&params); err != nil {
+			return nil, err
+		}
+		cond, err := p.Condition(params.Condition)
+		if err != nil {
+			return nil, err
+		}
+		return condition.Not(cond), nil
+	})
+	// {"type": "after", "generations": 100, "condition": {...}}
+	RegisterCondition("after", func(p Params) (evolve.Condition, error) {
+		var params struct {
+			Generations int
+			Condition   Spec
+		}
+		if err := p.Decode(&params); err != nil {
+			return nil, err
+		}
+		cond, err := p.Condition(params.Condition)
+		if err != nil {
+			return nil, err
+		}
+		return condition.After(params.Generations, cond), nil
+	})
+}
@@ -0,0 +1,288 @@
+// Package config describes whole evolution runs in JSON configuration files,
+// so that experiments can be versioned, and repeated, without recompiling Go
+// code.
+//
+// A configuration names the components of the run, and gives their
+// parameters:
+//
+//	{
+//	  "name": "onemax",
+//	  "seed": 42,
+//	  "population": 100,
+//	  "elites": 2,
+//	  "factory": {"type": "bitstring", "length": 64},
+//	  "evaluator": "onemax",
+//	  "selection": {"type": "tournament", "probability": 0.8},
+//	  "operators": [
+//	    {"type": "crossover", "mater": "bitstring", "probability": 0.7, "points": 1},
+//	    {"type": "bitstring-mutation", "probability": 0.05, "flips": 1}
+//	  ],
+//	  "conditions": [
+//	    {"type": "target-fitness", "fitness": 64},
+//	    {"type": "generation-count", "n": 500}
+//	  ]
+//	}
+//
+// Every component is described by a spec, an object whose "type" is the name
+// of the component in a Registry, and whose other fields are the parameters of
+// the component. A spec without parameters can be abbreviated to its type.
+//
+// The Default registry holds the components provided by this project (see
+// Registry.Names for the list). Users register their own components, in
+// particular their evaluators, before building a run.
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"os"
+
+	"github.com/arl/evolve"
+	"github.com/arl/evolve/engine"
+	"github.com/arl/evolve/operator"
+	"github.com/arl/evolve/pkg/mt19937"
+)
+
+// Config describes an evolution run.
+type Config struct {
+	// Name is the name of the run.
+	Name string `json:"name,omitempty"`
+
+	// Seed seeds the engine source of randomness.
+	Seed int64 `json:"seed"`
+
+	// Population is the population size, Elites the number of elites.
+	Population int `json:"population"`
+	Elites     int `json:"elites,omitempty"`
+
+	// Deterministic enables the engine deterministic mode (see
+	// engine.Deterministic).
+	Deterministic bool `json:"deterministic,omitempty"`
+
+	// Workers and ChunkSize configure the concurrent application of the
+	// operators (see engine.Generational).
+	Workers   int `json:"workers,omitempty"`
+	ChunkSize int `json:"chunk_size,omitempty"`
+
+	Factory   Spec `json:"factory"`
+	Evaluator Spec `json:"evaluator"`
+	Selection Spec `json:"selection"`
+
+	// Operators are applied in sequence, as an operator.Pipeline.
+	Operators []Spec `json:"operators"`
+
+	// Conditions are the termination conditions, the evolution stops when
+	// one of them is satisfied.
+	Conditions []Spec `json:"conditions"`
+}
+
+// Spec describes a component: its registered name, or type, and its
+// parameters.
+//
+// In JSON, a spec is an object holding the "type" and the parameters of the
+// component, or a string, the type, for components without parameters.
+type Spec struct {
+	Type   string
+	Params json.RawMessage // JSON object, or nil
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (s *Spec) UnmarshalJSON(data []byte) error {
+	var typ string
+	if err := json.Unmarshal(data, &typ); err == nil {
+		*s = Spec{Type: typ}
+		return nil
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return errors.New("component spec must be a string or an object")
+	}
+	raw, ok := fields["type"]
+	if !ok {
+		return errors.New("component spec without type")
+	}
+	if err := json.Unmarshal(raw, &typ); err != nil {
+		return errors.New("component type must be a string")
+	}
+	delete(fields, "type")
+
+	*s = Spec{Type: typ}
+	if len(fields) > 0 {
+		params, err := json.Marshal(fields)
+		if err != nil {
+			return err
+		}
+		s.Params = params
+	}
+	return nil
+}
+
+// MarshalJSON implements json.Marshaler.
+func (s Spec) MarshalJSON() ([]byte, error) {
+	if len(s.Params) == 0 {
+		return json.Marshal(s.Type)
+	}
+	fields := make(map[string]json.RawMessage)
+	if err := json.Unmarshal(s.Params, &fields); err != nil {
+		return nil, err
+	}
+	typ, _ := json.Marshal(s.Type)
+	fields["type"] = typ
+	return json.Marshal(fields)
+}
+
+// Parse parses a JSON configuration. Unknown fields are reported as errors.
+func Parse(r io.Reader) (*Config, error) {
+	dec := json.NewDecoder(r)
+	dec.DisallowUnknownFields()
+	var c Config
+	if err := dec.Decode(&c); err != nil {
+		return nil, fmt.Errorf("invalid configuration: %v", err)
+	}
+	return &c, nil
+}
+
+// Load parses the JSON configuration file at path.
+func Load(path string) (*Config, error) {
+	buf, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	c, err := Parse(bytes.NewReader(buf))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	return c, nil
+}
+
+// A Run is an evolution run built from a configuration.
+type Run struct {
+	Config    *Config
+	Engine    *engine.Engine
+	Evaluator evolve.Evaluator
+
+	// Options are the options given to Engine.Evolve, such as the number of
+	// elites. The termination conditions are options of the engine.
+	Options []func(*engine.Engine) error
+}
+
+// Evolve runs the evolution, see engine.Engine.Evolve.
+func (r *Run) Evolve() (evolve.Population, []evolve.Condition, error) {
+	return r.Engine.Evolve(r.Config.Population, r.Options...)
+}
+
+// Build builds the run described by c with the components of the Default
+// registry. options are additional engine options, such as observers.
+func Build(c *Config, options ...func(*engine.Engine) error) (*Run, error) {
+	return Default.Build(c, options...)
+}
+
+// Build builds the run described by c with the components of r. options are
+// additional engine options, such as observers.
+func (r *Registry) Build(c *Config, options ...func(*engine.Engine) error) (*Run, error) {
+	if c.Population <= 0 {
+		return nil, errors.New("population: must be strictly positive")
+	}
+	if len(c.Operators) == 0 {
+		return nil, errors.New("operators: at least one operator is required")
+	}
+	if len(c.Conditions) == 0 {
+		return nil, errors.New("conditions: at least one condition is required")
+	}
+
+	fac, err := r.factory(c.Factory)
+	if err != nil {
+		return nil, fmt.Errorf("factory: %v", err)
+	}
+	eval, err := r.BuildEvaluator(c)
+	if err != nil {
+		return nil, err
+	}
+	sel, err := r.selection(c.Selection, eval)
+	if err != nil {
+		return nil, fmt.Errorf("selection: %v", err)
+	}
+	var pipe operator.Pipeline
+	for i, spec := range c.Operators {
+		op, err := r.operator(spec, eval)
+		if err != nil {
+			return nil, fmt.Errorf("operators[%d]: %v", i, err)
+		}
+		pipe = append(pipe, op)
+	}
+	var op evolve.Operator = pipe
+	if len(pipe) == 1 {
+		op = pipe[0]
+	}
+
+	opts := []func(*engine.Engine) error{engine.Rand(rand.New(mt19937.New(c.Seed)))}
+	if c.Deterministic {
+		opts = append(opts, engine.Deterministic())
+	}
+	conds, err := r.BuildConditions(c, eval)
+	if err != nil {
+		return nil, err
+	}
+	for _, cond := range conds {
+		opts = append(opts, engine.EndOn(cond))
+	}
+
+	epocher := &engine.Generational{
+		Op:        op,
+		Eval:      eval,
+		Sel:       sel,
+		Workers:   c.Workers,
+		ChunkSize: c.ChunkSize,
+	}
+	eng, err := engine.New(fac, eval, epocher, append(opts, options...)...)
+	if err != nil {
+		return nil, err
+	}
+	return &Run{
+		Config:    c,
+		Engine:    eng,
+		Evaluator: eval,
+		Options:   []func(*engine.Engine) error{engine.Elites(c.Elites)},
+	}, nil
+}
+
+// BuildEvaluator builds the evaluator described by c with the components of
+// the Default registry, without building the rest of the run.
+func BuildEvaluator(c *Config) (evolve.Evaluator, error) {
+	return Default.BuildEvaluator(c)
+}
+
+// BuildEvaluator builds the evaluator described by c with the components of
+// r, without building the rest of the run.
+func (r *Registry) BuildEvaluator(c *Config) (evolve.Evaluator, error) {
+	eval, err := r.evaluator(c.Evaluator)
+	if err != nil {
+		return nil, fmt.Errorf("evaluator: %v", err)
+	}
+	return eval, nil
+}
+
+// BuildConditions builds the termination conditions described by c, for the
+// evaluator eval, with the components of the Default registry.
+func BuildConditions(c *Config, eval evolve.Evaluator) ([]evolve.Condition, error) {
+	return Default.BuildConditions(c, eval)
+}
+
+// BuildConditions builds the termination conditions described by c, for the
+// evaluator eval, with the components of r.
+func (r *Registry) BuildConditions(c *Config, eval evolve.Evaluator) ([]evolve.Condition, error) {
+	conds := make([]evolve.Condition, len(c.Conditions))
+	for i, spec := range c.Conditions {
+		cond, err := r.condition(spec, eval)
+		if err != nil {
+			return nil, fmt.Errorf("conditions[%d]: %v", i, err)
+		}
+		conds[i] = cond
+	}
+	return conds, nil
+}
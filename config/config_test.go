@@ -0,0 +1,219 @@
+package config
+
+import (
+	"encoding/json"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/arl/evolve"
+	"github.com/arl/evolve/condition"
+	"github.com/arl/evolve/pkg/bitstring"
+)
+
+func init() {
+	RegisterEvaluator("config-test-onemax", func(p Params) (evolve.Evaluator, error) {
+		return evolve.EvaluatorFunc(true, func(cand interface{}, pop []interface{}) float64 {
+			return float64(cand.(*bitstring.Bitstring).OnesCount())
+		}), p.Decode(&struct{}{})
+	})
+}
+
+const onemax = `{
+  "name": "onemax",
+  "seed": 42,
+  "population": 50,
+  "elites": 2,
+  "factory": {"type": "bitstring", "length": 20},
+  "evaluator": "config-test-onemax",
+  "selection": {"type": "tournament", "probability": 0.8},
+  "operators": [
+    {"type": "crossover", "mater": "bitstring", "probability": 0.7},
+    {"type": "bitstring-mutation", "probability": 0.05}
+  ],
+  "conditions": [
+    {"type": "target-fitness", "fitness": 20},
+    {"type": "generation-count", "n": 500}
+  ]
+}`
+
+func TestBuild(t *testing.T) {
+	run := func() evolve.Population {
+		c, err := Parse(strings.NewReader(onemax))
+		require.NoError(t, err)
+
+		r, err := Build(c)
+		require.NoError(t, err)
+
+		pop, satisfied, err := r.Evolve()
+		require.NoError(t, err)
+		require.Len(t, satisfied, 1)
+		assert.Equal(t, "Reached target fitness of 20.000000", satisfied[0].String())
+		require.Len(t, pop, 50)
+		assert.Equal(t, 20.0, pop[0].Fitness)
+		return pop
+	}
+
+	// Same seed, same run.
+	pop1, pop2 := run(), run()
+	for i := range pop1 {
+		assert.Equal(t, pop1[i].Fitness, pop2[i].Fitness)
+	}
+}
+
+func TestBuildErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		edit    func(*Config)
+		wantErr string
+	}{
+		{
+			name:    "population",
+			edit:    func(c *Config) { c.Population = 0 },
+			wantErr: "population: must be strictly positive",
+		},
+		{
+			name:    "no operators",
+			edit:    func(c *Config) { c.Operators = nil },
+			wantErr: "operators: at least one operator is required",
+		},
+		{
+			name:    "unknown evaluator",
+			edit:    func(c *Config) { c.Evaluator = Spec{Type: "foo"} },
+			wantErr: `evaluator: unknown evaluator "foo"`,
+		},
+		{
+			name:    "unknown mater",
+			edit:    func(c *Config) { c.Operators[0].Params = json.RawMessage(`{"mater":"foo"}`) },
+			wantErr: `operators[0]: unknown mater "foo"`,
+		},
+		{
+			name:    "unknown parameter",
+			edit:    func(c *Config) { c.Factory.Params = json.RawMessage(`{"length":10,"foo":1}`) },
+			wantErr: `factory: invalid parameters: json: unknown field "foo"`,
+		},
+		{
+			name: "nested condition",
+			edit: func(c *Config) {
+				c.Conditions[1].Type = "or"
+				c.Conditions[1].Params = json.RawMessage(`{"conditions":["generation-count","foo"]}`)
+			},
+			wantErr: `conditions[1]: conditions[1]: unknown condition "foo"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := Parse(strings.NewReader(onemax))
+			require.NoError(t, err)
+			tt.edit(c)
+
+			_, err = Build(c)
+			assert.EqualError(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestParseUnknownField(t *testing.T) {
+	_, err := Parse(strings.NewReader(`{"population": 10, "populaton": 10}`))
+	assert.EqualError(t, err, `invalid configuration: json: unknown field "populaton"`)
+}
+
+func TestBuildCombinators(t *testing.T) {
+	c, err := Parse(strings.NewReader(onemax))
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal([]byte(`[{
+		"type": "or",
+		"conditions": [
+			{"type": "after", "generations": 5, "condition": {"type": "target-fitness", "fitness": 20}},
+			{"type": "not", "condition": {"type": "elapsed-time", "duration": "1h"}}
+		]
+	}]`), &c.Conditions))
+
+	r, err := Build(c)
+	require.NoError(t, err)
+
+	_, satisfied, err := r.Evolve()
+	require.NoError(t, err)
+	require.Len(t, satisfied, 1)
+	comb, ok := satisfied[0].(*condition.Combinator)
+	require.True(t, ok)
+	assert.Equal(t, "(AFTER 5 generations THEN Reached target fitness of 20.000000) OR (NOT Elapsed Time (1h0m0s))", comb.String())
+}
+
+func TestSpecJSON(t *testing.T) {
+	tests := []struct {
+		in, out string
+		want    Spec
+	}{
+		{
+			in:   `"rank"`,
+			out:  `"rank"`,
+			want: Spec{Type: "rank"},
+		},
+		{
+			in:   `{"type": "rank"}`,
+			out:  `"rank"`,
+			want: Spec{Type: "rank"},
+		},
+		{
+			in:   `{"type": "tournament", "probability": 0.8}`,
+			out:  `{"probability":0.8,"type":"tournament"}`,
+			want: Spec{Type: "tournament", Params: json.RawMessage(`{"probability":0.8}`)},
+		},
+	}
+	for _, tt := range tests {
+		var spec Spec
+		require.NoError(t, json.Unmarshal([]byte(tt.in), &spec))
+		assert.Equal(t, tt.want, spec)
+
+		buf, err := json.Marshal(spec)
+		require.NoError(t, err)
+		assert.Equal(t, tt.out, string(buf))
+	}
+
+	var spec Spec
+	assert.Error(t, json.Unmarshal([]byte(`{"probability": 0.8}`), &spec))
+	assert.Error(t, json.Unmarshal([]byte(`3`), &spec))
+}
+
+func TestRegistry(t *testing.T) {
+	r := NewRegistry()
+	ctor := func(Params) (evolve.Selection, error) { return nil, nil }
+	r.RegisterSelection("b", ctor)
+	r.RegisterSelection("a", ctor)
+	assert.Equal(t, []string{"a", "b"}, r.Names(Selection))
+	assert.Empty(t, r.Names(Operator))
+
+	assert.Panics(t, func() { r.RegisterSelection("a", ctor) })
+	assert.Panics(t, func() { r.RegisterSelection("", ctor) })
+
+	assert.Contains(t, Default.Names(Condition), "stagnation")
+}
+
+// optimumEvaluator is an evaluator with a known optimum.
+type optimumEvaluator struct{ evolve.Evaluator }
+
+func (optimumEvaluator) Optimum() float64 { return 18 }
+
+// unknownOptimumEvaluator is an evaluator whose optimum is unknown.
+type unknownOptimumEvaluator struct{ evolve.Evaluator }
+
+func (unknownOptimumEvaluator) Optimum() float64 { return math.NaN() }
+
+func TestTargetFitnessOptimum(t *testing.T) {
+	eval := optimumEvaluator{evolve.EvaluatorFunc(true, nil)}
+	cond, err := Default.condition(Spec{Type: "target-fitness"}, eval)
+	require.NoError(t, err)
+	assert.Equal(t, condition.TargetFitness{Fitness: 18, Natural: true}, cond)
+
+	// Without Optimum method, the target fitness is 0.
+	cond, err = Default.condition(Spec{Type: "target-fitness"}, eval.Evaluator)
+	require.NoError(t, err)
+	assert.Equal(t, condition.TargetFitness{Fitness: 0, Natural: true}, cond)
+
+	_, err = Default.condition(Spec{Type: "target-fitness"}, unknownOptimumEvaluator{eval.Evaluator})
+	assert.EqualError(t, err, "missing fitness, the optimum of the evaluator is unknown")
+}
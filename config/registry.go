@@ -0,0 +1,237 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/arl/evolve"
+	"github.com/arl/evolve/operator/xover"
+)
+
+// Kind is a kind of component.
+type Kind string
+
+// Kinds of components.
+const (
+	Factory   Kind = "factory"
+	Evaluator Kind = "evaluator"
+	Selection Kind = "selection"
+	Operator  Kind = "operator"
+	Mater     Kind = "mater"
+	Condition Kind = "condition"
+)
+
+// Params holds the parameters of a component, as given in a configuration.
+type Params struct {
+	raw json.RawMessage
+	reg *Registry
+
+	// Evaluator is the evaluator of the run, it is nil when the factory and
+	// the evaluator are built.
+	Evaluator evolve.Evaluator
+}
+
+// Decode decodes the parameters into v, as json.Unmarshal does. Unknown
+// parameters are reported as errors.
+func (p Params) Decode(v interface{}) error {
+	if len(p.raw) == 0 {
+		return nil
+	}
+	dec := json.NewDecoder(bytes.NewReader(p.raw))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		return fmt.Errorf("invalid parameters: %v", err)
+	}
+	return nil
+}
+
+// Condition builds the condition described by spec, for combinators.
+func (p Params) Condition(spec Spec) (evolve.Condition, error) {
+	return p.reg.condition(spec, p.Evaluator)
+}
+
+// Mater builds the mater described by spec, for crossover operators.
+func (p Params) Mater(spec Spec) (xover.Mater, error) {
+	fn, err := p.reg.lookup(Mater, spec.Type)
+	if err != nil {
+		return nil, err
+	}
+	return fn.(func(Params) (xover.Mater, error))(p.with(spec))
+}
+
+// with returns the parameters of spec, in the context of p.
+func (p Params) with(spec Spec) Params {
+	return Params{raw: spec.Params, reg: p.reg, Evaluator: p.Evaluator}
+}
+
+// A Registry maps the names used in configurations to the constructors of the
+// components. Constructors build a component from its parameters.
+//
+// A Registry is safe for concurrent use.
+type Registry struct {
+	mu    sync.RWMutex
+	ctors map[Kind]map[string]interface{}
+}
+
+// NewRegistry returns an empty registry. Most users should register their
+// components in the Default registry instead, which holds the components
+// provided by this project.
+func NewRegistry() *Registry {
+	return &Registry{ctors: make(map[Kind]map[string]interface{})}
+}
+
+// Default is the default registry, used by Build. It holds the factories,
+// selection strategies, operators, maters and conditions provided by this
+// project, but no evaluator, since evaluators are problem-specific.
+var Default = NewRegistry()
+
+func (r *Registry) register(kind Kind, name string, ctor interface{}) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if name == "" {
+		panic(fmt.Sprintf("config: empty %s name", kind))
+	}
+	if r.ctors[kind] == nil {
+		r.ctors[kind] = make(map[string]interface{})
+	}
+	if _, dup := r.ctors[kind][name]; dup {
+		panic(fmt.Sprintf("config: %s %q registered twice", kind, name))
+	}
+	r.ctors[kind][name] = ctor
+}
+
+func (r *Registry) lookup(kind Kind, name string) (interface{}, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	ctor, ok := r.ctors[kind][name]
+	if !ok {
+		return nil, fmt.Errorf("unknown %s %q", kind, name)
+	}
+	return ctor, nil
+}
+
+// Names returns the sorted names of the registered components of the given
+// kind.
+func (r *Registry) Names(kind Kind) []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var names []string
+	for name := range r.ctors[kind] {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// RegisterFactory registers a factory constructor under name. It panics if
+// name is empty or already registered.
+func (r *Registry) RegisterFactory(name string, ctor func(Params) (evolve.Factory, error)) {
+	r.register(Factory, name, ctor)
+}
+
+// RegisterEvaluator registers an evaluator constructor under name. It panics
+// if name is empty or already registered.
+func (r *Registry) RegisterEvaluator(name string, ctor func(Params) (evolve.Evaluator, error)) {
+	r.register(Evaluator, name, ctor)
+}
+
+// RegisterSelection registers a selection strategy constructor under name. It
+// panics if name is empty or already registered.
+func (r *Registry) RegisterSelection(name string, ctor func(Params) (evolve.Selection, error)) {
+	r.register(Selection, name, ctor)
+}
+
+// RegisterOperator registers an operator constructor under name. It panics if
+// name is empty or already registered.
+func (r *Registry) RegisterOperator(name string, ctor func(Params) (evolve.Operator, error)) {
+	r.register(Operator, name, ctor)
+}
+
+// RegisterMater registers a crossover mater constructor under name. It panics
+// if name is empty or already registered.
+func (r *Registry) RegisterMater(name string, ctor func(Params) (xover.Mater, error)) {
+	r.register(Mater, name, ctor)
+}
+
+// RegisterCondition registers a termination condition constructor under
+// name. It panics if name is empty or already registered.
+func (r *Registry) RegisterCondition(name string, ctor func(Params) (evolve.Condition, error)) {
+	r.register(Condition, name, ctor)
+}
+
+// RegisterFactory registers a factory constructor in the Default registry.
+func RegisterFactory(name string, ctor func(Params) (evolve.Factory, error)) {
+	Default.RegisterFactory(name, ctor)
+}
+
+// RegisterEvaluator registers an evaluator constructor in the Default
+// registry.
+func RegisterEvaluator(name string, ctor func(Params) (evolve.Evaluator, error)) {
+	Default.RegisterEvaluator(name, ctor)
+}
+
+// RegisterSelection registers a selection strategy constructor in the Default
+// registry.
+func RegisterSelection(name string, ctor func(Params) (evolve.Selection, error)) {
+	Default.RegisterSelection(name, ctor)
+}
+
+// RegisterOperator registers an operator constructor in the Default registry.
+func RegisterOperator(name string, ctor func(Params) (evolve.Operator, error)) {
+	Default.RegisterOperator(name, ctor)
+}
+
+// RegisterMater registers a crossover mater constructor in the Default
+// registry.
+func RegisterMater(name string, ctor func(Params) (xover.Mater, error)) {
+	Default.RegisterMater(name, ctor)
+}
+
+// RegisterCondition registers a termination condition constructor in the
+// Default registry.
+func RegisterCondition(name string, ctor func(Params) (evolve.Condition, error)) {
+	Default.RegisterCondition(name, ctor)
+}
+
+func (r *Registry) factory(spec Spec) (evolve.Factory, error) {
+	fn, err := r.lookup(Factory, spec.Type)
+	if err != nil {
+		return nil, err
+	}
+	return fn.(func(Params) (evolve.Factory, error))(Params{raw: spec.Params, reg: r})
+}
+
+func (r *Registry) evaluator(spec Spec) (evolve.Evaluator, error) {
+	fn, err := r.lookup(Evaluator, spec.Type)
+	if err != nil {
+		return nil, err
+	}
+	return fn.(func(Params) (evolve.Evaluator, error))(Params{raw: spec.Params, reg: r})
+}
+
+func (r *Registry) selection(spec Spec, eval evolve.Evaluator) (evolve.Selection, error) {
+	fn, err := r.lookup(Selection, spec.Type)
+	if err != nil {
+		return nil, err
+	}
+	return fn.(func(Params) (evolve.Selection, error))(Params{raw: spec.Params, reg: r, Evaluator: eval})
+}
+
+func (r *Registry) operator(spec Spec, eval evolve.Evaluator) (evolve.Operator, error) {
+	fn, err := r.lookup(Operator, spec.Type)
+	if err != nil {
+		return nil, err
+	}
+	return fn.(func(Params) (evolve.Operator, error))(Params{raw: spec.Params, reg: r, Evaluator: eval})
+}
+
+func (r *Registry) condition(spec Spec, eval evolve.Evaluator) (evolve.Condition, error) {
+	fn, err := r.lookup(Condition, spec.Type)
+	if err != nil {
+		return nil, err
+	}
+	return fn.(func(Params) (evolve.Condition, error))(Params{raw: spec.Params, reg: r, Evaluator: eval})
+}
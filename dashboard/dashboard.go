@@ -0,0 +1,337 @@
+// Package dashboard implements a live web dashboard for running evolutions.
+//
+// A Dashboard is both an engine observer and an http.Handler. It serves a
+// small web page, embedded in the binary, plotting the fitness curves in real
+// time and showing the current best candidate. Data is pushed to the page with
+// Server-Sent Events. The page also offers controls to pause, resume and abort
+// the evolution.
+//
+// Pausing blocks the observer, and thus the evolution loop, at the end of the
+// current generation. Aborting relies on a condition.UserAbort, that must be
+// set as a termination condition of the engine:
+//
+//	dash, _ := dashboard.New(dashboard.Format(formatCandidate))
+//	go http.ListenAndServe("localhost:8080", dash)
+//	eng.Evolve(popsize,
+//		engine.Observe(dash),
+//		engine.EndOn(dash.Condition()),
+//		...)
+package dashboard
+
+import (
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"math"
+	"net/http"
+	"sync"
+
+	"github.com/arl/evolve"
+	"github.com/arl/evolve/condition"
+)
+
+//go:embed static
+var static embed.FS
+
+// DefaultHistory is the default maximum number of generations kept in the
+// history sent to newly connected clients.
+const DefaultHistory = 5000
+
+// Dashboard is an engine observer serving a live web dashboard. It implements
+// engine.Finisher and http.Handler.
+//
+// The handler serves the following routes:
+//
+//	GET  /         the dashboard page and its assets
+//	GET  /events   the Server-Sent Events stream
+//	POST /pause    pauses the evolution
+//	POST /resume   resumes the evolution
+//	POST /abort    aborts the evolution
+type Dashboard struct {
+	title   string
+	format  func(interface{}) string
+	abort   *condition.UserAbort
+	maxhist int
+	files   http.Handler
+
+	mu      sync.Mutex
+	resumed *sync.Cond
+	paused  bool
+	state   string
+	history []point
+	clients map[chan []byte]struct{}
+}
+
+// point holds the data of a generation sent to the clients.
+type point struct {
+	Gen     int      `json:"gen"`
+	Elapsed float64  `json:"elapsed"`
+	Best    *float64 `json:"best"`
+	Mean    *float64 `json:"mean"`
+	StdDev  *float64 `json:"stddev"`
+	Worst   *float64 `json:"worst"`
+	Cand    string   `json:"cand,omitempty"`
+}
+
+// Evolution states.
+const (
+	stateWaiting = "waiting"
+	stateRunning = "running"
+	statePaused  = "paused"
+	stateAborted = "aborted"
+	stateDone    = "done"
+)
+
+// New returns a new Dashboard.
+//
+// By default, candidates are formatted with fmt.Sprint and the dashboard uses
+// its own condition.UserAbort.
+func New(options ...func(*Dashboard) error) (*Dashboard, error) {
+	sub, err := fs.Sub(static, "static")
+	if err != nil {
+		return nil, err
+	}
+	d := &Dashboard{
+		title:   "evolve",
+		format:  func(cand interface{}) string { return fmt.Sprint(cand) },
+		abort:   condition.NewUserAbort(),
+		maxhist: DefaultHistory,
+		files:   http.FileServer(http.FS(sub)),
+		state:   stateWaiting,
+		clients: make(map[chan []byte]struct{}),
+	}
+	d.resumed = sync.NewCond(&d.mu)
+	for _, opt := range options {
+		if err := opt(d); err != nil {
+			return nil, err
+		}
+	}
+	return d, nil
+}
+
+// Title sets the title of the dashboard page.
+func Title(title string) func(*Dashboard) error {
+	return func(d *Dashboard) error {
+		d.title = title
+		return nil
+	}
+}
+
+// Format sets the function formatting the best candidate. The formatted
+// candidate is shown as preformatted text.
+func Format(format func(cand interface{}) string) func(*Dashboard) error {
+	return func(d *Dashboard) error {
+		if format == nil {
+			return errors.New("nil candidate formatter")
+		}
+		d.format = format
+		return nil
+	}
+}
+
+// Abort sets the condition triggered by the abort control, in place of the
+// dashboard own condition.
+func Abort(ua *condition.UserAbort) func(*Dashboard) error {
+	return func(d *Dashboard) error {
+		if ua == nil {
+			return errors.New("nil abort condition")
+		}
+		d.abort = ua
+		return nil
+	}
+}
+
+// History sets the maximum number of generations kept in the history sent to
+// newly connected clients.
+func History(n int) func(*Dashboard) error {
+	return func(d *Dashboard) error {
+		if n < 1 {
+			return errors.New("history size must be strictly positive")
+		}
+		d.maxhist = n
+		return nil
+	}
+}
+
+// Condition returns the condition triggered by the abort control. It must be
+// set as a termination condition of the engine (see engine.EndOn).
+func (d *Dashboard) Condition() *condition.UserAbort { return d.abort }
+
+// Observe sends the population stats to the connected clients, then blocks
+// while the evolution is paused.
+//
+// Observe never blocks on a client: events are dropped for the clients that
+// can't keep up.
+func (d *Dashboard) Observe(stats *evolve.PopulationStats) {
+	p := point{
+		Gen:     stats.GenNumber,
+		Elapsed: stats.Elapsed.Seconds(),
+		Best:    finite(stats.BestFitness),
+		Mean:    finite(stats.Mean),
+		StdDev:  finite(stats.StdDev),
+		Worst:   finite(stats.WorstFitness),
+		Cand:    d.format(stats.BestCand),
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if stats.GenNumber == 0 {
+		// New evolution.
+		d.history = d.history[:0]
+	}
+	if len(d.history) == d.maxhist {
+		copy(d.history, d.history[1:])
+		d.history = d.history[:len(d.history)-1]
+	}
+	d.history = append(d.history, p)
+	d.broadcast("gen", p)
+
+	for d.paused && !d.abort.IsSatisfied(nil) {
+		d.setState(statePaused)
+		d.resumed.Wait()
+	}
+	d.setState(stateRunning)
+}
+
+// Finish notifies the clients that the evolution has ended.
+func (d *Dashboard) Finish(*evolve.PopulationStats) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.paused = false
+	if d.abort.IsSatisfied(nil) {
+		d.setState(stateAborted)
+	} else {
+		d.setState(stateDone)
+	}
+}
+
+// Pause pauses the evolution, at the end of the current generation.
+func (d *Dashboard) Pause() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.paused = true
+}
+
+// Resume resumes a paused evolution.
+func (d *Dashboard) Resume() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.paused = false
+	d.resumed.Broadcast()
+}
+
+// AbortEvolution aborts the evolution, which ends at the end of the current
+// generation, even if it is paused.
+func (d *Dashboard) AbortEvolution() {
+	d.abort.Abort()
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.resumed.Broadcast()
+}
+
+// setState sets the evolution state and notifies the clients. d.mu must be
+// held.
+func (d *Dashboard) setState(state string) {
+	if d.state == state {
+		return
+	}
+	d.state = state
+	d.broadcast("state", state)
+}
+
+// broadcast sends an event to all clients, dropping it for the clients whose
+// buffer is full. d.mu must be held.
+func (d *Dashboard) broadcast(event string, data interface{}) {
+	msg := encodeEvent(event, data)
+	for c := range d.clients {
+		select {
+		case c <- msg:
+		default:
+		}
+	}
+}
+
+func encodeEvent(event string, data interface{}) []byte {
+	buf, _ := json.Marshal(data)
+	return []byte(fmt.Sprintf("event: %s\ndata: %s\n\n", event, buf))
+}
+
+func finite(f float64) *float64 {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return nil
+	}
+	return &f
+}
+
+// ServeHTTP serves the dashboard. To serve it under a path other than the
+// root, use http.StripPrefix.
+func (d *Dashboard) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	path := r.URL.Path
+	switch path {
+	case "/events":
+		d.serveEvents(w, r)
+	case "/pause", "/resume", "/abort":
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		switch path {
+		case "/pause":
+			d.Pause()
+		case "/resume":
+			d.Resume()
+		case "/abort":
+			d.AbortEvolution()
+		}
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		d.files.ServeHTTP(w, r)
+	}
+}
+
+// serveEvents streams the events to a client. The history is sent first.
+func (d *Dashboard) serveEvents(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+
+	c := make(chan []byte, 256)
+	d.mu.Lock()
+	init := encodeEvent("init", struct {
+		Title   string  `json:"title"`
+		State   string  `json:"state"`
+		Max     int     `json:"max"`
+		History []point `json:"history"`
+	}{d.title, d.state, d.maxhist, d.history})
+	d.clients[c] = struct{}{}
+	d.mu.Unlock()
+
+	defer func() {
+		d.mu.Lock()
+		delete(d.clients, c)
+		d.mu.Unlock()
+	}()
+
+	if _, err := w.Write(init); err != nil {
+		return
+	}
+	flusher.Flush()
+	for {
+		select {
+		case msg := <-c:
+			if _, err := w.Write(msg); err != nil {
+				return
+			}
+			flusher.Flush()
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
@@ -0,0 +1,187 @@
+package dashboard
+
+import (
+	"bufio"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/arl/evolve"
+)
+
+func stats(gen int) *evolve.PopulationStats {
+	return &evolve.PopulationStats{
+		GenNumber:   gen,
+		BestCand:    "cand",
+		BestFitness: float64(gen),
+		Elapsed:     time.Duration(gen) * time.Second,
+	}
+}
+
+func TestDashboardPage(t *testing.T) {
+	d, err := New(Title("my run"))
+	require.NoError(t, err)
+	srv := httptest.NewServer(d)
+	defer srv.Close()
+
+	for _, path := range []string{"/", "/app.js", "/style.css"} {
+		resp, err := http.Get(srv.URL + path)
+		require.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, http.StatusOK, resp.StatusCode, path)
+	}
+
+	resp, err := http.Get(srv.URL + "/pause")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusMethodNotAllowed, resp.StatusCode)
+}
+
+// readEvents returns a channel receiving the events read from the SSE stream,
+// formatted as "name data".
+func readEvents(t *testing.T, url string) (<-chan string, func()) {
+	resp, err := http.Get(url + "/events")
+	require.NoError(t, err)
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+	c := make(chan string, 100)
+	go func() {
+		defer close(c)
+		var name string
+		s := bufio.NewScanner(resp.Body)
+		for s.Scan() {
+			line := s.Text()
+			switch {
+			case strings.HasPrefix(line, "event: "):
+				name = line[len("event: "):]
+			case strings.HasPrefix(line, "data: "):
+				c <- name + " " + line[len("data: "):]
+			}
+		}
+	}()
+	return c, func() { resp.Body.Close() }
+}
+
+func next(t *testing.T, c <-chan string) string {
+	t.Helper()
+	select {
+	case ev := <-c:
+		return ev
+	case <-time.After(5 * time.Second):
+		t.Fatal("timeout waiting for event")
+	}
+	return ""
+}
+
+func TestDashboardEvents(t *testing.T) {
+	d, err := New(Format(func(cand interface{}) string { return strings.ToUpper(cand.(string)) }), History(2))
+	require.NoError(t, err)
+	srv := httptest.NewServer(d)
+	defer srv.Close()
+
+	for i := 0; i < 3; i++ {
+		d.Observe(stats(i))
+	}
+
+	events, stop := readEvents(t, srv.URL)
+	defer stop()
+	init := next(t, events)
+	assert.True(t, strings.HasPrefix(init, `init {"title":"evolve","state":"running","max":2,"history":[{"gen":1,`), init)
+	assert.Contains(t, init, `{"gen":2,"elapsed":2,"best":2,"mean":0,"stddev":0,"worst":0,"cand":"CAND"}]}`)
+
+	d.Observe(stats(3))
+	assert.Equal(t, `gen {"gen":3,"elapsed":3,"best":3,"mean":0,"stddev":0,"worst":0,"cand":"CAND"}`, next(t, events))
+	d.Finish(stats(3))
+	assert.Equal(t, `state "done"`, next(t, events))
+}
+
+func post(t *testing.T, url string) {
+	resp, err := http.Post(url, "", nil)
+	require.NoError(t, err)
+	ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+}
+
+func TestDashboardPauseResume(t *testing.T) {
+	d, err := New()
+	require.NoError(t, err)
+	srv := httptest.NewServer(d)
+	defer srv.Close()
+
+	post(t, srv.URL+"/pause")
+	done := make(chan struct{})
+	go func() {
+		d.Observe(stats(0))
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		t.Fatal("Observe should block while paused")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	post(t, srv.URL+"/resume")
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Observe should return once resumed")
+	}
+}
+
+func TestDashboardAbort(t *testing.T) {
+	d, err := New()
+	require.NoError(t, err)
+	srv := httptest.NewServer(d)
+	defer srv.Close()
+
+	d.Pause()
+	done := make(chan struct{})
+	go func() {
+		d.Observe(stats(0))
+		close(done)
+	}()
+
+	assert.False(t, d.Condition().IsSatisfied(nil))
+	post(t, srv.URL+"/abort")
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Observe should return once aborted")
+	}
+	assert.True(t, d.Condition().IsSatisfied(nil))
+
+	events, stop := readEvents(t, srv.URL)
+	defer stop()
+	next(t, events)
+	d.Finish(stats(0))
+	assert.Equal(t, `state "aborted"`, next(t, events))
+}
+
+func TestDashboardSlowClient(t *testing.T) {
+	d, err := New()
+	require.NoError(t, err)
+
+	// A client that never reads its events must not block Observe.
+	c := make(chan []byte)
+	d.clients[c] = struct{}{}
+	done := make(chan struct{})
+	go func() {
+		for i := 0; i < 1000; i++ {
+			d.Observe(stats(i))
+		}
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Observe blocked on a slow client")
+	}
+}
@@ -0,0 +1,143 @@
+"use strict";
+
+var history = [];
+var maxHistory = 5000;
+var series = [
+  { key: "best", color: "#2a7" },
+  { key: "mean", color: "#27c" },
+  { key: "worst", color: "#c44" },
+];
+
+function $(id) { return document.getElementById(id); }
+
+function fmt(v) { return v === null || v === undefined ? "-" : +v.toPrecision(6); }
+
+function setState(state) {
+  var el = $("state");
+  el.textContent = state;
+  el.className = "state " + state;
+  var ended = state === "done" || state === "aborted";
+  $("pause").disabled = ended || state === "paused";
+  $("resume").disabled = state !== "paused";
+  $("abort").disabled = ended;
+}
+
+function update(p) {
+  $("gen").textContent = p.gen;
+  $("elapsed").textContent = p.elapsed.toFixed(1) + "s";
+  $("best").textContent = fmt(p.best);
+  $("mean").textContent = fmt(p.mean);
+  $("stddev").textContent = fmt(p.stddev);
+  $("cand").textContent = p.cand || "";
+}
+
+function draw() {
+  var canvas = $("chart");
+  var ctx = canvas.getContext("2d");
+  var w = canvas.width, h = canvas.height, pad = 40;
+  ctx.clearRect(0, 0, w, h);
+  if (history.length === 0) {
+    return;
+  }
+
+  var min = Infinity, max = -Infinity;
+  history.forEach(function (p) {
+    series.forEach(function (s) {
+      var v = p[s.key];
+      if (v !== null) {
+        min = Math.min(min, v);
+        max = Math.max(max, v);
+      }
+    });
+  });
+  if (min === max) {
+    min -= 1;
+    max += 1;
+  }
+  var g0 = history[0].gen, g1 = Math.max(history[history.length - 1].gen, g0 + 1);
+  function x(gen) { return pad + (gen - g0) / (g1 - g0) * (w - 2 * pad); }
+  function y(v) { return h - pad - (v - min) / (max - min) * (h - 2 * pad); }
+
+  ctx.strokeStyle = "#999";
+  ctx.fillStyle = "#666";
+  ctx.font = "11px sans-serif";
+  ctx.beginPath();
+  ctx.moveTo(pad, pad);
+  ctx.lineTo(pad, h - pad);
+  ctx.lineTo(w - pad, h - pad);
+  ctx.stroke();
+  ctx.fillText(fmt(max), 2, pad);
+  ctx.fillText(fmt(min), 2, h - pad);
+  ctx.fillText(g0, pad, h - pad + 15);
+  ctx.fillText(g1, w - pad - 20, h - pad + 15);
+
+  series.forEach(function (s) {
+    ctx.strokeStyle = s.color;
+    ctx.beginPath();
+    var started = false;
+    history.forEach(function (p) {
+      var v = p[s.key];
+      if (v === null) {
+        return;
+      }
+      if (started) {
+        ctx.lineTo(x(p.gen), y(v));
+      } else {
+        ctx.moveTo(x(p.gen), y(v));
+        started = true;
+      }
+    });
+    ctx.stroke();
+  });
+}
+
+var pending = false;
+function redraw() {
+  if (!pending) {
+    pending = true;
+    window.requestAnimationFrame(function () {
+      pending = false;
+      draw();
+    });
+  }
+}
+
+function post(action) {
+  fetch(action, { method: "POST" });
+}
+
+["pause", "resume", "abort"].forEach(function (action) {
+  $(action).addEventListener("click", function () { post(action); });
+});
+
+var events = new EventSource("events");
+events.addEventListener("init", function (e) {
+  var init = JSON.parse(e.data);
+  document.title = init.title;
+  $("title").textContent = init.title;
+  history = init.history || [];
+  maxHistory = init.max;
+  setState(init.state);
+  if (history.length > 0) {
+    update(history[history.length - 1]);
+  }
+  redraw();
+});
+events.addEventListener("gen", function (e) {
+  var p = JSON.parse(e.data);
+  if (p.gen === 0) {
+    history = [];
+  }
+  history.push(p);
+  if (history.length > maxHistory) {
+    history.shift();
+  }
+  update(p);
+  redraw();
+});
+events.addEventListener("state", function (e) {
+  setState(JSON.parse(e.data));
+});
+events.onerror = function () {
+  setState("disconnected");
+};
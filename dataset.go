@@ -13,6 +13,8 @@ type Dataset struct {
 	product  float64
 	recipsum float64 // reciprocal sum
 	min, max float64
+
+	quantiles []*p2 // streaming quantile estimators
 }
 
 // NewDataset creates an empty data set with the provided initial capacity.
@@ -22,7 +24,7 @@ type Dataset struct {
 func NewDataset(capacity int) *Dataset {
 	return &Dataset{
 		min:      math.MaxFloat64,
-		max:      math.SmallestNonzeroFloat64,
+		max:      -math.MaxFloat64,
 		product:  1,
 		total:    0,
 		recipsum: 0,
@@ -41,7 +43,7 @@ func (ds *Dataset) AddValue(value float64) {
 // are calculated cumulatively.
 func (ds *Dataset) AddValues(values ...float64) {
 	ds.values = append(ds.values, values...)
-	for _, value := range ds.values {
+	for _, value := range values {
 		ds.update(value)
 	}
 }
@@ -50,11 +52,14 @@ func (ds *Dataset) AddValues(values ...float64) {
 // dataset capacity remains unchanged.
 func (ds *Dataset) Clear() {
 	ds.min = math.MaxFloat64
-	ds.max = math.SmallestNonzeroFloat64
+	ds.max = -math.MaxFloat64
 	ds.product = 1
 	ds.total = 0
 	ds.recipsum = 0
 	ds.values = ds.values[:0]
+	for _, q := range ds.quantiles {
+		q.reset()
+	}
 }
 
 // update the dataset by considering the new value that has been added
@@ -64,6 +69,9 @@ func (ds *Dataset) update(value float64) {
 	ds.recipsum += 1 / value
 	ds.min = math.Min(ds.min, value)
 	ds.max = math.Max(ds.max, value)
+	for _, q := range ds.quantiles {
+		q.add(value)
+	}
 }
 
 func (ds *Dataset) mustNotEmpty() {
@@ -114,6 +122,83 @@ func (ds *Dataset) Median() float64 {
 	return cpy[middle-1] + (cpy[middle]-cpy[middle-1])/2
 }
 
+// TrackQuantiles enables the streaming estimation of the quantiles of
+// probabilities ps, that must be in [0, 1].
+//
+// Tracked quantiles are estimated with the P² algorithm, which updates the
+// estimation of each quantile in constant time and memory as values are added,
+// rather than sorting all the values. Estimates are exact as long as the data
+// set has less than 5 values. Only the values added after the call to
+// TrackQuantiles are taken into account, it should thus be called on an empty
+// data set.
+func (ds *Dataset) TrackQuantiles(ps ...float64) {
+	for _, p := range ps {
+		if p < 0 || p > 1 {
+			panic("Dataset: quantile probability out of [0, 1]")
+		}
+		ds.quantiles = append(ds.quantiles, newP2(p))
+	}
+}
+
+// Quantile returns the quantile of probability p, in [0, 1], of the data set.
+//
+// If p is tracked (see TrackQuantiles), Quantile returns its streaming
+// estimate. Otherwise the exact quantile is computed, by sorting a copy of the
+// values and interpolating linearly between the closest ranks.
+//
+// panics if the data set is empty.
+func (ds *Dataset) Quantile(p float64) float64 {
+	ds.mustNotEmpty()
+	for _, q := range ds.quantiles {
+		if q.p == p {
+			return q.value()
+		}
+	}
+	cpy := make([]float64, len(ds.values))
+	copy(cpy, ds.values)
+	sort.Float64s(cpy)
+	return quantile(cpy, p)
+}
+
+// Histogram is a fixed-bin histogram.
+type Histogram struct {
+	// Min and Max are the bounds of the histogram range. Bins have all the
+	// same width, (Max-Min)/len(Counts).
+	Min, Max float64
+
+	// Counts holds the number of values in each bin. A value v falls in the
+	// bin of index floor((v-Min)/width), except Max that falls in the last bin.
+	Counts []int
+}
+
+// Histogram returns the histogram of the data set values, with nbins bins
+// covering the range [Min(), Max()]. If all values are equal, they all fall in
+// the first bin.
+//
+// The histogram is computed in a single pass over the values, without sorting
+// them.
+//
+// panics if the data set is empty.
+func (ds *Dataset) Histogram(nbins int) Histogram {
+	ds.mustNotEmpty()
+	if nbins <= 0 {
+		panic("Dataset: number of histogram bins must be strictly positive")
+	}
+	h := Histogram{Min: ds.min, Max: ds.max, Counts: make([]int, nbins)}
+	width := (ds.max - ds.min) / float64(nbins)
+	for _, v := range ds.values {
+		var i int
+		if width > 0 {
+			i = int((v - ds.min) / width)
+		}
+		if i >= nbins {
+			i = nbins - 1
+		}
+		h.Counts[i]++
+	}
+	return h
+}
+
 // Sum returns the sum of all values.
 //
 // panics if the data set is empty.
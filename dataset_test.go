@@ -2,6 +2,7 @@ package evolve
 
 import (
 	"math"
+	"math/rand"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -134,6 +135,69 @@ func TestDataSetEmptyDataSet(t *testing.T) {
 
 // round rounds floats into integer numbers.
 // FIXME: Remove this function when math.Round will exist (in Go 1.10)
+func TestDataSetAddValuesNonEmpty(t *testing.T) {
+	data := NewDataset(0)
+	data.AddValue(10)
+	data.AddValues(1, 2)
+	assert.Equal(t, 13.0, data.Sum())
+	assert.Equal(t, 20.0, data.Product())
+}
+
+func TestDataSetMaximumNegative(t *testing.T) {
+	data := NewDataset(0)
+	data.AddValues(-3, -1, -2)
+	assert.Equal(t, -1.0, data.Max())
+	data.Clear()
+	data.AddValue(-5)
+	assert.Equal(t, -5.0, data.Max())
+}
+
+func TestDataSetQuantile(t *testing.T) {
+	data := NewDataset(len(testDataSet))
+	data.AddValues(testDataSet...)
+	assert.Equal(t, 1.0, data.Quantile(0))
+	assert.Equal(t, 2.0, data.Quantile(0.25))
+	assert.Equal(t, 3.0, data.Quantile(0.5))
+	assert.Equal(t, 4.5, data.Quantile(0.875))
+	assert.Equal(t, 5.0, data.Quantile(1))
+}
+
+func TestDataSetTrackQuantiles(t *testing.T) {
+	rng := rand.New(rand.NewSource(99))
+	data := NewDataset(100000)
+	data.TrackQuantiles(0.1, 0.5, 0.9)
+
+	// Exact for small data sets.
+	data.AddValues(3, 1, 2)
+	assert.Equal(t, 2.0, data.Quantile(0.5))
+	assert.Equal(t, 1.2, data.Quantile(0.1))
+
+	for _, n := range []int{1000, 100000} {
+		data.Clear()
+		for i := 0; i < n; i++ {
+			data.AddValue(rng.Float64() * 100)
+		}
+		for _, p := range []float64{0.1, 0.5, 0.9} {
+			// Compare to the exact quantile, that is computed for untracked
+			// probabilities.
+			exact := data.Quantile(p + 1e-12)
+			assert.InDeltaf(t, exact, data.Quantile(p), 1, "n=%d p=%v", n, p)
+		}
+	}
+}
+
+func TestDataSetHistogram(t *testing.T) {
+	data := NewDataset(0)
+	data.AddValues(0, 1, 2, 2.5, 3, 10)
+	h := data.Histogram(5)
+	assert.Equal(t, Histogram{Min: 0, Max: 10, Counts: []int{2, 3, 0, 0, 1}}, h)
+	assert.Equal(t, []int{5, 1}, data.Histogram(2).Counts)
+
+	data.Clear()
+	data.AddValues(4, 4, 4)
+	assert.Equal(t, []int{3, 0, 0}, data.Histogram(3).Counts)
+}
+
 func round(a float64) int {
 	if a < 0 {
 		return int(a - 0.5)
@@ -0,0 +1,16 @@
+package evolve
+
+import "fmt"
+
+// Diversity is the interface that wraps the Diversity method.
+//
+// Diversity measures the genotypic diversity of a population, which usually
+// decreases as the population converges. The String method returns the name
+// of the measure, under which its value is reported in PopulationStats.
+type Diversity interface {
+	fmt.Stringer
+
+	// Diversity returns the measure of the diversity of pop. It is called
+	// once per generation, with the sorted population.
+	Diversity(pop Population) float64
+}
@@ -0,0 +1,75 @@
+package diversity
+
+import (
+	"github.com/arl/evolve"
+	"github.com/arl/evolve/pkg/bitstring"
+)
+
+// onesPerLocus returns, for each locus, the number of bit strings of pop having
+// that bit set. All candidates must be *bitstring.Bitstring of the same length.
+func onesPerLocus(pop evolve.Population) []int {
+	if len(pop) == 0 {
+		return nil
+	}
+	length := pop[0].Candidate.(*bitstring.Bitstring).Len()
+	ones := make([]int, length)
+	for _, ind := range pop {
+		bs := ind.Candidate.(*bitstring.Bitstring)
+		if bs.Len() != length {
+			panic("diversity: bit strings of different lengths")
+		}
+		for i := range ones {
+			if bs.Bit(uint(i)) {
+				ones[i]++
+			}
+		}
+	}
+	return ones
+}
+
+// Hamming is the mean pairwise Hamming distance between the *bitstring.Bitstring
+// candidates of a population, i.e the average number of bits differing between
+// two distinct individuals.
+//
+// It is computed exactly, in linear time, from the number of set bits at each
+// locus.
+var Hamming evolve.Diversity = hamming{}
+
+type hamming struct{}
+
+func (hamming) Diversity(pop evolve.Population) float64 {
+	if len(pop) < 2 {
+		return 0
+	}
+	n := len(pop)
+	var sum float64
+	for _, ones := range onesPerLocus(pop) {
+		// Number of pairs differing at that locus.
+		sum += float64(ones * (n - ones))
+	}
+	return sum / float64(n*(n-1)/2)
+}
+
+func (hamming) String() string { return "hamming" }
+
+// LocusEntropy is the mean, over all loci, of the entropy of the bits of the
+// *bitstring.Bitstring candidates of a population. It ranges from 0, when all
+// individuals are identical, to 1, when each bit is set in exactly half of the
+// population.
+var LocusEntropy evolve.Diversity = locusEntropy{}
+
+type locusEntropy struct{}
+
+func (locusEntropy) Diversity(pop evolve.Population) float64 {
+	ones := onesPerLocus(pop)
+	if len(ones) == 0 {
+		return 0
+	}
+	var sum float64
+	for _, c := range ones {
+		sum += binaryEntropy(float64(c) / float64(len(pop)))
+	}
+	return sum / float64(len(ones))
+}
+
+func (locusEntropy) String() string { return "locus-entropy" }
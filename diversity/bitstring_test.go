@@ -0,0 +1,36 @@
+package diversity
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/arl/evolve"
+	"github.com/arl/evolve/pkg/bitstring"
+)
+
+func bitstrings(t *testing.T, strs ...string) evolve.Population {
+	var pop evolve.Population
+	for _, s := range strs {
+		bs, err := bitstring.MakeFromString(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		pop = append(pop, &evolve.Individual{Candidate: bs})
+	}
+	return pop
+}
+
+func TestHamming(t *testing.T) {
+	assert.Equal(t, 0.0, Hamming.Diversity(bitstrings(t, "1010", "1010", "1010")))
+	assert.Equal(t, 4.0, Hamming.Diversity(bitstrings(t, "1010", "0101")))
+	// Pairwise distances: 1, 2, 3
+	assert.Equal(t, 2.0, Hamming.Diversity(bitstrings(t, "0000", "0001", "0111")))
+	assert.Equal(t, 0.0, Hamming.Diversity(bitstrings(t, "0000")))
+}
+
+func TestLocusEntropy(t *testing.T) {
+	assert.Equal(t, 0.0, LocusEntropy.Diversity(bitstrings(t, "1010", "1010")))
+	assert.Equal(t, 1.0, LocusEntropy.Diversity(bitstrings(t, "1010", "0101")))
+	assert.Equal(t, 0.5, LocusEntropy.Diversity(bitstrings(t, "1100", "1111")))
+}
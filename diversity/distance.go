@@ -0,0 +1,65 @@
+package diversity
+
+import (
+	"math/rand"
+
+	"github.com/arl/evolve"
+)
+
+// Distance is a generic diversity measure: the mean distance between two
+// distinct individuals of the population, for a user-provided distance
+// function.
+//
+// Computing all the pairwise distances has a quadratic cost, for large
+// populations Samples limits the number of pairs on which the mean distance is
+// estimated.
+type Distance struct {
+	// Name is the name of the measure.
+	Name string
+
+	// Dist returns the distance between 2 candidates.
+	Dist func(a, b interface{}) float64
+
+	// Samples is the maximum number of pairs of individuals whose distance is
+	// computed. If the population has more pairs, the mean distance is
+	// estimated on Samples pairs drawn at random. If Samples is 0, all pairs
+	// are considered.
+	Samples int
+
+	// Seed seeds the source of randomness used to draw the pairs. Every
+	// generation, pairs are drawn from a new source seeded with Seed, so
+	// that the measure does not consume the engine source of randomness.
+	Seed int64
+}
+
+// Diversity implements evolve.Diversity.
+func (d Distance) Diversity(pop evolve.Population) float64 {
+	n := len(pop)
+	if n < 2 {
+		return 0
+	}
+
+	var sum float64
+	npairs := n * (n - 1) / 2
+	if d.Samples <= 0 || npairs <= d.Samples {
+		for i := 0; i < n; i++ {
+			for j := i + 1; j < n; j++ {
+				sum += d.Dist(pop[i].Candidate, pop[j].Candidate)
+			}
+		}
+		return sum / float64(npairs)
+	}
+
+	rng := rand.New(rand.NewSource(d.Seed))
+	for k := 0; k < d.Samples; k++ {
+		i := rng.Intn(n)
+		j := rng.Intn(n - 1)
+		if j >= i {
+			j++
+		}
+		sum += d.Dist(pop[i].Candidate, pop[j].Candidate)
+	}
+	return sum / float64(d.Samples)
+}
+
+func (d Distance) String() string { return d.Name }
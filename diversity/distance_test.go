@@ -0,0 +1,36 @@
+package diversity
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDistance(t *testing.T) {
+	d := Distance{
+		Name: "abs",
+		Dist: func(a, b interface{}) float64 { return math.Abs(a.(float64) - b.(float64)) },
+	}
+	assert.Equal(t, "abs", d.String())
+
+	// Pairwise distances: 1, 3, 2
+	pop := population(0.0, 1.0, 3.0)
+	assert.Equal(t, 2.0, d.Diversity(pop))
+
+	// Sampled estimate on a larger population, of which the exact mean
+	// pairwise distance is 1/3 of the range.
+	var cands []interface{}
+	for i := 0; i <= 1000; i++ {
+		cands = append(cands, float64(i))
+	}
+	pop = population(cands...)
+	exact := d.Diversity(pop)
+	assert.InDelta(t, 1002.0/3, exact, 1e-9)
+
+	d.Samples = 2000
+	d.Seed = 99
+	est := d.Diversity(pop)
+	assert.InDelta(t, exact, est, exact*0.05)
+	assert.Equal(t, est, d.Diversity(pop), "sampling should be repeatable")
+}
@@ -0,0 +1,29 @@
+// Package diversity provides measures of the genotypic diversity of a
+// population, to be set on the engine with engine.MeasureDiversity.
+//
+// Measures are specific to a candidate type, they panic if the population
+// holds candidates of another type.
+package diversity
+
+import "math"
+
+// entropy returns the Shannon entropy, in bits, of the distribution of
+// symbols whose occurrences are counted in counts, n being the total number of
+// occurrences.
+func entropy(counts map[interface{}]int, n int) float64 {
+	var h float64
+	for _, c := range counts {
+		p := float64(c) / float64(n)
+		h -= p * math.Log2(p)
+	}
+	return h
+}
+
+// binaryEntropy returns the entropy, in bits, of a binary symbol having
+// probability p.
+func binaryEntropy(p float64) float64 {
+	if p == 0 || p == 1 {
+		return 0
+	}
+	return -p*math.Log2(p) - (1-p)*math.Log2(1-p)
+}
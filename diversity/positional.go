@@ -0,0 +1,56 @@
+package diversity
+
+import (
+	"fmt"
+
+	"github.com/arl/evolve"
+)
+
+// PositionalEntropy is the mean, over all positions, of the entropy, in bits,
+// of the symbols found at that position in the candidates of a population.
+//
+// Candidates must be []int, such as permutations, or strings, in which case
+// symbols are runes. When candidates have different lengths, the entropy of
+// each position is computed over the candidates that are long enough.
+var PositionalEntropy evolve.Diversity = positionalEntropy{}
+
+type positionalEntropy struct{}
+
+func (positionalEntropy) Diversity(pop evolve.Population) float64 {
+	var counts []map[interface{}]int
+	var totals []int
+	add := func(pos int, sym interface{}) {
+		for pos >= len(counts) {
+			counts = append(counts, make(map[interface{}]int))
+			totals = append(totals, 0)
+		}
+		counts[pos][sym]++
+		totals[pos]++
+	}
+
+	for _, ind := range pop {
+		switch cand := ind.Candidate.(type) {
+		case []int:
+			for i, v := range cand {
+				add(i, v)
+			}
+		case string:
+			for i, r := range []rune(cand) {
+				add(i, r)
+			}
+		default:
+			panic(fmt.Sprintf("diversity: positional entropy of unsupported type %T", cand))
+		}
+	}
+
+	if len(counts) == 0 {
+		return 0
+	}
+	var sum float64
+	for i := range counts {
+		sum += entropy(counts[i], totals[i])
+	}
+	return sum / float64(len(counts))
+}
+
+func (positionalEntropy) String() string { return "positional-entropy" }
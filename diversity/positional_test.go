@@ -0,0 +1,27 @@
+package diversity
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/arl/evolve"
+)
+
+func population(cands ...interface{}) evolve.Population {
+	var pop evolve.Population
+	for _, c := range cands {
+		pop = append(pop, &evolve.Individual{Candidate: c})
+	}
+	return pop
+}
+
+func TestPositionalEntropy(t *testing.T) {
+	assert.Equal(t, 0.0, PositionalEntropy.Diversity(population([]int{0, 1, 2}, []int{0, 1, 2})))
+	assert.Equal(t, 1.0, PositionalEntropy.Diversity(population([]int{0, 1}, []int{1, 0})))
+	assert.Equal(t, 2.0, PositionalEntropy.Diversity(population("abcd", "bcda", "cdab", "dabc")))
+	// First position has entropy 1, second is 0.
+	assert.Equal(t, 0.5, PositionalEntropy.Diversity(population("ab", "bb", "a", "b")))
+
+	assert.Panics(t, func() { PositionalEntropy.Diversity(population(1.0)) })
+}
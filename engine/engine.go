@@ -4,10 +4,11 @@ import (
 	"errors"
 	"fmt"
 	"math/rand"
-	"sort"
 	"time"
 
 	"github.com/arl/evolve"
+	"github.com/arl/evolve/condition"
+	"github.com/arl/evolve/lineage"
 	"github.com/arl/evolve/pkg/mt19937"
 )
 
@@ -24,6 +25,26 @@ type Engine struct {
 	seeds   []interface{}
 	conds   []evolve.Condition
 	size    int
+	cmp     evolve.Comparator
+	divs    []evolve.Diversity
+	qs      []float64
+	nbins   int
+	lineage *lineage.Genealogy
+	lingens int // generations kept in the genealogy, 0 for no limit
+	ngen    int // current generation
+	evalgen int // generation being evaluated
+
+	telemetry bool
+	cur       generation // telemetry of the current generation
+
+	budget int64 // maximum number of evaluations, 0 for no limit
+	evals  int64 // evaluations since the evolution start
+	hits   int64 // cache hits since the evolution start
+
+	bestEver    *evolve.Individual
+	bestEverGen int
+
+	deterministic bool
 }
 
 // New creates an evolution engine.
@@ -69,6 +90,131 @@ func Rand(rng *rand.Rand) func(*Engine) error {
 	}
 }
 
+// Deterministic enables the deterministic mode of the engine.
+//
+// By default fitness evaluations are performed concurrently, so that evaluators
+// holding some state may observe candidates in any order. In deterministic
+// mode, candidates are evaluated one after the other, in population order, on
+// the goroutine running Evolve. This applies to the initial population as well
+// as to the populations evaluated by the Generational epocher.
+//
+// Together with a seeded source of randomness (see Rand), deterministic mode
+// guarantees that two runs of the same configuration produce the exact same
+// sequence of populations, provided every component of the configuration only
+// draws random numbers from the engine source of randomness (or from sources
+// that are themselves deterministically seeded).
+func Deterministic() func(*Engine) error {
+	return func(eng *Engine) error {
+		eng.deterministic = true
+		return nil
+	}
+}
+
+// Compare sets the comparator defining the order of the individuals, from the
+// fittest to the weakest. By default, individuals are ordered by their fitness
+// score, according to the evaluator IsNatural method (see evolve.ByFitness).
+//
+// A comparator is required to take into account all the scores of the
+// individuals evaluated by an evolve.VectorEvaluator. If cmp is an
+// evolve.CheckedComparator, Evolve fails if it can't compare the individuals
+// of the initial population.
+//
+// Only the selection strategies picking individuals by their position in the
+// sorted population, such as selection.Tournament, selection.Truncation and
+// selection.RankBased, follow the comparator. Fitness-proportionate
+// strategies, such as selection.RouletteWheel, selection.SigmaScaling and
+// selection.StochasticUniversalSampling, only see the fitness of the
+// individuals.
+func Compare(cmp evolve.Comparator) func(*Engine) error {
+	return func(eng *Engine) error {
+		if cmp == nil {
+			return errors.New("nil comparator")
+		}
+		eng.cmp = cmp
+		return nil
+	}
+}
+
+// MeasureDiversity adds diversity measures, computed every generation and
+// reported, by name, in the Diversity field of the population stats given to
+// observers and termination conditions.
+func MeasureDiversity(measures ...evolve.Diversity) func(*Engine) error {
+	return func(eng *Engine) error {
+		eng.divs = append(eng.divs, measures...)
+		return nil
+	}
+}
+
+// Quantiles sets the probabilities, in [0, 1], of the fitness quantiles
+// reported in the population stats. Quantiles are estimated in a streaming
+// fashion, in constant time per candidate, when the population is not ordered
+// by fitness (see Compare).
+func Quantiles(ps ...float64) func(*Engine) error {
+	return func(eng *Engine) error {
+		for _, p := range ps {
+			if p < 0 || p > 1 {
+				return fmt.Errorf("invalid quantile probability %v", p)
+			}
+		}
+		eng.qs = ps
+		return nil
+	}
+}
+
+// HistogramBins sets the number of bins of the fitness histogram reported in
+// the population stats. By default, or if n is 0, no histogram is computed.
+func HistogramBins(n int) func(*Engine) error {
+	return func(eng *Engine) error {
+		if n < 0 {
+			return errors.New("invalid number of histogram bins")
+		}
+		eng.nbins = n
+		return nil
+	}
+}
+
+// Lineage makes the engine track the lineage of the individuals, which is
+// recorded in g. Every individual is then given an Origin, with a unique ID,
+// the IDs of its parents, the operators that produced it and its birth
+// generation.
+//
+// Individuals are traced through the operators of the Generational epocher,
+// see evolve.ApplyTrace. Since operators deal with candidates, selected
+// candidates are matched to the individuals of the population by identity, so
+// the parents of an offspring may be mistaken for other individuals having
+// the same content, if candidates are values rather than pointers or slices.
+//
+// Every generation, the records of the individuals that left no descendant in
+// the population are pruned (see lineage.Genealogy.Prune), so that the whole
+// ancestry of the living individuals is kept. If gens is strictly positive,
+// the genealogy is further bounded to the records of the individuals born in
+// the last gens generations, and those of the individuals of the population
+// (see lineage.Genealogy.Trim), at the cost of cutting their ancestry.
+func Lineage(g *lineage.Genealogy, gens int) func(*Engine) error {
+	return func(eng *Engine) error {
+		if g == nil {
+			return errors.New("nil genealogy")
+		}
+		eng.lineage, eng.lingens = g, gens
+		return nil
+	}
+}
+
+// Telemetry makes the engine report the activity of the genetic operators
+// in the population stats: how many offspring each operator produced and
+// changed, the time spent in each of them, and how many offspring are fitter
+// than their parents.
+//
+// Offspring are traced through the operators of the Generational epocher, see
+// evolve.ApplyInstrumented. The time spent in each phase of the generation is
+// always reported, regardless of this option.
+func Telemetry() func(*Engine) error {
+	return func(eng *Engine) error {
+		eng.telemetry = true
+		return nil
+	}
+}
+
 // Observe adds an observer of the evolution process.
 func Observe(o Observer) func(*Engine) error {
 	return func(eng *Engine) error {
@@ -107,7 +253,9 @@ func Seeds(seeds []interface{}) func(*Engine) error {
 }
 
 // EndOn adds a termination condition to the engine. The engine stops
-// after one or more condition is met.
+// after one or more condition is met, that is multiple conditions are
+// combined with a logical OR. More complex rules can be expressed with the
+// combinators of the condition package (see condition.And).
 func EndOn(cond evolve.Condition) func(*Engine) error {
 	return func(eng *Engine) error {
 		eng.conds = append(eng.conds, cond)
@@ -124,7 +272,13 @@ func EndOn(cond evolve.Condition) func(*Engine) error {
 // with Seeds. size must be at least 1 or Evolve will return en error.
 //
 // At least one termination condition must be defined with EndOn, or Evolve will
-// return an error.
+// return an error. Conditions implementing evolve.Resetter are reset before the
+// evolution starts.
+//
+// The smallest evaluation budget of the termination conditions, including the
+// conditions nested in combinators of the condition package, is honoured
+// exactly (see evolve.BudgetCondition). This requires the epocher to be a
+// Binder.
 func (e *Engine) Evolve(popsize int, options ...func(*Engine) error) (evolve.Population, []evolve.Condition, error) {
 	e.size = popsize
 	for _, opt := range options {
@@ -140,8 +294,41 @@ func (e *Engine) Evolve(popsize int, options ...func(*Engine) error) (evolve.Pop
 		return nil, nil, errors.New("no termination condition specified")
 	}
 
+	// Reset stateful conditions, they may have been used in a previous run.
+	for _, cond := range e.conds {
+		if r, ok := cond.(evolve.Resetter); ok {
+			r.ResetCondition()
+		}
+	}
+
+	// The smallest evaluation budget of the conditions is honoured.
+	e.budget, e.evals, e.hits = 0, 0, 0
+	for _, cond := range e.conds {
+		max, err := budget(cond)
+		if err != nil {
+			return nil, nil, err
+		}
+		if max > 0 && (e.budget == 0 || max < e.budget) {
+			e.budget = max
+		}
+	}
+	b, ok := e.epoch.(Binder)
+	if ok {
+		b.Bind(e)
+	} else if e.budget > 0 {
+		return nil, nil, fmt.Errorf("can't honour the evaluation budget, %T doesn't evaluate through the engine (see Binder)", e.epoch)
+	}
+
 	// create the dataset
 	e.stats = evolve.NewDataset(popsize)
+	e.bestEver = nil
+
+	cmp := e.comparator()
+	if e.cmp != nil {
+		// The population is not sorted by fitness, quantiles are estimated.
+		e.stats.TrackQuantiles(0.5)
+		e.stats.TrackQuantiles(e.qs...)
+	}
 
 	var ngen int
 	start := time.Now()
@@ -154,14 +341,40 @@ func (e *Engine) Evolve(popsize int, options ...func(*Engine) error) (evolve.Pop
 	var satisfied []evolve.Condition
 
 	// Evaluate initial population fitness
-	evpop := evolve.EvaluatePopulation(pop, e.eval, true)
+	e.cur = generation{}
+	e.evalgen = 0
+	evalStart := time.Now()
+	evpop := e.Evaluate(pop, e.eval)
+	e.cur.timings.Evaluation = time.Since(evalStart)
+	if e.lineage != nil {
+		for _, ind := range evpop {
+			e.lineage.Add(ind, nil, "", 0)
+		}
+	}
+
+	if cc, ok := cmp.(evolve.CheckedComparator); ok && len(evpop) > 0 {
+		nscores := 1
+		if evpop[0].Scores != nil {
+			nscores = len(evpop[0].Scores)
+		}
+		if err := cc.Check(nscores); err != nil {
+			return nil, nil, err
+		}
+	}
 
 	for {
-		// Sort population according to fitness.
-		if e.eval.IsNatural() {
-			sort.Sort(sort.Reverse(evpop))
-		} else {
-			sort.Sort(evpop)
+		// Sort population, fittest first.
+		sortStart := time.Now()
+		evolve.SortPopulation(evpop, cmp)
+		e.cur.timings.Sorting = time.Since(sortStart)
+
+		if e.lineage != nil {
+			// Individuals have no origin if the epocher doesn't trace them,
+			// Prune then fails and leaves the genealogy untouched.
+			e.lineage.Prune(evpop)
+			if e.lingens > 0 {
+				e.lineage.Trim(evpop, ngen-e.lingens+1)
+			}
 		}
 
 		// compute population stats
@@ -170,10 +383,14 @@ func (e *Engine) Evolve(popsize int, options ...func(*Engine) error) (evolve.Pop
 		// check for termination conditions
 		satisfied = shouldContinue(data, e.conds...)
 		if satisfied != nil {
+			e.finish(data)
 			break
 		}
 
 		// perform evolution
+		e.ngen = ngen
+		e.evalgen = ngen + 1
+		e.cur = generation{}
 		evpop = e.epoch.Epoch(evpop, e.nelites, e.rng)
 
 		ngen++
@@ -181,23 +398,191 @@ func (e *Engine) Evolve(popsize int, options ...func(*Engine) error) (evolve.Pop
 	return evpop, satisfied, nil
 }
 
+// finish notifies the finishers that the evolution ends.
+func (e *Engine) finish(stats *evolve.PopulationStats) {
+	for o := range e.obs {
+		if f, ok := o.(Finisher); ok {
+			f.Finish(stats)
+		}
+	}
+}
+
+// Evaluate evaluates the candidates of pop with eval, concurrently unless the
+// engine runs in deterministic mode, and counts the evaluations reported in
+// the population stats. It's meant to be called by epochers implementing
+// Binder, to evaluate the populations they produce.
+//
+// If eval is an evolve.GenerationalEvaluator, it's notified of the generation
+// being evaluated.
+//
+// If the engine has an evaluation budget (see evolve.BudgetCondition), the
+// candidates are evaluated in order until the budget is exhausted: the
+// returned population then only holds the individuals of the first evaluated
+// candidates, and is empty once the budget has been exhausted.
+func (e *Engine) Evaluate(pop []interface{}, eval evolve.Evaluator) evolve.Population {
+	if ge, ok := eval.(evolve.GenerationalEvaluator); ok {
+		ge.StartGeneration(e.evalgen)
+	}
+	if e.budget == 0 {
+		return e.evaluateRange(pop, 0, len(pop), eval)
+	}
+
+	// Evaluate successive ranges no larger than the remaining budget, since
+	// cache hits don't consume it.
+	evpop := make(evolve.Population, 0, len(pop))
+	for len(evpop) < len(pop) && e.evals < e.budget {
+		from := len(evpop)
+		to := from + int(e.budget-e.evals)
+		if to > len(pop) || to < from {
+			to = len(pop)
+		}
+		evpop = append(evpop, e.evaluateRange(pop, from, to, eval)...)
+	}
+	return evpop
+}
+
+// evaluateRange evaluates pop[from:to] and counts the evaluations.
+func (e *Engine) evaluateRange(pop []interface{}, from, to int, eval evolve.Evaluator) evolve.Population {
+	ce, caching := eval.(evolve.CachingEvaluator)
+	var hits int64
+	if caching {
+		hits = ce.Stats().Hits
+	}
+
+	evpop := evolve.EvaluateRange(pop, from, to, eval, !e.deterministic)
+
+	n := int64(to - from)
+	if caching {
+		hits = ce.Stats().Hits - hits
+		if hits < 0 || hits > n {
+			// The cache has been reset meanwhile.
+			hits = 0
+		}
+		n -= hits
+		e.hits += hits
+	}
+	e.evals += n
+	return evpop
+}
+
+// generation holds the telemetry of a generation, filled by the engine and by
+// its epocher.
+type generation struct {
+	timings  evolve.Timings
+	ops      []evolve.OperatorStats
+	improved int
+}
+
+// A Binder is an epocher bound to the engine running it, through which it
+// evaluates the populations it produces (see Engine.Evaluate).
+//
+// The engine can only count the evaluations of the populations produced by a
+// Binder, and Evolve returns an error if the epocher isn't a Binder while a
+// termination condition sets an evaluation budget.
+type Binder interface {
+	evolve.Epocher
+
+	// Bind is called by the engine at the start of Evolve.
+	Bind(*Engine)
+}
+
+// budget returns the evaluation budget set by cond, that is the number of
+// evaluations after which cond is certainly satisfied, or 0 if cond doesn't
+// set any. The sub-conditions of combinators are taken into account.
+func budget(cond evolve.Condition) (int64, error) {
+	if b, ok := cond.(evolve.BudgetCondition); ok {
+		max := b.MaxEvaluations()
+		if max <= 0 {
+			return 0, fmt.Errorf("invalid evaluation budget %d", max)
+		}
+		return max, nil
+	}
+	c, ok := cond.(*condition.Combinator)
+	if !ok {
+		return 0, nil
+	}
+
+	var min, max int64
+	all := true // all the sub-conditions set a budget
+	for _, sub := range c.Conditions() {
+		b, err := budget(sub)
+		if err != nil {
+			return 0, err
+		}
+		if b == 0 {
+			all = false
+			continue
+		}
+		if min == 0 || b < min {
+			min = b
+		}
+		if b > max {
+			max = b
+		}
+	}
+
+	switch c.Op() {
+	case condition.OpOr:
+		return min, nil
+	case condition.OpAnd:
+		if all {
+			return max, nil
+		}
+	}
+	// NOT and AFTER combinators may not be satisfied when the budget of their
+	// sub-condition is, they don't set any.
+	return 0, nil
+}
+
 func (e *Engine) updateStats(pop evolve.Population, ngen int, elapsed time.Duration) *evolve.PopulationStats {
 	e.stats.Clear()
 	for _, cand := range pop {
 		e.stats.AddValue(cand.Fitness)
 	}
 
+	best := pop[0]
+	if e.bestEver == nil || e.fitter(best, e.bestEver, pop) {
+		e.bestEver, e.bestEverGen = best, ngen
+	}
+
 	// Notify observers with the population state
 	stats := evolve.PopulationStats{
-		BestCand:    pop[0].Candidate,
-		BestFitness: pop[0].Fitness,
-		Mean:        e.stats.ArithmeticMean(),
-		StdDev:      e.stats.StandardDeviation(),
-		Natural:     e.eval.IsNatural(),
-		Size:        e.stats.Len(),
-		NumElites:   e.nelites,
-		GenNumber:   ngen,
-		Elapsed:     elapsed,
+		BestCand:        best.Candidate,
+		BestFitness:     best.Fitness,
+		BestScores:      best.Scores,
+		WorstFitness:    pop[len(pop)-1].Fitness,
+		BestEverCand:    e.bestEver.Candidate,
+		BestEverFitness: e.bestEver.Fitness,
+		BestEverGen:     e.bestEverGen,
+		Median:          e.quantile(pop, 0.5),
+		Mean:            e.stats.ArithmeticMean(),
+		StdDev:          e.stats.StandardDeviation(),
+		Natural:         e.eval.IsNatural(),
+		Size:            e.stats.Len(),
+		NumElites:       e.nelites,
+		GenNumber:       ngen,
+		Elapsed:         elapsed,
+		Evaluations:     e.evals,
+		CacheHits:       e.hits,
+		Timings:         e.cur.timings,
+		Operators:       e.cur.ops,
+		Improved:        e.cur.improved,
+	}
+	for _, p := range e.qs {
+		stats.Quantiles = append(stats.Quantiles, evolve.Quantile{P: p, Value: e.quantile(pop, p)})
+	}
+	if e.nbins > 0 {
+		h := e.stats.Histogram(e.nbins)
+		stats.Histogram = &h
+	}
+	if r, ok := e.eval.(evolve.ReportingEvaluator); ok {
+		stats.EvaluatorStats = r.EvaluatorStats()
+	}
+	if len(e.divs) > 0 {
+		stats.Diversity = make(map[string]float64, len(e.divs))
+		for _, d := range e.divs {
+			stats.Diversity[d.String()] = d.Diversity(pop)
+		}
 	}
 
 	for o := range e.obs {
@@ -206,6 +591,53 @@ func (e *Engine) updateStats(pop evolve.Population, ngen int, elapsed time.Durat
 	return &stats
 }
 
+// comparator returns the comparator ordering the population.
+func (e *Engine) comparator() evolve.Comparator {
+	if e.cmp == nil {
+		return evolve.ByFitness(e.eval.IsNatural())
+	}
+	return e.cmp
+}
+
+// fitter reports whether best, the fittest individual of the sorted population
+// pop, is fitter than ind, an individual of a previous generation. A population
+// comparator compares them within a population made of both, then gets
+// prepared again with pop.
+func (e *Engine) fitter(best, ind *evolve.Individual, pop evolve.Population) bool {
+	cmp := e.comparator()
+	pc, ok := cmp.(evolve.PopulationComparator)
+	if !ok {
+		return cmp.Compare(best, ind) < 0
+	}
+	pc.Prepare(evolve.Population{ind, best})
+	fitter := pc.Compare(best, ind) < 0
+	pc.Prepare(pop)
+	return fitter
+}
+
+// quantile returns the fitness quantile of probability p of the sorted
+// population. If pop is sorted by fitness, the quantile is exact, otherwise it
+// is the streaming estimate computed by the dataset.
+func (e *Engine) quantile(pop evolve.Population, p float64) float64 {
+	if e.cmp != nil {
+		return e.stats.Quantile(p)
+	}
+
+	// i-th fitness in ascending order.
+	fitness := func(i int) float64 {
+		if e.eval.IsNatural() {
+			return pop[len(pop)-1-i].Fitness
+		}
+		return pop[i].Fitness
+	}
+	pos := p * float64(len(pop)-1)
+	lo := int(pos)
+	if lo >= len(pop)-1 {
+		return fitness(len(pop) - 1)
+	}
+	return fitness(lo) + (pos-float64(lo))*(fitness(lo+1)-fitness(lo))
+}
+
 // shouldContinue determines whether or not the evolution should continue.
 func shouldContinue(stats *evolve.PopulationStats, conds ...evolve.Condition) []evolve.Condition {
 	satisfied := make([]evolve.Condition, 0)
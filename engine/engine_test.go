@@ -1,9 +1,18 @@
 package engine
 
 import (
+	"math/rand"
+	"sync"
+	"sync/atomic"
 	"testing"
 
+	"github.com/stretchr/testify/assert"
+
+	"github.com/arl/evolve"
+	"github.com/arl/evolve/comparator"
 	"github.com/arl/evolve/condition"
+	"github.com/arl/evolve/diversity"
+	"github.com/arl/evolve/selection"
 )
 
 func TestEngineArgumentErrors(t *testing.T) {
@@ -41,3 +50,426 @@ func TestEngineArgumentErrors(t *testing.T) {
 		}
 	})
 }
+
+// digitEvaluator scores an int candidate by its last digit, then by its value.
+type digitEvaluator struct{ intEvaluator }
+
+func (digitEvaluator) Scores(cand interface{}, pop []interface{}) []float64 {
+	return []float64{float64(cand.(int) % 10), float64(cand.(int))}
+}
+
+type identityOp struct{}
+
+func (identityOp) Apply(sel []interface{}, rng *rand.Rand) []interface{} { return sel }
+
+func TestEngineCompare(t *testing.T) {
+	factory := evolve.FactoryFunc(func(rng *rand.Rand) interface{} { return rng.Intn(100) })
+	epocher := Generational{Op: identityOp{}, Eval: digitEvaluator{}, Sel: selection.NewTournament()}
+	cmp := comparator.Lexicographic{Natural: []bool{true, false}}
+
+	var best []float64
+	eng, err := New(factory, digitEvaluator{}, &epocher,
+		Rand(rand.New(rand.NewSource(99))),
+		Compare(cmp),
+		Observe(ObserverFunc(func(stats *evolve.PopulationStats) {
+			best = stats.BestScores
+		})))
+	check(t, err)
+
+	pop, _, err := eng.Evolve(50, Elites(2), EndOn(condition.GenerationCount(5)))
+	check(t, err)
+	for i := 1; i < len(pop); i++ {
+		assert.True(t, cmp.Compare(pop[i-1], pop[i]) <= 0, "population not sorted at index %d", i)
+	}
+	assert.Equal(t, pop[0].Scores, best)
+	assert.Equal(t, pop[0].Scores[0], pop[0].Fitness)
+
+	// The direction of the scores is required.
+	eng, err = New(factory, digitEvaluator{}, &epocher, Compare(comparator.Lexicographic{}))
+	check(t, err)
+	_, _, err = eng.Evolve(50, EndOn(condition.GenerationCount(5)))
+	assert.EqualError(t, err, "comparator: Natural specifies 0 scores, want 2")
+}
+
+func TestEngineMeasureDiversity(t *testing.T) {
+	factory := evolve.FactoryFunc(func(rng *rand.Rand) interface{} { return rng.Intn(100) })
+	epocher := Generational{Op: zeroIntMaker{}, Eval: intEvaluator{}, Sel: selection.NewTournament()}
+
+	distinct := diversity.Distance{
+		Name: "distinct",
+		Dist: func(a, b interface{}) float64 {
+			if a == b {
+				return 0
+			}
+			return 1
+		},
+	}
+	var divs []float64
+	eng, err := New(factory, intEvaluator{}, &epocher,
+		Rand(rand.New(rand.NewSource(99))),
+		MeasureDiversity(distinct),
+		Observe(ObserverFunc(func(stats *evolve.PopulationStats) {
+			divs = append(divs, stats.Diversity["distinct"])
+		})))
+	check(t, err)
+
+	_, _, err = eng.Evolve(10, EndOn(condition.GenerationCount(2)))
+	check(t, err)
+
+	// The initial population is diverse, then all candidates are zeroes.
+	assert.Len(t, divs, 2)
+	assert.Greater(t, divs[0], 0.5)
+	assert.Equal(t, 0.0, divs[1])
+}
+
+func TestEngineStats(t *testing.T) {
+	factory := evolve.FactoryFunc(func(rng *rand.Rand) interface{} { return rng.Intn(100) })
+	epocher := Generational{Op: zeroIntMaker{}, Eval: intEvaluator{}, Sel: selection.NewTournament()}
+
+	var stats []evolve.PopulationStats
+	eng, err := New(factory, intEvaluator{}, &epocher,
+		Rand(rand.New(rand.NewSource(99))),
+		Quantiles(0.25, 0.75),
+		HistogramBins(4),
+		Observe(ObserverFunc(func(s *evolve.PopulationStats) {
+			stats = append(stats, *s)
+		})))
+	check(t, err)
+
+	pop, _, err := eng.Evolve(100, Elites(1), EndOn(condition.GenerationCount(3)))
+	check(t, err)
+
+	first, last := stats[0], stats[len(stats)-1]
+	assert.Equal(t, first.BestFitness, first.BestEverFitness)
+	assert.Equal(t, 0, first.BestEverGen)
+	assert.True(t, first.WorstFitness <= first.Quantiles[0].Value)
+	assert.True(t, first.Quantiles[0].Value <= first.Median)
+	assert.True(t, first.Median <= first.Quantiles[1].Value)
+	assert.Equal(t, 0.75, first.Quantiles[1].P)
+	assert.Len(t, first.Histogram.Counts, 4)
+
+	// The elite is the only non-zero candidate.
+	assert.Equal(t, first.BestFitness, last.BestEverFitness)
+	assert.Equal(t, first.BestCand, last.BestEverCand)
+	assert.Equal(t, 0, last.BestEverGen)
+	assert.Equal(t, 0.0, last.WorstFitness)
+	assert.Equal(t, 0.0, last.Median)
+	assert.Equal(t, evolve.Histogram{Min: 0, Max: pop[0].Fitness, Counts: []int{99, 0, 0, 1}}, *last.Histogram)
+
+	_, _, err = eng.Evolve(100, Quantiles(2))
+	assert.Error(t, err)
+}
+
+func TestEngineStatsComparator(t *testing.T) {
+	// The comparators order the individuals by ascending fitness, whereas the
+	// evaluator is natural.
+	cmps := []evolve.Comparator{
+		comparator.Lexicographic{Natural: []bool{false}},
+		&comparator.Pareto{Natural: []bool{false}},
+	}
+	for _, cmp := range cmps {
+		factory := evolve.FactoryFunc(func(rng *rand.Rand) interface{} { return 1 + rng.Intn(100) })
+		epocher := Generational{Op: zeroIntMaker{}, Eval: intEvaluator{}, Sel: selection.NewTournament()}
+
+		var stats []evolve.PopulationStats
+		eng, err := New(factory, intEvaluator{}, &epocher,
+			Rand(rand.New(rand.NewSource(99))),
+			Compare(cmp),
+			Observe(ObserverFunc(func(s *evolve.PopulationStats) {
+				stats = append(stats, *s)
+			})))
+		check(t, err)
+
+		_, _, err = eng.Evolve(100, EndOn(condition.GenerationCount(3)))
+		check(t, err)
+
+		first, last := stats[0], stats[len(stats)-1]
+		assert.Greater(t, first.WorstFitness, first.BestFitness, "%T", cmp)
+		assert.Equal(t, first.BestFitness, first.BestEverFitness, "%T", cmp)
+
+		// The zeroes of the second generation are the fittest.
+		assert.Equal(t, 0.0, last.BestEverFitness, "%T", cmp)
+		assert.Equal(t, 1, last.BestEverGen, "%T", cmp)
+	}
+}
+
+type finisher struct {
+	observed, finished []int
+}
+
+func (f *finisher) Observe(stats *evolve.PopulationStats) {
+	f.observed = append(f.observed, stats.GenNumber)
+}
+func (f *finisher) Finish(stats *evolve.PopulationStats) {
+	f.finished = append(f.finished, stats.GenNumber)
+}
+
+func TestEngineFinisher(t *testing.T) {
+	epocher := Generational{Op: zeroIntMaker{}, Eval: intEvaluator{}, Sel: selection.NewTournament()}
+	f := &finisher{}
+	eng, err := New(zeroFactory, intEvaluator{}, &epocher, Observe(f))
+	check(t, err)
+
+	_, _, err = eng.Evolve(10, EndOn(condition.GenerationCount(3)))
+	check(t, err)
+	assert.Equal(t, []int{0, 1, 2}, f.observed)
+	assert.Equal(t, []int{2}, f.finished)
+}
+
+// generationalEvaluator records the generations it's notified of, and reports
+// the number of evaluations of the current one.
+type generationalEvaluator struct {
+	intEvaluator
+	mu    sync.Mutex
+	gens  []int
+	calls int
+}
+
+func (e *generationalEvaluator) StartGeneration(gen int) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if len(e.gens) == 0 || e.gens[len(e.gens)-1] != gen {
+		e.gens = append(e.gens, gen)
+		e.calls = 0
+	}
+}
+
+func (e *generationalEvaluator) Fitness(cand interface{}, pop []interface{}) float64 {
+	e.mu.Lock()
+	e.calls++
+	e.mu.Unlock()
+	return e.intEvaluator.Fitness(cand, pop)
+}
+
+func (e *generationalEvaluator) EvaluatorStats() map[string]float64 {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return map[string]float64{"calls": float64(e.calls)}
+}
+
+func TestEngineGenerationalEvaluator(t *testing.T) {
+	eval := &generationalEvaluator{}
+	epocher := Generational{Op: zeroIntMaker{}, Eval: eval, Sel: selection.NewTournament()}
+
+	var calls []float64
+	eng, err := New(zeroFactory, eval, &epocher,
+		Observe(ObserverFunc(func(stats *evolve.PopulationStats) {
+			calls = append(calls, stats.EvaluatorStats["calls"])
+		})))
+	check(t, err)
+
+	_, _, err = eng.Evolve(10, Elites(2), EndOn(condition.GenerationCount(3)))
+	check(t, err)
+	assert.Equal(t, []int{0, 1, 2}, eval.gens)
+	assert.Equal(t, []float64{10, 10, 10}, calls)
+}
+
+func TestEngineResetsConditions(t *testing.T) {
+	// The fitness never improves, the condition is satisfied at the 4th
+	// generation of every run.
+	stagnation := &condition.Stagnation{Generations: 3}
+
+	var last int
+	epocher := Generational{Op: zeroIntMaker{}, Eval: intEvaluator{}, Sel: selection.NewTournament()}
+	eng, err := New(zeroFactory, intEvaluator{}, &epocher,
+		EndOn(stagnation),
+		Observe(ObserverFunc(func(stats *evolve.PopulationStats) { last = stats.GenNumber })))
+	check(t, err)
+
+	for run := 0; run < 2; run++ {
+		_, satisfied, err := eng.Evolve(10)
+		check(t, err)
+		assert.Equal(t, 3, last, "run %d", run)
+		assert.Len(t, satisfied, 1)
+	}
+}
+
+func TestEngineEarlyAbort(t *testing.T) {
+	abort := condition.NewUserAbort()
+	abort.Abort()
+
+	var last int
+	epocher := Generational{Op: zeroIntMaker{}, Eval: intEvaluator{}, Sel: selection.NewTournament()}
+	eng, err := New(zeroFactory, intEvaluator{}, &epocher,
+		EndOn(abort),
+		EndOn(condition.GenerationCount(10)),
+		Observe(ObserverFunc(func(stats *evolve.PopulationStats) { last = stats.GenNumber })))
+	check(t, err)
+
+	// The abort made before the evolution starts is honoured.
+	_, satisfied, err := eng.Evolve(10)
+	check(t, err)
+	assert.Equal(t, 0, last)
+	assert.Equal(t, []evolve.Condition{abort}, satisfied)
+}
+
+// countingEvaluator counts the calls to Fitness.
+type countingEvaluator struct {
+	intEvaluator
+	calls int64
+}
+
+func (e *countingEvaluator) Fitness(cand interface{}, pop []interface{}) float64 {
+	atomic.AddInt64(&e.calls, 1)
+	return e.intEvaluator.Fitness(cand, pop)
+}
+
+func TestEngineEvaluationBudget(t *testing.T) {
+	factory := evolve.FactoryFunc(func(rng *rand.Rand) interface{} { return rng.Intn(1000) })
+
+	t.Run("exact", func(t *testing.T) {
+		eval := &countingEvaluator{}
+		epocher := Generational{Op: identityOp{}, Eval: eval, Sel: selection.NewTournament()}
+		var last evolve.PopulationStats
+		eng, err := New(factory, eval, &epocher,
+			Rand(rand.New(rand.NewSource(99))),
+			Observe(ObserverFunc(func(s *evolve.PopulationStats) { last = *s })))
+		check(t, err)
+
+		pop, satisfied, err := eng.Evolve(10, Elites(2),
+			EndOn(condition.EvaluationCount(35)),
+			EndOn(condition.GenerationCount(100)))
+		check(t, err)
+
+		// The last generation has only been partially evaluated.
+		assert.Equal(t, int64(35), eval.calls)
+		assert.Equal(t, int64(35), last.Evaluations)
+		assert.Equal(t, 3, last.GenNumber)
+		assert.Len(t, pop, 10)
+		assert.Equal(t, []evolve.Condition{condition.EvaluationCount(35)}, satisfied)
+	})
+
+	t.Run("cache hits", func(t *testing.T) {
+		cache := &evolve.FitnessCache{Wrapped: intEvaluator{}}
+		epocher := Generational{Op: zeroIntMaker{}, Eval: cache, Sel: selection.NewTournament()}
+		var stats []evolve.PopulationStats
+		eng, err := New(factory, cache, &epocher,
+			Rand(rand.New(rand.NewSource(99))),
+			Observe(ObserverFunc(func(s *evolve.PopulationStats) { stats = append(stats, *s) })))
+		check(t, err)
+
+		_, _, err = eng.Evolve(10, Elites(2),
+			EndOn(condition.EvaluationCount(100)),
+			EndOn(condition.GenerationCount(5)))
+		check(t, err)
+
+		assert.Len(t, stats, 5)
+		for i, s := range stats {
+			assert.Equal(t, int64(10*(i+1)), s.Evaluations+s.CacheHits)
+		}
+		last := stats[len(stats)-1]
+		assert.Equal(t, cache.Stats().Misses, last.Evaluations)
+		assert.Equal(t, cache.Stats().Hits, last.CacheHits)
+	})
+
+	t.Run("combinators", func(t *testing.T) {
+		tests := []struct {
+			cond  evolve.Condition
+			calls int64
+		}{
+			{cond: condition.Or(condition.EvaluationCount(35), condition.GenerationCount(100)), calls: 35},
+			{cond: condition.And(condition.EvaluationCount(35), condition.EvaluationCount(45)), calls: 45},
+			{cond: condition.Or(condition.GenerationCount(100), condition.And(condition.EvaluationCount(25), condition.EvaluationCount(33))), calls: 33},
+			// The budget is not reached before the 5th generation.
+			{cond: condition.And(condition.EvaluationCount(35), condition.GenerationCount(5)), calls: 50},
+			{cond: condition.After(5, condition.EvaluationCount(35)), calls: 50},
+		}
+		for _, tt := range tests {
+			t.Run(tt.cond.String(), func(t *testing.T) {
+				eval := &countingEvaluator{}
+				epocher := Generational{Op: identityOp{}, Eval: eval, Sel: selection.NewTournament()}
+				eng, err := New(factory, eval, &epocher, Rand(rand.New(rand.NewSource(99))))
+				check(t, err)
+
+				_, satisfied, err := eng.Evolve(10, Elites(2), EndOn(tt.cond))
+				check(t, err)
+				assert.Len(t, satisfied, 1)
+				assert.Equal(t, tt.calls, eval.calls)
+			})
+		}
+	})
+
+	t.Run("elites are preserved", func(t *testing.T) {
+		epocher := Generational{Op: zeroIntMaker{}, Eval: intEvaluator{}, Sel: selection.NewTournament()}
+		var best []interface{}
+		eng, err := New(factory, intEvaluator{}, &epocher,
+			Rand(rand.New(rand.NewSource(99))),
+			Observe(ObserverFunc(func(s *evolve.PopulationStats) {
+				best = append(best, s.BestCand)
+			})))
+		check(t, err)
+
+		// The budget is exhausted after the zeroed offspring and the first
+		// elite of the second generation are evaluated.
+		pop, _, err := eng.Evolve(10, Elites(3), EndOn(condition.EvaluationCount(18)))
+		check(t, err)
+		assert.Len(t, pop, 10)
+		assert.Equal(t, best[0], best[1])
+		for i, ind := range pop {
+			assert.Equal(t, i < 3, ind.Fitness > 0, "individual %d", i)
+		}
+	})
+
+	t.Run("varying offspring counts", func(t *testing.T) {
+		eval := &countingEvaluator{}
+		var last evolve.PopulationStats
+		eng, err := New(factory, eval, &steadyState{factory: factory, eval: eval},
+			Rand(rand.New(rand.NewSource(99))),
+			Observe(ObserverFunc(func(s *evolve.PopulationStats) { last = *s })))
+		check(t, err)
+
+		// 10 evaluations for the initial population, then 1, 2, 3, 4, 1, 2 and
+		// 3 offspring, the last generation being partially evaluated.
+		pop, satisfied, err := eng.Evolve(10, EndOn(condition.EvaluationCount(24)))
+		check(t, err)
+		assert.Equal(t, int64(24), eval.calls)
+		assert.Equal(t, int64(24), last.Evaluations)
+		assert.Equal(t, 7, last.GenNumber)
+		assert.Len(t, pop, 10)
+		assert.Equal(t, []evolve.Condition{condition.EvaluationCount(24)}, satisfied)
+	})
+
+	t.Run("not a binder", func(t *testing.T) {
+		eng, err := New(factory, intEvaluator{}, epocherFunc(func(pop evolve.Population, _ int, _ *rand.Rand) evolve.Population {
+			return pop
+		}))
+		check(t, err)
+		_, _, err = eng.Evolve(10, EndOn(condition.Or(condition.EvaluationCount(20))))
+		assert.Error(t, err)
+	})
+
+	t.Run("invalid budget", func(t *testing.T) {
+		epocher := Generational{Op: identityOp{}, Eval: intEvaluator{}, Sel: selection.NewTournament()}
+		eng, err := New(factory, intEvaluator{}, &epocher)
+		check(t, err)
+		_, _, err = eng.Evolve(10, EndOn(condition.EvaluationCount(0)))
+		assert.Error(t, err)
+	})
+}
+
+type epocherFunc func(evolve.Population, int, *rand.Rand) evolve.Population
+
+func (f epocherFunc) Epoch(pop evolve.Population, nelites int, rng *rand.Rand) evolve.Population {
+	return f(pop, nelites, rng)
+}
+
+// steadyState is an epocher replacing, at every generation, the weakest
+// individuals by 1, 2, 3 or 4 new random candidates.
+type steadyState struct {
+	factory evolve.Factory
+	eval    evolve.Evaluator
+	eng     *Engine
+	gen     int
+}
+
+func (s *steadyState) Bind(eng *Engine) { s.eng, s.gen = eng, 0 }
+
+func (s *steadyState) Epoch(pop evolve.Population, nelites int, rng *rand.Rand) evolve.Population {
+	s.gen++
+	cands := make([]interface{}, 1+(s.gen-1)%4)
+	for i := range cands {
+		cands[i] = s.factory.New(rng)
+	}
+	off := s.eng.Evaluate(cands, s.eval)
+	return append(pop[:len(pop)-len(off):len(pop)-len(off)], off...)
+}
@@ -0,0 +1,96 @@
+// Package enginetest implements utilities for testing evolution engines and
+// the components they are made of.
+package enginetest
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/arl/evolve"
+	"github.com/arl/evolve/engine"
+)
+
+// A Run records the statistics of every generation of an evolution.
+type Run struct {
+	Stats     []evolve.PopulationStats
+	Satisfied []string
+}
+
+// Record creates an engine with newEngine, runs the evolution of a population
+// of popsize candidates, with options, and returns its record.
+//
+// Elapsed time and timings being inherently non reproducible, they are zeroed
+// in the recorded statistics.
+func Record(newEngine func() (*engine.Engine, error), popsize int, options ...func(*engine.Engine) error) (*Run, error) {
+	eng, err := newEngine()
+	if err != nil {
+		return nil, fmt.Errorf("can't create engine: %v", err)
+	}
+
+	var run Run
+	obs := engine.ObserverFunc(func(stats *evolve.PopulationStats) {
+		cpy := *stats
+		cpy.Elapsed = 0
+		cpy.Timings = evolve.Timings{}
+		if cpy.Operators != nil {
+			cpy.Operators = append([]evolve.OperatorStats(nil), cpy.Operators...)
+			for i := range cpy.Operators {
+				cpy.Operators[i].Time = 0
+			}
+		}
+		run.Stats = append(run.Stats, cpy)
+	})
+	eng.AddObserver(obs)
+	defer eng.RemoveObserver(obs)
+
+	_, satisfied, err := eng.Evolve(popsize, options...)
+	if err != nil {
+		return nil, fmt.Errorf("can't evolve: %v", err)
+	}
+	for _, cond := range satisfied {
+		run.Satisfied = append(run.Satisfied, cond.String())
+	}
+	return &run, nil
+}
+
+// CheckReproducible runs the same evolution twice and reports a test error if
+// the statistics of any generation differ between both runs.
+//
+// newEngine must create a new engine for every call, configured so that the
+// evolution is reproducible. That is usually achieved by seeding the engine
+// source of randomness with a constant and enabling the deterministic mode:
+//
+//	newEngine := func() (*engine.Engine, error) {
+//		rng := rand.New(mt19937.New(42))
+//		return engine.New(fac, eval, &epocher, engine.Rand(rng), engine.Deterministic())
+//	}
+//	enginetest.CheckReproducible(t, newEngine, 100, engine.EndOn(condition.GenerationCount(50)))
+//
+// Since options are applied to both engines, they should not hold a state that
+// is modified during evolution.
+func CheckReproducible(t testing.TB, newEngine func() (*engine.Engine, error), popsize int, options ...func(*engine.Engine) error) {
+	t.Helper()
+
+	run1, err := Record(newEngine, popsize, options...)
+	if err != nil {
+		t.Fatalf("first run: %v", err)
+	}
+	run2, err := Record(newEngine, popsize, options...)
+	if err != nil {
+		t.Fatalf("second run: %v", err)
+	}
+
+	if len(run1.Stats) != len(run2.Stats) {
+		t.Errorf("runs have a different number of generations: %d != %d", len(run1.Stats), len(run2.Stats))
+	}
+	for i := 0; i < len(run1.Stats) && i < len(run2.Stats); i++ {
+		if !reflect.DeepEqual(run1.Stats[i], run2.Stats[i]) {
+			t.Errorf("generation %d differs between runs:\nfirst:  %+v\nsecond: %+v", i, run1.Stats[i], run2.Stats[i])
+			return
+		}
+	}
+	if !reflect.DeepEqual(run1.Satisfied, run2.Satisfied) {
+		t.Errorf("satisfied conditions differ between runs: %q != %q", run1.Satisfied, run2.Satisfied)
+	}
+}
@@ -0,0 +1,129 @@
+package enginetest
+
+import (
+	"math/rand"
+	"sync"
+	"testing"
+
+	"github.com/arl/evolve/condition"
+	"github.com/arl/evolve/engine"
+	"github.com/arl/evolve/factory"
+	"github.com/arl/evolve/generator"
+	"github.com/arl/evolve/operator"
+	"github.com/arl/evolve/operator/mutation"
+	"github.com/arl/evolve/operator/xover"
+	"github.com/arl/evolve/pkg/mt19937"
+	"github.com/arl/evolve/selection"
+)
+
+const alphabet = "ABCDEFGHIJKLMNOPQRSTUVWXYZ "
+
+// stateful is a fitness evaluator which result depends on the order in which
+// candidates are evaluated: every evaluation adds a penalty that increases
+// with the number of evaluations performed so far.
+type stateful struct {
+	mu    sync.Mutex
+	count int
+}
+
+func (s *stateful) Fitness(cand interface{}, _ []interface{}) float64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.count++
+	var nerrs int
+	for i, c := range cand.(string) {
+		if byte(c) != "EVOLVE WORLD"[i] {
+			nerrs++
+		}
+	}
+	return float64(nerrs) + float64(s.count%7)/10
+}
+
+func (*stateful) IsNatural() bool { return false }
+
+func newEngine(workers int) func() (*engine.Engine, error) {
+	return func() (*engine.Engine, error) {
+		fac, err := factory.NewString(alphabet, len("EVOLVE WORLD"))
+		if err != nil {
+			return nil, err
+		}
+		mut := mutation.New(&mutation.String{
+			Alphabet:    alphabet,
+			Probability: generator.ConstFloat64(0.02),
+		})
+		xover := xover.New(xover.StringMater{})
+		xover.Points = generator.ConstInt(1)
+		xover.Probability = generator.ConstFloat64(1)
+
+		eval := &stateful{}
+		epocher := engine.Generational{
+			Op:      operator.Pipeline{mut, xover},
+			Eval:    eval,
+			Sel:     selection.NewTournament(),
+			Workers: workers,
+		}
+		rng := rand.New(mt19937.New(99))
+		return engine.New(fac, eval, &epocher, engine.Rand(rng), engine.Deterministic())
+	}
+}
+
+func TestCheckReproducible(t *testing.T) {
+	for _, workers := range []int{0, 3} {
+		CheckReproducible(t, newEngine(workers), 200,
+			engine.Elites(2),
+			engine.EndOn(condition.GenerationCount(30)))
+	}
+}
+
+func TestRecord(t *testing.T) {
+	run, err := Record(newEngine(0), 50, engine.EndOn(condition.GenerationCount(5)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(run.Stats) != 5 {
+		t.Errorf("got %d recorded generations, want 5", len(run.Stats))
+	}
+	for i, stats := range run.Stats {
+		if stats.GenNumber != i {
+			t.Errorf("Stats[%d].GenNumber = %d", i, stats.GenNumber)
+		}
+		if stats.Elapsed != 0 {
+			t.Errorf("Stats[%d].Elapsed = %v, want 0", i, stats.Elapsed)
+		}
+	}
+	if len(run.Satisfied) != 1 {
+		t.Errorf("got %d satisfied conditions, want 1", len(run.Satisfied))
+	}
+}
+
+// recorder is a testing.TB recording whether errors have been reported.
+type recorder struct {
+	testing.TB
+	failed bool
+}
+
+func (r *recorder) Errorf(string, ...interface{}) { r.failed = true }
+
+func TestCheckReproducibleDetectsRandomness(t *testing.T) {
+	// An engine which source of randomness is seeded with the current time.
+	unseeded := func() (*engine.Engine, error) {
+		fac, err := factory.NewString(alphabet, len("EVOLVE WORLD"))
+		if err != nil {
+			return nil, err
+		}
+		eval := &stateful{}
+		epocher := engine.Generational{
+			Op:   mutation.New(&mutation.String{Alphabet: alphabet, Probability: generator.ConstFloat64(0.1)}),
+			Eval: eval,
+			Sel:  selection.NewTournament(),
+		}
+		return engine.New(fac, eval, &epocher, engine.Deterministic())
+	}
+
+	r := &recorder{TB: t}
+	CheckReproducible(r, unseeded, 100, engine.EndOn(condition.GenerationCount(10)))
+	if !r.failed {
+		t.Errorf("CheckReproducible didn't report an error for an unseeded engine")
+	}
+}
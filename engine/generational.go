@@ -1,11 +1,21 @@
 package engine
 
 import (
+	"fmt"
 	"math/rand"
+	"reflect"
+	"sync"
+	"time"
 
 	"github.com/arl/evolve"
+	"github.com/arl/evolve/pkg/mt19937"
 )
 
+// DefaultChunkSize is the number of selected candidates that make up a chunk
+// when the genetic operators are applied concurrently and Generational's
+// ChunkSize is 0.
+const DefaultChunkSize = 256
+
 // Generational implements a general-purpose engine for generational
 // evolutionary algorithm.
 //
@@ -23,12 +33,49 @@ import (
 // where it is not permitted for applications to manage their own threads. If
 // there are no restrictions on concurrency, applications should enable
 // multi-threading for improved performance.
+//
+// The genetic operators can also be applied concurrently, by setting Workers
+// to a strictly positive value. In that case the selected candidates are split
+// into chunks of ChunkSize candidates and Op is applied to each chunk
+// independently, by a pool of Workers goroutines. Every chunk gets its own
+// source of randomness, derived from the engine source of randomness before
+// the chunks are dispatched. The next generation is thus only determined by the
+// engine seed and the chunk size, and does not depend on the number of workers:
+// running with 1 or 16 workers produces the exact same results, even when the
+// engine runs in deterministic mode (see Deterministic). Op, and the
+// random generators it may use (generator.Int, generator.Float, etc.), must be
+// safe for concurrent use by multiple goroutines.
+//
+// When the engine tracks the lineage of the individuals (see Lineage), the
+// offspring are traced through Op with evolve.ApplyTrace, and their origin is
+// recorded in the engine genealogy, while the elites keep their origin.
+//
+// The offspring are evaluated before the elites. If the evaluation budget of
+// the engine (see evolve.BudgetCondition) is exhausted in the middle of a
+// generation, so that the budget is honoured exactly, the elites are
+// preserved, and the offspring that could be evaluated only replace the
+// weakest individuals of the population.
 type Generational struct {
 	Op   evolve.Operator
 	Eval evolve.Evaluator
 	Sel  evolve.Selection
+
+	// Workers is the number of goroutines applying Op to the selected
+	// candidates. If Workers is 0, Op is applied once to the whole selection,
+	// on the calling goroutine, with the engine source of randomness.
+	Workers int
+
+	// ChunkSize is the number of selected candidates in each chunk when
+	// Workers is strictly positive. If ChunkSize is 0, DefaultChunkSize is
+	// used.
+	ChunkSize int
+
+	eng *Engine // engine running the epocher, if any
 }
 
+// Bind implements Binder.
+func (e *Generational) Bind(eng *Engine) { e.eng = eng }
+
 // Epoch performs a single step/iteration of the evolutionary process.
 //
 // pop is the population at the beginning of the process.
@@ -39,6 +86,8 @@ type Generational struct {
 func (e *Generational) Epoch(pop evolve.Population, nelites int, rng *rand.Rand) evolve.Population {
 	nextpop := make([]interface{}, 0, len(pop))
 
+	var timings evolve.Timings
+
 	// Perform elitism: straightforward copy the n fittest candidates into the
 	// next generation, without any kind of selection.
 	elite := make([]interface{}, nelites)
@@ -47,12 +96,230 @@ func (e *Generational) Epoch(pop evolve.Population, nelites int, rng *rand.Rand)
 	}
 
 	// Select the rest of population through natural selection
+	start := time.Now()
 	selected := e.Sel.Select(pop, e.Eval.IsNatural(), len(pop)-nelites, rng)
+	timings.Selection = time.Since(start)
 
-	// Apply genetic operators on the selected candidates
-	nextpop = e.Op.Apply(append(nextpop, selected...), rng)
+	// Apply genetic operators on the selected candidates, tracing the
+	// offspring when the engine tracks the lineage of the individuals or the
+	// activity of the operators.
+	trace := e.eng != nil && (e.eng.lineage != nil || e.eng.telemetry)
+	var (
+		derivs []evolve.Derivation
+		ops    []evolve.OperatorStats
+	)
+	start = time.Now()
+	if e.Workers > 0 {
+		var off []interface{}
+		off, derivs, ops = e.applyChunks(selected, rng, trace)
+		nextpop = append(nextpop, off...)
+	} else {
+		nextpop, derivs, ops = e.apply(append(nextpop, selected...), rng, trace)
+	}
+	timings.Operators = time.Since(start)
 
 	// While the elite is added, untouched, to the next population
 	nextpop = append(nextpop, elite...)
-	return evolve.EvaluatePopulation(nextpop, e.Eval, true)
+	if e.eng == nil {
+		return evolve.EvaluatePopulation(nextpop, e.Eval, true)
+	}
+
+	start = time.Now()
+	evpop := e.eng.Evaluate(nextpop, e.Eval)
+	timings.Evaluation = time.Since(start)
+
+	e.eng.cur = generation{timings: timings, ops: ops}
+
+	// The elites are evaluated last, so the offspring, and then the elites,
+	// may not all have been evaluated if the engine evaluation budget has been
+	// exhausted.
+	noff := len(nextpop) - nelites
+	nevals := len(evpop)
+	if nevals < noff {
+		noff = nevals
+	}
+	if trace {
+		e.trace(pop, selected, derivs, evpop[:noff])
+	}
+	if e.eng.lineage != nil {
+		for i := noff; i < nevals; i++ {
+			evpop[i].Origin = pop[i-noff].Origin
+		}
+	}
+	if nevals < len(nextpop) {
+		// The evaluation budget is exhausted. The elites that couldn't be
+		// evaluated keep their individuals of the previous generation, and
+		// the offspring that couldn't be evaluated are replaced by the fittest
+		// non-elite individuals, so that only the weakest individuals of the
+		// previous generation are dropped.
+		nel := nevals - noff
+		evpop = append(evpop, pop[nel:nelites]...)
+		evpop = append(evpop, pop[nelites:nelites+len(pop)-nelites-noff]...)
+	}
+	return evpop
+}
+
+// apply applies the genetic operators to sel, and returns the derivations of
+// the offspring and the stats of the operators if trace is true.
+func (e *Generational) apply(sel []interface{}, rng *rand.Rand, trace bool) ([]interface{}, []evolve.Derivation, []evolve.OperatorStats) {
+	if trace {
+		return evolve.ApplyInstrumented(e.Op, sel, rng)
+	}
+	return e.Op.Apply(sel, rng), nil, nil
+}
+
+// trace follows the offspring in off, derived from the selected candidates
+// sel, back to their parents in pop. It counts the offspring fitter than all
+// their parents and, if the engine tracks the lineage, records the origin of
+// the offspring in the engine genealogy.
+func (e *Generational) trace(pop evolve.Population, sel []interface{}, derivs []evolve.Derivation, off evolve.Population) {
+	// Match candidates to individuals, the fittest individual wins when
+	// several have the same identity.
+	inds := make(map[interface{}]*evolve.Individual, len(pop))
+	for i := len(pop) - 1; i >= 0; i-- {
+		inds[identity(pop[i].Candidate)] = pop[i]
+	}
+
+	natural := e.Eval.IsNatural()
+	for i, ind := range off {
+		var (
+			parents  []uint64
+			op       string
+			nparents int
+			improved = true
+		)
+		if i < len(derivs) {
+			for _, p := range derivs[i].Parents {
+				parent, ok := inds[identity(sel[p])]
+				if !ok {
+					continue
+				}
+				nparents++
+				if parent.Origin != nil {
+					parents = append(parents, parent.Origin.ID)
+				}
+				if ind.Fitness == parent.Fitness || (ind.Fitness > parent.Fitness) != natural {
+					improved = false
+				}
+			}
+			op = derivs[i].Op
+		}
+		if improved && nparents > 0 && op != "" {
+			e.eng.cur.improved++
+		}
+		if e.eng.lineage != nil {
+			e.eng.lineage.Add(ind, parents, op, e.eng.ngen+1)
+		}
+	}
+}
+
+// identity returns a value identifying cand, usable as a map key: cand itself
+// if it's comparable, the address and length of slices, etc.
+func identity(cand interface{}) interface{} {
+	type ref struct {
+		typ reflect.Type
+		ptr uintptr
+		len int
+	}
+
+	if cand == nil {
+		return nil
+	}
+	v := reflect.ValueOf(cand)
+	switch v.Kind() {
+	case reflect.Slice:
+		return ref{v.Type(), v.Pointer(), v.Len()}
+	case reflect.Map, reflect.Func, reflect.Chan:
+		return ref{typ: v.Type(), ptr: v.Pointer()}
+	}
+	if v.Type().Comparable() {
+		return cand
+	}
+	if key, ok := evolve.CandidateKey(cand); ok {
+		return key
+	}
+	return fmt.Sprintf("%#v", cand)
+}
+
+// applyChunks splits the selection into chunks and concurrently applies the
+// genetic operators to each of them. The offspring are returned in the chunks
+// order.
+//
+// If trace is true, the derivations of the offspring and the stats of the
+// operators are also returned. The time reported for each operator is then the
+// total time spent by the workers applying it.
+func (e *Generational) applyChunks(sel []interface{}, rng *rand.Rand, trace bool) ([]interface{}, []evolve.Derivation, []evolve.OperatorStats) {
+	size := e.ChunkSize
+	if size <= 0 {
+		size = DefaultChunkSize
+	}
+
+	type chunk struct {
+		start  int
+		sel    []interface{}
+		rng    *rand.Rand
+		off    []interface{}
+		derivs []evolve.Derivation
+		ops    []evolve.OperatorStats
+	}
+
+	// Chunks and their sources of randomness are determined on the calling
+	// goroutine so that they only depend on the engine rng.
+	chunks := make([]chunk, 0, (len(sel)+size-1)/size)
+	for i := 0; i < len(sel); i += size {
+		end := i + size
+		if end > len(sel) {
+			end = len(sel)
+		}
+		chunks = append(chunks, chunk{
+			start: i,
+			sel:   sel[i:end:end],
+			rng:   rand.New(mt19937.New(rng.Int63())),
+		})
+	}
+
+	nworkers := e.Workers
+	if nworkers > len(chunks) {
+		nworkers = len(chunks)
+	}
+
+	idx := make(chan int)
+	var wg sync.WaitGroup
+	wg.Add(nworkers)
+	for w := 0; w < nworkers; w++ {
+		go func() {
+			defer wg.Done()
+			for i := range idx {
+				c := &chunks[i]
+				c.off, c.derivs, c.ops = e.apply(c.sel, c.rng, trace)
+			}
+		}()
+	}
+	for i := range chunks {
+		idx <- i
+	}
+	close(idx)
+	wg.Wait()
+
+	off := make([]interface{}, 0, len(sel))
+	var (
+		derivs []evolve.Derivation
+		ops    []evolve.OperatorStats
+	)
+	for _, c := range chunks {
+		off = append(off, c.off...)
+		if !trace {
+			continue
+		}
+		ops = evolve.MergeOperatorStats(ops, c.ops)
+		// Parents indexes are relative to the chunk.
+		for _, d := range c.derivs {
+			parents := make([]int, len(d.Parents))
+			for j, p := range d.Parents {
+				parents[j] = c.start + p
+			}
+			derivs = append(derivs, evolve.Derivation{Parents: parents, Op: d.Op})
+		}
+	}
+	return off, derivs, ops
 }
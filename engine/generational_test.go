@@ -11,9 +11,12 @@ import (
 	"github.com/arl/evolve/condition"
 	"github.com/arl/evolve/factory"
 	"github.com/arl/evolve/generator"
+	"github.com/arl/evolve/lineage"
 	"github.com/arl/evolve/operator"
 	"github.com/arl/evolve/operator/mutation"
 	"github.com/arl/evolve/operator/xover"
+	"github.com/arl/evolve/pkg/bitstring"
+	"github.com/arl/evolve/pkg/mt19937"
 	"github.com/arl/evolve/selection"
 )
 
@@ -202,3 +205,182 @@ func (s evaluator) Fitness(
 // Fitness is not natural, one fitness point represents an error, so the lower
 // is better
 func (evaluator) IsNatural() bool { return false }
+
+func TestGenerationalWorkersDeterminism(t *testing.T) {
+	// run returns the final population of a run of 20 generations, with the
+	// operator phase parallelised over the given number of workers.
+	run := func(workers int) []string {
+		mut := mutation.New(&mutation.Bitstring{
+			Probability: generator.ConstFloat64(0.2),
+			FlipCount:   generator.ConstInt(2),
+		})
+		eval := evolve.EvaluatorFunc(true, func(cand interface{}, _ []interface{}) float64 {
+			return float64(cand.(*bitstring.Bitstring).OnesCount())
+		})
+		epocher := Generational{
+			Op:        mut,
+			Eval:      eval,
+			Sel:       selection.NewTournament(),
+			Workers:   workers,
+			ChunkSize: 7,
+		}
+		eng, err := New(factory.Bitstring(32), eval, &epocher, Rand(rand.New(mt19937.New(1234))))
+		check(t, err)
+
+		pop, _, err := eng.Evolve(100, Elites(3), EndOn(condition.GenerationCount(20)))
+		check(t, err)
+
+		cands := make([]string, len(pop))
+		for i := range pop {
+			cands[i] = pop[i].Candidate.(*bitstring.Bitstring).String()
+		}
+		return cands
+	}
+
+	want := run(1)
+	for _, workers := range []int{2, 4, 16} {
+		assert.Equalf(t, want, run(workers), "Workers = %d, final population differs from Workers = 1", workers)
+	}
+}
+
+func TestGenerationalLineage(t *testing.T) {
+	// run returns the final population of a run of 10 generations, tracking
+	// the lineage in g, if it's not nil, bounded to gens generations.
+	run := func(workers int, g *lineage.Genealogy, gens int) evolve.Population {
+		xover := xover.New(xover.BitstringMater{})
+		xover.Points = generator.ConstInt(1)
+		xover.Probability = generator.ConstFloat64(0.7)
+		mut := mutation.New(&mutation.Bitstring{
+			Probability: generator.ConstFloat64(0.2),
+			FlipCount:   generator.ConstInt(1),
+		})
+		eval := evolve.EvaluatorFunc(true, func(cand interface{}, _ []interface{}) float64 {
+			return float64(cand.(*bitstring.Bitstring).OnesCount())
+		})
+		epocher := Generational{
+			Op:        operator.Pipeline{xover, mut},
+			Eval:      eval,
+			Sel:       selection.NewTournament(),
+			Workers:   workers,
+			ChunkSize: 8,
+		}
+		opts := []func(*Engine) error{Rand(rand.New(mt19937.New(1234)))}
+		if g != nil {
+			opts = append(opts, Lineage(g, gens))
+		}
+		eng, err := New(factory.Bitstring(32), eval, &epocher, opts...)
+		check(t, err)
+
+		pop, _, err := eng.Evolve(50, Elites(2), EndOn(condition.GenerationCount(10)))
+		check(t, err)
+		return pop
+	}
+
+	for _, workers := range []int{0, 3} {
+		g, err := lineage.New()
+		check(t, err)
+		pop := run(workers, g, 0)
+
+		// Tracking the lineage doesn't change the evolution.
+		assert.Equal(t, run(workers, nil, 0).String(), pop.String())
+
+		ids := make(map[uint64]bool)
+		for _, ind := range pop {
+			if !assert.NotNil(t, ind.Origin) {
+				return
+			}
+			assert.False(t, ids[ind.Origin.ID], "duplicate ID %d", ind.Origin.ID)
+			ids[ind.Origin.ID] = true
+
+			rec, ok := g.Get(ind.Origin.ID)
+			assert.True(t, ok)
+			assert.Equal(t, *ind.Origin, rec.Origin)
+			if rec.Born == 0 {
+				continue
+			}
+			assert.NotEmpty(t, rec.Parents)
+			for _, p := range rec.Parents {
+				prec, ok := g.Get(p)
+				assert.True(t, ok)
+				assert.Less(t, prec.Born, rec.Born)
+			}
+			if len(rec.Parents) == 2 {
+				assert.Contains(t, rec.Op, "crossover")
+			}
+		}
+
+		// The ancestry of the fittest individual goes back to the initial
+		// population.
+		recs, err := g.Ancestry(pop[0].Origin.ID, 0)
+		check(t, err)
+		assert.Equal(t, 0, recs[0].Born)
+
+		// Individuals without descendant have been pruned.
+		assert.Less(t, g.Len(), 50+10*48)
+
+		// Only the last 3 generations, 7 to 9, and the final population are
+		// kept when the genealogy is bounded.
+		g, err = lineage.New()
+		check(t, err)
+		pop = run(workers, g, 3)
+		assert.LessOrEqual(t, g.Len(), 4*50)
+		recs, err = g.Ancestry(pop[0].Origin.ID, 0)
+		check(t, err)
+		for _, rec := range recs {
+			assert.True(t, rec.Born >= 7 || rec.ID == pop[0].Origin.ID, "record %d born in generation %d", rec.ID, rec.Born)
+		}
+	}
+}
+
+func TestGenerationalTelemetry(t *testing.T) {
+	for _, workers := range []int{0, 3} {
+		xover := xover.New(xover.BitstringMater{})
+		xover.Points = generator.ConstInt(1)
+		xover.Probability = generator.ConstFloat64(0.5)
+		mut := mutation.New(&mutation.Bitstring{
+			Probability: generator.ConstFloat64(0.2),
+			FlipCount:   generator.ConstInt(1),
+		})
+		eval := evolve.EvaluatorFunc(true, func(cand interface{}, _ []interface{}) float64 {
+			return float64(cand.(*bitstring.Bitstring).OnesCount())
+		})
+		epocher := Generational{
+			Op:        operator.Pipeline{xover, mut},
+			Eval:      eval,
+			Sel:       selection.NewTournament(),
+			Workers:   workers,
+			ChunkSize: 8,
+		}
+
+		var stats []evolve.PopulationStats
+		eng, err := New(factory.Bitstring(32), eval, &epocher,
+			Rand(rand.New(mt19937.New(1234))),
+			Telemetry(),
+			Observe(ObserverFunc(func(s *evolve.PopulationStats) {
+				stats = append(stats, *s)
+			})))
+		check(t, err)
+
+		_, _, err = eng.Evolve(50, Elites(2), EndOn(condition.GenerationCount(5)))
+		check(t, err)
+
+		assert.Nil(t, stats[0].Operators)
+		assert.Zero(t, stats[0].Timings.Selection)
+		var improved int
+		for _, s := range stats[1:] {
+			if !assert.Len(t, s.Operators, 2) {
+				return
+			}
+			for i, name := range []string{"crossover", "mutation"} {
+				op := s.Operators[i]
+				assert.Equal(t, name, op.Name)
+				assert.Equal(t, 48, op.Offspring)
+				assert.True(t, op.Changed > 0 && op.Changed <= op.Offspring)
+			}
+			assert.True(t, s.Improved <= 48)
+			assert.True(t, s.Timings.Total() > 0)
+			improved += s.Improved
+		}
+		assert.True(t, improved > 0)
+	}
+}
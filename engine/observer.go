@@ -13,6 +13,16 @@ type Observer interface {
 	Observe(*evolve.PopulationStats)
 }
 
+// A Finisher is an Observer that is also notified when the evolution ends, for
+// example to flush some buffered output.
+type Finisher interface {
+	Observer
+
+	// Finish is called once, when Evolve is about to return, with the stats
+	// of the last generation, that has already been observed.
+	Finish(*evolve.PopulationStats)
+}
+
 type observerFunc struct{ f func(*evolve.PopulationStats) }
 
 // The ObserverFunc type is an adapter to allow the use of
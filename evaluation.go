@@ -9,32 +9,34 @@ import "sync"
 // sorted, either in descending order of fitness for natural scores, or
 // ascending for non-natural scores.
 //
+// If e is a VectorEvaluator, the vector of scores is recorded in the
+// individuals and their fitness is the first score. If e is a NoisyEvaluator,
+// the sample count and confidence interval of each fitness estimate are
+// recorded in the individuals.
+//
 // Returns the evaluated population (a slice of individuals, each of which
 // associated with its fitness).
 func EvaluatePopulation(pop []interface{}, e Evaluator, concurrent bool) Population {
-	var evpop Population
+	return EvaluateRange(pop, 0, len(pop), e, concurrent)
+}
+
+// EvaluateRange is like EvaluatePopulation, but it only evaluates the
+// candidates pop[from:to], in the context of the whole population pop, and
+// returns the corresponding individuals.
+func EvaluateRange(pop []interface{}, from, to int, e Evaluator, concurrent bool) Population {
+	evpop := make(Population, to-from)
 
 	if !concurrent {
-		evpop = make(Population, len(pop))
-		for i, candidate := range pop {
-			evpop[i] = &Individual{
-				Candidate: candidate,
-				Fitness:   e.Fitness(candidate, pop),
-			}
+		for i := from; i < to; i++ {
+			evpop[i-from] = evaluate(pop[i], pop, e)
 		}
 	} else {
-		evpop = make(Population, len(pop))
-
 		var w sync.WaitGroup
-		w.Add(len(pop))
+		w.Add(to - from)
 
-		for i := range pop {
+		for i := from; i < to; i++ {
 			go func(i int) {
-				ind := &Individual{
-					Candidate: pop[i],
-					Fitness:   e.Fitness(pop[i], pop),
-				}
-				evpop[i] = ind
+				evpop[i-from] = evaluate(pop[i], pop, e)
 				w.Done()
 			}(i)
 		}
@@ -44,5 +46,27 @@ func EvaluatePopulation(pop []interface{}, e Evaluator, concurrent bool) Populat
 		// TODO: handle goroutine termination
 	}
 
+	if ne, ok := e.(NoisyEvaluator); ok {
+		for _, ind := range evpop {
+			ind.Samples, ind.CI = ne.Estimate(ind.Candidate)
+		}
+	}
+
 	return evpop
 }
+
+// evaluate evaluates a single candidate.
+func evaluate(cand interface{}, pop []interface{}, e Evaluator) *Individual {
+	if ve, ok := e.(VectorEvaluator); ok {
+		scores := ve.Scores(cand, pop)
+		return &Individual{
+			Candidate: cand,
+			Fitness:   scores[0],
+			Scores:    scores,
+		}
+	}
+	return &Individual{
+		Candidate: cand,
+		Fitness:   e.Fitness(cand, pop),
+	}
+}
@@ -1,5 +1,7 @@
 package evolve
 
+import "math"
+
 // Evaluator calculates the fitness score of a given candidate of the
 // appropriate type.
 //
@@ -60,6 +62,43 @@ type Evaluator interface {
 	IsNatural() bool
 }
 
+// A VectorEvaluator is an Evaluator computing a vector of scores for each
+// candidate, for example a primary objective followed by secondary objectives
+// used to break ties, or several objectives to be traded off.
+//
+// When a population is evaluated by a VectorEvaluator, Fitness is not called:
+// the scores are recorded in the Scores field of the individuals, and their
+// Fitness is set to the first score. Individuals are then ordered by the
+// Comparator of the engine (see engine.Compare), while statistics, termination
+// conditions and fitness-proportionate selection strategies keep using the
+// Fitness field. IsNatural still applies to the first score.
+//
+// Evaluator wrappers, such as FitnessCache, only forward the Fitness method,
+// the scores of a wrapped VectorEvaluator are thus lost.
+type VectorEvaluator interface {
+	Evaluator
+
+	// Scores calculates the vector of scores for the given candidate. pop is
+	// the entire population, as for Fitness. All scores must be non-negative
+	// and all candidates must have the same number of scores.
+	Scores(cand interface{}, pop []interface{}) []float64
+}
+
+// A NoisyEvaluator is an Evaluator whose fitness scores are estimates, computed
+// from repeated evaluations of a noisy, or stochastic, fitness function.
+//
+// When a population is evaluated by a NoisyEvaluator, the number of samples
+// and the confidence interval of every fitness estimate are recorded in the
+// Samples and CI fields of the individuals.
+type NoisyEvaluator interface {
+	Evaluator
+
+	// Estimate returns the number of samples from which the fitness of cand
+	// has been estimated, by the last call to Fitness, and the half-width of
+	// the confidence interval around that estimate.
+	Estimate(cand interface{}) (samples int, ci float64)
+}
+
 // FitnessFunc is the type of function computing the fitness of a candidate
 // solution.
 type FitnessFunc func(interface{}, []interface{}) float64
@@ -79,3 +118,52 @@ func (e evaluatorFunc) IsNatural() bool                                     { re
 func EvaluatorFunc(natural bool, f FitnessFunc) evaluatorFunc { // nolint: golint
 	return evaluatorFunc{f: f, n: natural}
 }
+
+// A CachingEvaluator is an Evaluator serving some fitness scores from a cache,
+// such as FitnessCache. The engine relies on the cache statistics to tell the
+// cache hits apart from the actual fitness evaluations.
+type CachingEvaluator interface {
+	Evaluator
+
+	// Stats returns the cache statistics.
+	Stats() CacheStats
+}
+
+// A GenerationalEvaluator is an Evaluator whose state follows the successive
+// generations, such as an evaluator pre-screening or re-sampling all the
+// candidates of a population at once.
+//
+// The engine calls StartGeneration before evaluating the candidates of each
+// generation, the initial population being generation 0. The candidates of a
+// generation may then be evaluated in several batches, each of them in the
+// context of the whole population. Outside of the engine, StartGeneration must
+// be called before every population is evaluated.
+type GenerationalEvaluator interface {
+	Evaluator
+
+	// StartGeneration notifies the evaluator that the candidates of the
+	// (zero-based) generation gen are about to be evaluated. Calling it again
+	// with the same generation has no effect.
+	StartGeneration(gen int)
+}
+
+// A ReportingEvaluator is an Evaluator reporting statistics about its
+// evaluations, such as the accuracy of a surrogate model. The engine reports
+// them, every generation, in the EvaluatorStats field of the population
+// stats.
+type ReportingEvaluator interface {
+	Evaluator
+
+	// EvaluatorStats returns the statistics, keyed by name, of the
+	// evaluations of the last generation.
+	EvaluatorStats() map[string]float64
+}
+
+// WorstFitness returns the worst possible fitness score, that is 0 for natural
+// scores, and math.MaxFloat64 for non-natural ones.
+func WorstFitness(natural bool) float64 {
+	if natural {
+		return 0
+	}
+	return math.MaxFloat64
+}
@@ -0,0 +1,313 @@
+// Package diskcache implements a fitness cache persisted on disk, so that
+// fitness scores computed during a run can be reused by the next ones.
+//
+// The cache is stored in a directory, as an append-only log of records, each
+// associating the canonical key of a candidate (see evolve.CandidateKey) to its
+// fitness. Every record is checksummed: if the process crashes while a record
+// is being written, the incomplete record is detected and discarded the next
+// time the cache is opened, leaving the cache in a consistent state.
+//
+// A cache directory must only be used for a single fitness function: changing
+// the fitness function requires a new directory. It can only be used by one
+// Cache at a time, which holds an exclusive lock on the directory until it's
+// closed: opening or compacting a cache that is already open, in this process
+// or another, fails with ErrLocked.
+package diskcache
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/arl/evolve"
+)
+
+// LogName is the name of the log file, in the cache directory.
+const LogName = "fitness.log"
+
+// LockName is the name of the lock file, in the cache directory.
+const LockName = "LOCK"
+
+// magic is the header of log files.
+const magic = "EVOLVE-FITNESS-LOG-1\n"
+
+// ErrCorrupted is the error returned when a log file has an invalid header.
+var ErrCorrupted = errors.New("diskcache: corrupted log file")
+
+// ErrLocked is the error returned when opening or compacting a cache that is
+// already open.
+var ErrLocked = errors.New("diskcache: cache is locked by another user")
+
+// Cache is an evolve.Evaluator caching the fitness scores computed by a
+// wrapped evaluator in a directory. It is safe for concurrent use by multiple
+// goroutines, such as those of evolve.EvaluatePopulation.
+//
+// The same rules as for evolve.FitnessCache apply: fitness evaluations must be
+// isolated and repeatable.
+type Cache struct {
+	wrapped evolve.Evaluator
+	key     func(cand interface{}) (string, bool)
+	sync    bool
+
+	mu     sync.Mutex
+	f      *os.File
+	unlock func() error
+	index  map[string]float64
+	stats  evolve.CacheStats
+	buf    []byte
+	closed bool
+}
+
+// Open opens, or creates, the cache stored in dir, and returns a Cache
+// evaluating candidates missing from it with wrapped.
+//
+// If the log file ends with an incomplete or corrupted record, as may happen
+// after a crash, the log is truncated to its last valid record.
+//
+// Open returns ErrLocked if the cache is already open. The returned cache must
+// be closed with Close, which releases the lock.
+func Open(dir string, wrapped evolve.Evaluator, options ...func(*Cache) error) (*Cache, error) {
+	c := &Cache{
+		wrapped: wrapped,
+		key:     evolve.CandidateKey,
+		index:   make(map[string]float64),
+	}
+	for _, opt := range options {
+		if err := opt(c); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("diskcache: %v", err)
+	}
+	unlock, err := lock(dir)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.open(dir); err != nil {
+		unlock()
+		return nil, err
+	}
+	c.unlock = unlock
+	return c, nil
+}
+
+// open opens the log in dir and loads its records.
+func (c *Cache) open(dir string) error {
+	f, err := os.OpenFile(filepath.Join(dir, LogName), os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return fmt.Errorf("diskcache: %v", err)
+	}
+
+	valid, err := load(f, func(key string, fitness float64) { c.index[key] = fitness })
+	if err != nil {
+		f.Close()
+		return err
+	}
+	if valid == 0 {
+		// New log file, or not even a complete header.
+		if err := f.Truncate(0); err != nil {
+			f.Close()
+			return fmt.Errorf("diskcache: %v", err)
+		}
+		if _, err := f.WriteAt([]byte(magic), 0); err != nil {
+			f.Close()
+			return fmt.Errorf("diskcache: %v", err)
+		}
+		valid = int64(len(magic))
+	} else if err := f.Truncate(valid); err != nil {
+		f.Close()
+		return fmt.Errorf("diskcache: can't truncate log: %v", err)
+	}
+	if _, err := f.Seek(valid, io.SeekStart); err != nil {
+		f.Close()
+		return fmt.Errorf("diskcache: %v", err)
+	}
+
+	c.f = f
+	return nil
+}
+
+// Key sets the function returning the canonical key of a candidate. Keys must
+// not depend on the process, since they're persisted. The default is
+// evolve.CandidateKey.
+func Key(key func(cand interface{}) (string, bool)) func(*Cache) error {
+	return func(c *Cache) error {
+		c.key = key
+		return nil
+	}
+}
+
+// Sync makes the cache flush every new record to stable storage before
+// returning the fitness score. This is slower but guarantees that no fitness
+// evaluation is lost in case of power failure. Without it, the log is only
+// synced on Close.
+func Sync() func(*Cache) error {
+	return func(c *Cache) error {
+		c.sync = true
+		return nil
+	}
+}
+
+// Fitness returns the cached fitness of cand if there's one, or evaluates it
+// with the wrapped evaluator and appends the result to the log.
+//
+// Fitness panics if cand can't be keyed or if the record can't be written.
+func (c *Cache) Fitness(cand interface{}, pop []interface{}) float64 {
+	key, ok := c.key(cand)
+	if !ok {
+		panic(fmt.Sprintf("diskcache: can't key candidates of type %T, implement evolve.Keyer or use the Key option", cand))
+	}
+
+	c.mu.Lock()
+	if fitness, ok := c.index[key]; ok {
+		c.stats.Hits++
+		c.mu.Unlock()
+		return fitness
+	}
+	c.stats.Misses++
+	c.mu.Unlock()
+
+	fitness := c.wrapped.Fitness(cand, pop)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.index[key]; ok {
+		// The same candidate has been evaluated concurrently.
+		return fitness
+	}
+	if err := c.append(key, fitness); err != nil {
+		panic(err)
+	}
+	c.index[key] = fitness
+	return fitness
+}
+
+// append writes a record to the log. c.mu must be held.
+func (c *Cache) append(key string, fitness float64) error {
+	if c.closed {
+		return errors.New("diskcache: cache is closed")
+	}
+	c.buf = appendRecord(c.buf[:0], key, fitness)
+	if _, err := c.f.Write(c.buf); err != nil {
+		return fmt.Errorf("diskcache: can't write record: %v", err)
+	}
+	if c.sync {
+		if err := c.f.Sync(); err != nil {
+			return fmt.Errorf("diskcache: can't sync log: %v", err)
+		}
+	}
+	return nil
+}
+
+// IsNatural specifies whether this evaluator generates 'natural' fitness
+// scores or not.
+func (c *Cache) IsNatural() bool { return c.wrapped.IsNatural() }
+
+// Len returns the number of fitness scores in the cache.
+func (c *Cache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.index)
+}
+
+// Stats returns the cache statistics for the current process.
+func (c *Cache) Stats() evolve.CacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	stats := c.stats
+	stats.Len = len(c.index)
+	return stats
+}
+
+// Close syncs the log to stable storage and closes it.
+func (c *Cache) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		return nil
+	}
+	c.closed = true
+	defer c.unlock()
+	if err := c.f.Sync(); err != nil {
+		c.f.Close()
+		return fmt.Errorf("diskcache: %v", err)
+	}
+	return c.f.Close()
+}
+
+// A record is made of the uvarint length of the key, the key, the fitness as a
+// little endian float64 and the CRC-32 (IEEE) of all of the above, as a little
+// endian uint32.
+func appendRecord(b []byte, key string, fitness float64) []byte {
+	start := len(b)
+	var tmp [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(tmp[:], uint64(len(key)))
+	b = append(b, tmp[:n]...)
+	b = append(b, key...)
+	binary.LittleEndian.PutUint64(tmp[:8], math.Float64bits(fitness))
+	b = append(b, tmp[:8]...)
+	binary.LittleEndian.PutUint32(tmp[:4], crc32.ChecksumIEEE(b[start:]))
+	return append(b, tmp[:4]...)
+}
+
+// maxKeySize is the maximum size of a key, larger key lengths are considered
+// as corruption.
+const maxKeySize = 1 << 28
+
+// load reads the log from the start and calls fn for each valid record. It
+// returns the offset following the last valid record, or 0 if the log header
+// is missing or incomplete.
+func load(r io.Reader, fn func(key string, fitness float64)) (int64, error) {
+	br := bufio.NewReader(r)
+
+	hdr := make([]byte, len(magic))
+	if n, err := io.ReadFull(br, hdr); err != nil {
+		if bytes.HasPrefix([]byte(magic), hdr[:n]) {
+			return 0, nil
+		}
+		return 0, ErrCorrupted
+	}
+	if string(hdr) != magic {
+		return 0, ErrCorrupted
+	}
+
+	off := int64(len(magic))
+	var rec []byte
+	for {
+		klen, err := binary.ReadUvarint(br)
+		if err != nil || klen > maxKeySize {
+			return off, nil
+		}
+		var tmp [binary.MaxVarintLen64]byte
+		n := binary.PutUvarint(tmp[:], klen)
+
+		size := n + int(klen) + 8 + 4
+		if cap(rec) < size {
+			rec = make([]byte, size)
+		}
+		rec = rec[:size]
+		copy(rec, tmp[:n])
+		if _, err := io.ReadFull(br, rec[n:]); err != nil {
+			return off, nil
+		}
+
+		body := rec[:size-4]
+		if crc32.ChecksumIEEE(body) != binary.LittleEndian.Uint32(rec[size-4:]) {
+			return off, nil
+		}
+		key := string(body[n : n+int(klen)])
+		fitness := math.Float64frombits(binary.LittleEndian.Uint64(body[n+int(klen):]))
+		fn(key, fitness)
+		off += int64(size)
+	}
+}
@@ -0,0 +1,191 @@
+package diskcache
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/arl/evolve"
+)
+
+// lenEvaluator evaluates string candidates to their length and counts its
+// evaluations.
+type lenEvaluator struct{ count int64 }
+
+func (e *lenEvaluator) Fitness(cand interface{}, _ []interface{}) float64 {
+	atomic.AddInt64(&e.count, 1)
+	return float64(len(cand.(string)))
+}
+
+func (*lenEvaluator) IsNatural() bool { return false }
+
+func TestCachePersistence(t *testing.T) {
+	dir := t.TempDir()
+
+	eval := &lenEvaluator{}
+	c, err := Open(dir, eval)
+	require.NoError(t, err)
+	assert.False(t, c.IsNatural())
+	assert.Equal(t, 3.0, c.Fitness("abc", nil))
+	assert.Equal(t, 3.0, c.Fitness("abc", nil))
+	assert.Equal(t, 1.0, c.Fitness("a", nil))
+	assert.Equal(t, evolve.CacheStats{Hits: 1, Misses: 2, Len: 2}, c.Stats())
+	require.NoError(t, c.Close())
+
+	// Reopen the cache, previously evaluated candidates shouldn't be evaluated
+	// again.
+	eval = &lenEvaluator{}
+	c, err = Open(dir, eval)
+	require.NoError(t, err)
+	defer c.Close()
+	assert.Equal(t, 2, c.Len())
+	assert.Equal(t, 3.0, c.Fitness("abc", nil))
+	assert.Equal(t, 1.0, c.Fitness("a", nil))
+	assert.Equal(t, 2.0, c.Fitness("ab", nil))
+	assert.Equal(t, int64(1), eval.count)
+}
+
+func TestCacheConcurrent(t *testing.T) {
+	dir := t.TempDir()
+
+	eval := &lenEvaluator{}
+	c, err := Open(dir, eval)
+	require.NoError(t, err)
+
+	pop := make([]interface{}, 200)
+	for i := range pop {
+		pop[i] = strings.Repeat("x", i%50)
+	}
+	evpop := evolve.EvaluatePopulation(pop, c, true)
+	for i, ind := range evpop {
+		assert.Equal(t, float64(i%50), ind.Fitness)
+	}
+	require.NoError(t, c.Close())
+
+	info, err := Inspect(dir)
+	require.NoError(t, err)
+	assert.Equal(t, 50, info.Keys)
+	assert.Equal(t, 50, info.Records)
+	assert.Zero(t, info.Garbage)
+}
+
+func TestCacheCrashRecovery(t *testing.T) {
+	dir := t.TempDir()
+
+	c, err := Open(dir, &lenEvaluator{})
+	require.NoError(t, err)
+	c.Fitness("abc", nil)
+	c.Fitness("abcd", nil)
+	require.NoError(t, c.Close())
+
+	// Simulate a crash while writing a record: append an incomplete record.
+	path := filepath.Join(dir, LogName)
+	valid, err := os.Stat(path)
+	require.NoError(t, err)
+	torn := appendRecord(nil, "sabcde", 5)
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0)
+	require.NoError(t, err)
+	_, err = f.Write(torn[:len(torn)-3])
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	info, err := Inspect(dir)
+	require.NoError(t, err)
+	assert.Equal(t, 2, info.Records)
+	assert.Equal(t, int64(len(torn)-3), info.Garbage)
+
+	// Reopening truncates the log to its last valid record.
+	eval := &lenEvaluator{}
+	c, err = Open(dir, eval)
+	require.NoError(t, err)
+	assert.Equal(t, 2, c.Len())
+	fi, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, valid.Size(), fi.Size())
+
+	// New records are appended after the last valid one.
+	assert.Equal(t, 5.0, c.Fitness("abcde", nil))
+	require.NoError(t, c.Close())
+
+	info, err = Inspect(dir)
+	require.NoError(t, err)
+	assert.Equal(t, 3, info.Records)
+	assert.Zero(t, info.Garbage)
+}
+
+func TestCacheCorruptedHeader(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, LogName), []byte("not a fitness log"), 0o644))
+
+	_, err := Open(dir, &lenEvaluator{})
+	assert.ErrorIs(t, err, ErrCorrupted)
+}
+
+func TestCompact(t *testing.T) {
+	dir := t.TempDir()
+
+	// Write a log with duplicated keys and trailing garbage.
+	buf := []byte(magic)
+	buf = appendRecord(buf, "a", 1)
+	buf = appendRecord(buf, "b", 2)
+	buf = appendRecord(buf, "a", 3)
+	buf = append(buf, 0x05, 'x')
+	require.NoError(t, os.WriteFile(filepath.Join(dir, LogName), buf, 0o644))
+
+	info, err := Inspect(dir)
+	require.NoError(t, err)
+	assert.Equal(t, 3, info.Records)
+	assert.Equal(t, 2, info.Keys)
+	assert.Equal(t, int64(2), info.Garbage)
+
+	require.NoError(t, Compact(dir))
+
+	info, err = Inspect(dir)
+	require.NoError(t, err)
+	assert.Equal(t, 2, info.Records)
+	assert.Equal(t, 2, info.Keys)
+	assert.Zero(t, info.Garbage)
+
+	got := make(map[string]float64)
+	require.NoError(t, Range(dir, func(key string, fitness float64) bool {
+		got[key] = fitness
+		return true
+	}))
+	assert.Equal(t, map[string]float64{"a": 3, "b": 2}, got)
+
+	// No temporary file should remain.
+	tmps, err := filepath.Glob(filepath.Join(dir, "*.tmp"))
+	require.NoError(t, err)
+	assert.Empty(t, tmps)
+}
+
+func TestCacheLock(t *testing.T) {
+	dir := t.TempDir()
+
+	c, err := Open(dir, &lenEvaluator{})
+	require.NoError(t, err)
+
+	_, err = Open(dir, &lenEvaluator{})
+	assert.ErrorIs(t, err, ErrLocked)
+	assert.ErrorIs(t, Compact(dir), ErrLocked)
+
+	// Closing the cache releases the lock.
+	require.NoError(t, c.Close())
+	require.NoError(t, Compact(dir))
+	c, err = Open(dir, &lenEvaluator{})
+	require.NoError(t, err)
+	require.NoError(t, c.Close())
+}
+
+func TestCacheUnkeyable(t *testing.T) {
+	c, err := Open(t.TempDir(), evolve.ZeroEvaluator{})
+	require.NoError(t, err)
+	defer c.Close()
+
+	assert.Panics(t, func() { c.Fitness(42, nil) })
+}
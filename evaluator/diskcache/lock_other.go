@@ -0,0 +1,31 @@
+//go:build !darwin && !dragonfly && !freebsd && !linux && !netbsd && !openbsd
+// +build !darwin,!dragonfly,!freebsd,!linux,!netbsd,!openbsd
+
+package diskcache
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// lock takes an exclusive lock on the cache stored in dir, and returns the
+// function releasing it.
+//
+// On this platform, the lock is the existence of the lock file: if the process
+// holding it crashes, the lock file must be removed by hand.
+func lock(dir string) (unlock func() error, err error) {
+	name := filepath.Join(dir, LockName)
+	f, err := os.OpenFile(name, os.O_RDWR|os.O_CREATE|os.O_EXCL, 0o644)
+	if err != nil {
+		if os.IsExist(err) {
+			return nil, fmt.Errorf("%w: %s", ErrLocked, dir)
+		}
+		return nil, fmt.Errorf("diskcache: %v", err)
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(name)
+		return nil, fmt.Errorf("diskcache: %v", err)
+	}
+	return func() error { return os.Remove(name) }, nil
+}
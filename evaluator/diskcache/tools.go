@@ -0,0 +1,126 @@
+package diskcache
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// Info describes the content of a cache directory.
+type Info struct {
+	Size    int64 // size of the log file, in bytes
+	Records int   // number of valid records in the log
+	Keys    int   // number of distinct keys in the log
+	Garbage int64 // number of bytes following the last valid record
+}
+
+// Inspect returns information about the cache stored in dir. The log is not
+// modified, even if it ends with invalid records.
+func Inspect(dir string) (*Info, error) {
+	var info Info
+	keys := make(map[string]struct{})
+	valid, err := readLog(dir, func(key string, _ float64) {
+		info.Records++
+		keys[key] = struct{}{}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	fi, err := os.Stat(filepath.Join(dir, LogName))
+	if err != nil {
+		return nil, fmt.Errorf("diskcache: %v", err)
+	}
+	info.Size = fi.Size()
+	info.Keys = len(keys)
+	info.Garbage = info.Size - valid
+	return &info, nil
+}
+
+// Range calls fn for each valid record of the log stored in dir, in the order
+// in which they were written. Range stops if fn returns false.
+func Range(dir string, fn func(key string, fitness float64) bool) error {
+	stop := false
+	_, err := readLog(dir, func(key string, fitness float64) {
+		if !stop && !fn(key, fitness) {
+			stop = true
+		}
+	})
+	return err
+}
+
+// Compact rewrites the log stored in dir so that it only contains one record
+// per key and no invalid records. Compact returns ErrLocked if the cache is
+// open.
+//
+// The compacted log is first written to a temporary file, which then
+// atomically replaces the log, so that a crash during compaction leaves the
+// original log untouched.
+func Compact(dir string) error {
+	unlock, err := lock(dir)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	var order []string
+	index := make(map[string]float64)
+	if _, err := readLog(dir, func(key string, fitness float64) {
+		if _, ok := index[key]; !ok {
+			order = append(order, key)
+		}
+		index[key] = fitness
+	}); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(dir, LogName+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("diskcache: %v", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	buf := []byte(magic)
+	for _, key := range order {
+		buf = appendRecord(buf, key, index[key])
+		if len(buf) >= 1<<20 {
+			if _, err := tmp.Write(buf); err != nil {
+				tmp.Close()
+				return fmt.Errorf("diskcache: %v", err)
+			}
+			buf = buf[:0]
+		}
+	}
+	if _, err := tmp.Write(buf); err != nil {
+		tmp.Close()
+		return fmt.Errorf("diskcache: %v", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("diskcache: %v", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("diskcache: %v", err)
+	}
+	if err := os.Rename(tmp.Name(), filepath.Join(dir, LogName)); err != nil {
+		return fmt.Errorf("diskcache: %v", err)
+	}
+
+	// Sync the directory so that the rename is durable.
+	if d, err := os.Open(dir); err == nil {
+		d.Sync()
+		d.Close()
+	}
+	return nil
+}
+
+// readLog reads the log stored in dir, calling fn for each valid record, and
+// returns the offset following the last valid one.
+func readLog(dir string, fn func(key string, fitness float64)) (int64, error) {
+	f, err := os.Open(filepath.Join(dir, LogName))
+	if err != nil {
+		return 0, fmt.Errorf("diskcache: %v", err)
+	}
+	defer f.Close()
+	return load(f, fn)
+}
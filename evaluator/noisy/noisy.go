@@ -0,0 +1,449 @@
+// Package noisy implements the evaluation of noisy fitness functions.
+//
+// When the fitness function is stochastic, for example a simulation run with a
+// random seed, a single evaluation gives a poor estimate of the fitness of a
+// candidate, and lucky candidates, such as elites, may keep an overestimated
+// fitness for many generations. The noisy Evaluator estimates the fitness of a
+// candidate by the mean of repeated evaluations, the samples.
+//
+// Every generation, each new candidate is sampled a fixed number of times.
+// Then additional samples are allocated by racing: the candidates competing for
+// the top ranks of the population, whose confidence intervals overlap, are
+// sampled again until they can be told apart, or the sampling budget is
+// exhausted. Candidates surviving from a generation to the next one, elites for
+// example, keep their samples and are sampled again, so that their fitness
+// estimate becomes more accurate over time.
+//
+// The Evaluator is an evolve.GenerationalEvaluator: the engine notifies it of
+// every new generation. When it's used outside of the engine, StartGeneration
+// must be called before each population is evaluated.
+package noisy
+
+import (
+	"errors"
+	"math"
+	"runtime"
+	"sort"
+	"sync"
+
+	"github.com/arl/evolve"
+)
+
+// Evaluator is an evolve.NoisyEvaluator estimating the fitness of the
+// candidates from repeated evaluations by the wrapped evaluator. It is safe
+// for concurrent use by multiple goroutines.
+//
+// The fitness of a candidate is the mean of its samples and its confidence
+// interval is computed with the normal approximation, z·s/√n, where s is the
+// sample standard deviation and n the number of samples. The confidence
+// interval of an estimate made of a single sample is infinite.
+type Evaluator struct {
+	wrapped evolve.Evaluator
+	key     func(interface{}) interface{}
+
+	samples    int     // initial samples per candidate
+	resample   int     // new samples per surviving candidate
+	maxSamples int     // samples per candidate, 0 means no limit
+	budget     float64 // racing samples per generation, per candidate
+	top        int     // number of top ranks to race for
+	z          float64 // confidence interval z-score
+	workers    int
+
+	mu    sync.Mutex
+	gen   *generation
+	ests  map[interface{}]*estimate // estimates of the current generation
+	total int64
+}
+
+// generation holds the sampling state of a generation.
+type generation struct {
+	num  int
+	once sync.Once
+}
+
+// estimate accumulates the samples of a candidate fitness.
+type estimate struct {
+	cand interface{}
+	n    int
+	mean float64
+	m2   float64 // sum of squared differences from the mean
+}
+
+// add adds a sample, using Welford's algorithm.
+func (est *estimate) add(x float64) {
+	est.n++
+	d := x - est.mean
+	est.mean += d / float64(est.n)
+	est.m2 += d * (x - est.mean)
+}
+
+// New returns a noisy Evaluator estimating the fitness scores computed by
+// wrapped.
+//
+// By default, every candidate is sampled 3 times, then candidates surviving to
+// the next generation are sampled once more, the racing budget is 1 sample per
+// candidate and per generation, races are run for the best rank only, at a
+// confidence level of 95%. Sampling is performed by GOMAXPROCS goroutines.
+func New(wrapped evolve.Evaluator, options ...func(*Evaluator) error) (*Evaluator, error) {
+	e := &Evaluator{
+		wrapped:  wrapped,
+		key:      defaultKey,
+		samples:  3,
+		resample: 1,
+		budget:   1,
+		top:      1,
+		workers:  runtime.GOMAXPROCS(0),
+		ests:     make(map[interface{}]*estimate),
+	}
+	if err := Confidence(0.95)(e); err != nil {
+		return nil, err
+	}
+	for _, opt := range options {
+		if err := opt(e); err != nil {
+			return nil, err
+		}
+	}
+	if e.maxSamples > 0 && e.maxSamples < e.samples {
+		return nil, errors.New("noisy: max samples lower than initial samples")
+	}
+	return e, nil
+}
+
+func defaultKey(cand interface{}) interface{} {
+	if key, ok := evolve.CandidateKey(cand); ok {
+		return key
+	}
+	return cand
+}
+
+// Samples sets the number of samples initially taken for every new candidate.
+func Samples(n int) func(*Evaluator) error {
+	return func(e *Evaluator) error {
+		if n < 1 {
+			return errors.New("noisy: samples must be strictly positive")
+		}
+		e.samples = n
+		return nil
+	}
+}
+
+// Resample sets the number of new samples taken, in every generation, for the
+// candidates that were already in the previous generation. If n is 0,
+// surviving candidates are only sampled again by racing.
+func Resample(n int) func(*Evaluator) error {
+	return func(e *Evaluator) error {
+		if n < 0 {
+			return errors.New("noisy: resample must be positive")
+		}
+		e.resample = n
+		return nil
+	}
+}
+
+// MaxSamples sets the maximum number of samples per candidate. Once reached, a
+// candidate is not sampled anymore, neither by racing nor when it survives to
+// the next generation. If n is 0, which is the default, the number of samples
+// is not limited.
+func MaxSamples(n int) func(*Evaluator) error {
+	return func(e *Evaluator) error {
+		if n < 0 {
+			return errors.New("noisy: max samples must be positive")
+		}
+		e.maxSamples = n
+		return nil
+	}
+}
+
+// Budget sets the racing budget, that is the maximum number of additional
+// samples allocated by racing in each generation, expressed in samples per
+// candidate of the population. A budget of 0 disables racing.
+func Budget(perCand float64) func(*Evaluator) error {
+	return func(e *Evaluator) error {
+		if perCand < 0 {
+			return errors.New("noisy: budget must be positive")
+		}
+		e.budget = perCand
+		return nil
+	}
+}
+
+// Top sets the number of top ranks candidates race for. Racing stops when the
+// confidence intervals of the m best candidates don't overlap those of the
+// other candidates anymore. It is usually set to the number of elites, or to 1
+// to focus on the best candidate.
+func Top(m int) func(*Evaluator) error {
+	return func(e *Evaluator) error {
+		if m < 1 {
+			return errors.New("noisy: top must be strictly positive")
+		}
+		e.top = m
+		return nil
+	}
+}
+
+// Confidence sets the confidence level of the confidence intervals, in (0, 1).
+func Confidence(level float64) func(*Evaluator) error {
+	return func(e *Evaluator) error {
+		if level <= 0 || level >= 1 {
+			return errors.New("noisy: confidence level must be in (0, 1)")
+		}
+		e.z = math.Sqrt2 * math.Erfinv(level)
+		return nil
+	}
+}
+
+// Workers sets the number of goroutines sampling the fitness function. In
+// order to reproduce a run with a seeded, but stateful, fitness function,
+// sampling must be performed by a single worker.
+func Workers(n int) func(*Evaluator) error {
+	return func(e *Evaluator) error {
+		if n < 1 {
+			return errors.New("noisy: workers must be strictly positive")
+		}
+		e.workers = n
+		return nil
+	}
+}
+
+// Key sets the function returning the key identifying a candidate across
+// generations. Keys must be comparable. By default, candidates are keyed by
+// the encoding of their content (see evolve.CandidateKey) or, if it can't be
+// encoded, by themselves.
+func Key(key func(cand interface{}) interface{}) func(*Evaluator) error {
+	return func(e *Evaluator) error {
+		e.key = key
+		return nil
+	}
+}
+
+// Fitness returns the estimated fitness of cand, the mean of its samples.
+//
+// The first call in a generation triggers the sampling of all the candidates
+// of pop, which must thus be the whole population being evaluated, as it is
+// when Fitness is called by evolve.EvaluatePopulation. If StartGeneration has
+// not been called, the candidates are considered to be part of generation 0.
+func (e *Evaluator) Fitness(cand interface{}, pop []interface{}) float64 {
+	e.mu.Lock()
+	if e.gen == nil {
+		e.gen = &generation{}
+	}
+	gen := e.gen
+	e.mu.Unlock()
+
+	gen.once.Do(func() { e.sample(pop) })
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	est, ok := e.ests[e.key(cand)]
+	if !ok {
+		// cand is not in pop.
+		est = &estimate{cand: cand}
+		for i := 0; i < e.samples; i++ {
+			est.add(e.wrapped.Fitness(cand, pop))
+		}
+		e.total += int64(e.samples)
+	}
+	return est.mean
+}
+
+// StartGeneration implements evolve.GenerationalEvaluator. The next evaluated
+// population gets sampled, its candidates that were part of the previous
+// generation keeping their samples.
+func (e *Evaluator) StartGeneration(gen int) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.gen != nil && e.gen.num == gen {
+		return
+	}
+	e.gen = &generation{num: gen}
+}
+
+// Estimate implements evolve.NoisyEvaluator.
+func (e *Evaluator) Estimate(cand interface{}) (samples int, ci float64) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	est, ok := e.ests[e.key(cand)]
+	if !ok {
+		return 0, math.Inf(1)
+	}
+	return est.n, e.ci(est)
+}
+
+// ci returns the half-width of the confidence interval of est.
+func (e *Evaluator) ci(est *estimate) float64 {
+	if est.n < 2 {
+		return math.Inf(1)
+	}
+	return e.z * math.Sqrt(est.m2/float64(est.n-1)/float64(est.n))
+}
+
+// Evaluations returns the total number of calls made to the wrapped evaluator.
+func (e *Evaluator) Evaluations() int64 {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.total
+}
+
+// IsNatural specifies whether this evaluator generates 'natural' fitness
+// scores or not.
+func (e *Evaluator) IsNatural() bool { return e.wrapped.IsNatural() }
+
+// job asks for n samples of est.
+type job struct {
+	est *estimate
+	n   int
+}
+
+// sample samples all candidates of pop, then races them.
+func (e *Evaluator) sample(pop []interface{}) {
+	e.mu.Lock()
+	prev := e.ests
+	ests := make(map[interface{}]*estimate, len(pop))
+	list := make([]*estimate, 0, len(pop)) // estimates in population order
+	var jobs []job
+	for _, cand := range pop {
+		key := e.key(cand)
+		if _, ok := ests[key]; ok {
+			continue
+		}
+		est, survived := prev[key]
+		n := e.resample
+		if !survived {
+			est, n = &estimate{cand: cand}, e.samples
+		}
+		ests[key] = est
+		list = append(list, est)
+		if n = e.limit(est, n); n > 0 {
+			jobs = append(jobs, job{est: est, n: n})
+		}
+	}
+	e.mu.Unlock()
+
+	e.run(jobs, pop)
+
+	// Race for the top ranks.
+	budget := int(math.Round(e.budget * float64(len(pop))))
+	for budget > 0 {
+		jobs = e.contenders(list)
+		if len(jobs) > budget {
+			jobs = jobs[:budget]
+		}
+		if len(jobs) == 0 {
+			break
+		}
+		budget -= len(jobs)
+		e.run(jobs, pop)
+	}
+
+	e.mu.Lock()
+	e.ests = ests
+	e.mu.Unlock()
+}
+
+// limit returns the number of samples, at most n, that can still be taken for
+// est.
+func (e *Evaluator) limit(est *estimate, n int) int {
+	if e.maxSamples > 0 && est.n+n > e.maxSamples {
+		return e.maxSamples - est.n
+	}
+	return n
+}
+
+// contenders returns a job for each candidate whose confidence interval
+// overlaps the boundary between the top ranks and the rest of the population,
+// most uncertain candidates first.
+func (e *Evaluator) contenders(list []*estimate) []job {
+	if len(list) <= e.top {
+		return nil
+	}
+
+	natural := e.wrapped.IsNatural()
+	better := func(a, b float64) bool {
+		if natural {
+			return a > b
+		}
+		return a < b
+	}
+	sort.SliceStable(list, func(i, j int) bool { return better(list[i].mean, list[j].mean) })
+
+	// The worst bound of the top candidates, and the best bound of the others.
+	ci := make([]float64, len(list))
+	for i, est := range list {
+		ci[i] = e.ci(est)
+	}
+	sign := 1.0
+	if !natural {
+		sign = -1
+	}
+	worstTop := math.Inf(1)
+	for i := 0; i < e.top; i++ {
+		worstTop = math.Min(worstTop, sign*list[i].mean-ci[i])
+	}
+	bestRest := math.Inf(-1)
+	for i := e.top; i < len(list); i++ {
+		bestRest = math.Max(bestRest, sign*list[i].mean+ci[i])
+	}
+
+	var jobs []job
+	var ord []float64
+	for i, est := range list {
+		top := i < e.top
+		if top && sign*est.mean-ci[i] > bestRest || !top && sign*est.mean+ci[i] < worstTop {
+			continue
+		}
+		if e.limit(est, 1) == 0 {
+			continue
+		}
+		jobs = append(jobs, job{est: est, n: 1})
+		ord = append(ord, ci[i])
+	}
+	sort.Sort(byCI{jobs, ord})
+	return jobs
+}
+
+type byCI struct {
+	jobs []job
+	ci   []float64
+}
+
+func (s byCI) Len() int           { return len(s.jobs) }
+func (s byCI) Less(i, j int) bool { return s.ci[i] > s.ci[j] }
+func (s byCI) Swap(i, j int) {
+	s.jobs[i], s.jobs[j] = s.jobs[j], s.jobs[i]
+	s.ci[i], s.ci[j] = s.ci[j], s.ci[i]
+}
+
+// run performs the sampling jobs concurrently.
+func (e *Evaluator) run(jobs []job, pop []interface{}) {
+	nworkers := e.workers
+	if nworkers > len(jobs) {
+		nworkers = len(jobs)
+	}
+
+	idx := make(chan int)
+	var wg sync.WaitGroup
+	wg.Add(nworkers)
+	for w := 0; w < nworkers; w++ {
+		go func() {
+			defer wg.Done()
+			for i := range idx {
+				j := jobs[i]
+				xs := make([]float64, j.n)
+				for k := range xs {
+					xs[k] = e.wrapped.Fitness(j.est.cand, pop)
+				}
+				e.mu.Lock()
+				for _, x := range xs {
+					j.est.add(x)
+				}
+				e.total += int64(j.n)
+				e.mu.Unlock()
+			}
+		}()
+	}
+	for i := range jobs {
+		idx <- i
+	}
+	close(idx)
+	wg.Wait()
+}
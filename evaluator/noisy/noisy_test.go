@@ -0,0 +1,121 @@
+package noisy
+
+import (
+	"math"
+	"math/rand"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/arl/evolve"
+)
+
+// gaussian returns the candidate, a float64, plus some gaussian noise.
+type gaussian struct {
+	mu     sync.Mutex
+	rng    *rand.Rand
+	stddev float64
+}
+
+func (g *gaussian) Fitness(cand interface{}, _ []interface{}) float64 {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return math.Max(0, cand.(float64)+g.rng.NormFloat64()*g.stddev)
+}
+
+func (*gaussian) IsNatural() bool { return true }
+
+func newGaussian(stddev float64) *gaussian {
+	return &gaussian{rng: rand.New(rand.NewSource(99)), stddev: stddev}
+}
+
+func samplesOf(pop evolve.Population) map[float64]int {
+	m := make(map[float64]int)
+	for _, ind := range pop {
+		m[ind.Candidate.(float64)] = ind.Samples
+	}
+	return m
+}
+
+func TestEvaluatorSamples(t *testing.T) {
+	eval, err := New(newGaussian(1), Samples(4), Budget(0))
+	require.NoError(t, err)
+
+	pop := []interface{}{10.0, 20.0, 30.0, 40.0}
+	eval.StartGeneration(0)
+	evpop := evolve.EvaluatePopulation(pop, eval, true)
+	for _, ind := range evpop {
+		assert.Equal(t, 4, ind.Samples)
+		assert.False(t, math.IsInf(ind.CI, 1))
+		assert.Greater(t, ind.CI, 0.0)
+		assert.InDelta(t, ind.Candidate.(float64), ind.Fitness, 3)
+	}
+	assert.Equal(t, int64(16), eval.Evaluations())
+
+	// Surviving candidates are sampled once more.
+	pop = []interface{}{30.0, 40.0, 50.0, 60.0}
+	eval.StartGeneration(1)
+	evpop = evolve.EvaluatePopulation(pop, eval, true)
+	assert.Equal(t, map[float64]int{30: 5, 40: 5, 50: 4, 60: 4}, samplesOf(evpop))
+	assert.Equal(t, int64(26), eval.Evaluations())
+
+	// The population is evaluated again in the same generation, no sampling.
+	evpop = evolve.EvaluatePopulation(pop, eval, true)
+	assert.Equal(t, map[float64]int{30: 5, 40: 5, 50: 4, 60: 4}, samplesOf(evpop))
+	assert.Equal(t, int64(26), eval.Evaluations())
+
+	// The same slice, in the next generation, is sampled again.
+	eval.StartGeneration(2)
+	evpop = evolve.EvaluatePopulation(pop, eval, true)
+	assert.Equal(t, map[float64]int{30: 6, 40: 6, 50: 5, 60: 5}, samplesOf(evpop))
+	assert.Equal(t, int64(30), eval.Evaluations())
+}
+
+func TestEvaluatorRacing(t *testing.T) {
+	eval, err := New(newGaussian(0.5), Samples(3), Budget(20), MaxSamples(50))
+	require.NoError(t, err)
+
+	// The 2 best candidates are close competitors, the others are far behind.
+	pop := []interface{}{10.0, 9.9, 1.0, 2.0, 3.0}
+	evpop := evolve.EvaluatePopulation(pop, eval, false)
+	samples := samplesOf(evpop)
+	assert.Equal(t, 3, samples[1])
+	assert.Equal(t, 3, samples[2])
+	assert.Equal(t, 3, samples[3])
+	assert.Greater(t, samples[10], 20)
+	assert.Greater(t, samples[9.9], 20)
+	assert.LessOrEqual(t, samples[10], 50)
+	assert.LessOrEqual(t, samples[9.9], 50)
+}
+
+func TestEvaluatorReproducible(t *testing.T) {
+	run := func() evolve.Population {
+		eval, err := New(newGaussian(1), Workers(1))
+		require.NoError(t, err)
+		pop := []interface{}{1.0, 1.5, 2.0, 2.5, 3.0}
+		eval.StartGeneration(0)
+		evolve.EvaluatePopulation(pop, eval, false)
+		eval.StartGeneration(1)
+		return evolve.EvaluatePopulation(pop[1:], eval, false)
+	}
+	assert.Equal(t, run(), run())
+}
+
+func TestNewErrors(t *testing.T) {
+	opts := [][]func(*Evaluator) error{
+		{Samples(0)},
+		{Resample(-1)},
+		{MaxSamples(-1)},
+		{Budget(-1)},
+		{Top(0)},
+		{Confidence(1)},
+		{Workers(0)},
+		{Samples(5), MaxSamples(4)},
+	}
+	for _, o := range opts {
+		_, err := New(newGaussian(1), o...)
+		assert.Error(t, err)
+	}
+}
@@ -0,0 +1,89 @@
+package remote
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"fmt"
+	"reflect"
+
+	"github.com/arl/evolve/pkg/bitstring"
+)
+
+// A Codec encodes candidates into bytes, in order to send them over the
+// network, and decodes them back on the other side.
+//
+// Both the remote evaluator and the worker server must use compatible codecs.
+// A Codec must be safe for concurrent use by multiple goroutines.
+type Codec interface {
+	// Encode returns the encoding of cand.
+	Encode(cand interface{}) ([]byte, error)
+
+	// Decode decodes a candidate from data.
+	Decode(data []byte) (interface{}, error)
+}
+
+// JSON returns a Codec encoding candidates in JSON.
+//
+// sample is a candidate, it is only used to determine the type of the decoded
+// candidates. For example JSON([]int(nil)) returns a codec for []int
+// candidates.
+func JSON(sample interface{}) Codec {
+	return jsonCodec{typ: reflect.TypeOf(sample)}
+}
+
+type jsonCodec struct{ typ reflect.Type }
+
+func (c jsonCodec) Encode(cand interface{}) ([]byte, error) { return json.Marshal(cand) }
+
+func (c jsonCodec) Decode(data []byte) (interface{}, error) {
+	v := reflect.New(c.typ)
+	if err := json.Unmarshal(data, v.Interface()); err != nil {
+		return nil, err
+	}
+	return v.Elem().Interface(), nil
+}
+
+// Gob returns a Codec encoding candidates with encoding/gob.
+//
+// sample is a candidate, it is only used to determine the type of the decoded
+// candidates.
+func Gob(sample interface{}) Codec {
+	return gobCodec{typ: reflect.TypeOf(sample)}
+}
+
+type gobCodec struct{ typ reflect.Type }
+
+func (c gobCodec) Encode(cand interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(cand); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (c gobCodec) Decode(data []byte) (interface{}, error) {
+	v := reflect.New(c.typ)
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(v.Interface()); err != nil {
+		return nil, err
+	}
+	return v.Elem().Interface(), nil
+}
+
+// Bitstring is a Codec for *bitstring.Bitstring candidates. Bit strings are
+// encoded as strings of 1s and 0s.
+var Bitstring Codec = bitstringCodec{}
+
+type bitstringCodec struct{}
+
+func (bitstringCodec) Encode(cand interface{}) ([]byte, error) {
+	bs, ok := cand.(*bitstring.Bitstring)
+	if !ok {
+		return nil, fmt.Errorf("can't encode %T, want *bitstring.Bitstring", cand)
+	}
+	return []byte(bs.String()), nil
+}
+
+func (bitstringCodec) Decode(data []byte) (interface{}, error) {
+	return bitstring.MakeFromString(string(data))
+}
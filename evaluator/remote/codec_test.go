@@ -0,0 +1,35 @@
+package remote
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/arl/evolve/pkg/bitstring"
+)
+
+func TestCodecs(t *testing.T) {
+	bs, err := bitstring.MakeFromString("1011001110001")
+	require.NoError(t, err)
+
+	tests := []struct {
+		name  string
+		codec Codec
+		cand  interface{}
+	}{
+		{"json/string", JSON(""), "hello"},
+		{"json/ints", JSON([]int(nil)), []int{3, 1, 2}},
+		{"gob/floats", Gob([]float64(nil)), []float64{1.5, -2, 3e10}},
+		{"bitstring", Bitstring, bs},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := tt.codec.Encode(tt.cand)
+			require.NoError(t, err)
+			got, err := tt.codec.Decode(data)
+			require.NoError(t, err)
+			assert.Equal(t, tt.cand, got)
+		})
+	}
+}
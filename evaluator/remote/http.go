@@ -0,0 +1,74 @@
+package remote
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// httpTransport sends requests to an HTTP worker.
+type httpTransport struct {
+	base   string // base URL, without trailing slash
+	client *http.Client
+}
+
+func newHTTPTransport(base string) *httpTransport {
+	return &httpTransport{
+		base:   strings.TrimSuffix(base, "/"),
+		client: &http.Client{Transport: &http.Transport{}},
+	}
+}
+
+func (t *httpTransport) fitness(ctx context.Context, data []byte) (float64, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, t.base+"/fitness", bytes.NewReader(data))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/octet-stream")
+
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return 0, err
+	}
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return strconv.ParseFloat(string(body), 64)
+	case http.StatusUnprocessableEntity:
+		return 0, &EvalError{Msg: strings.TrimSpace(string(body))}
+	}
+	return 0, fmt.Errorf("unexpected status %s", resp.Status)
+}
+
+func (t *httpTransport) ping(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, t.base+"/health", nil)
+	if err != nil {
+		return err
+	}
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	return nil
+}
+
+func (t *httpTransport) close() error {
+	t.client.CloseIdleConnections()
+	return nil
+}
@@ -0,0 +1,375 @@
+// Package remote implements the evaluation of candidates fitness by remote
+// worker processes.
+//
+// A worker process wraps any local evolve.Evaluator into a Server, which serves
+// fitness evaluations over HTTP, TCP, or both. On the engine side, an Evaluator
+// sends the candidates to a farm of such workers, balancing the load between
+// them, retrying failed evaluations and checking their health.
+//
+// Candidates are converted to bytes, and back, by a Codec. Both sides must use
+// compatible codecs.
+//
+// Since the workers only receive the candidate to evaluate, remote evaluation
+// is only suited to fitness evaluations that do not depend on the rest of the
+// population: the worker evaluator receives a nil population.
+package remote
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/arl/evolve"
+)
+
+// ErrNoWorkers is the error returned by New when no worker address is provided.
+var ErrNoWorkers = errors.New("remote: no worker addresses")
+
+// An EvalError is the error returned by a worker when the evaluation of a
+// candidate failed. Evaluation errors are attributed to the candidate, not to
+// the worker, so they don't affect its health.
+type EvalError struct{ Msg string }
+
+func (e *EvalError) Error() string { return "remote: evaluation failed: " + e.Msg }
+
+// transport is the client side of a connection to a worker.
+type transport interface {
+	// fitness sends an encoded candidate and returns its fitness.
+	fitness(ctx context.Context, data []byte) (float64, error)
+	// ping checks the worker is alive and serving.
+	ping(ctx context.Context) error
+	close() error
+}
+
+// Evaluator is an evolve.Evaluator delegating fitness evaluations to remote
+// workers. It is safe for concurrent use by multiple goroutines.
+//
+// Every evaluation is sent to the healthy worker having the fewest evaluations
+// in flight. When an evaluation fails, it is retried on another worker, up to
+// the configured number of retries. If the evaluation still fails, the failure
+// handler decides of the candidate fitness (see OnFailure), and the failure is
+// reported by Failures.
+type Evaluator struct {
+	codec   Codec
+	natural bool
+	workers []*worker
+	next    uint32 // round-robin offset, to break ties between workers
+
+	timeout  time.Duration
+	retries  int
+	interval time.Duration
+	failure  func(cand interface{}, err error) float64
+	maxsize  int
+
+	mu      sync.Mutex // protects nfailed and lastErr
+	nfailed int64
+	lastErr error
+
+	done chan struct{}
+	wg   sync.WaitGroup
+}
+
+type worker struct {
+	addr string
+	t    transport
+
+	inflight int64
+	healthy  int32 // 1 if healthy
+	nevals   int64
+	nfails   int64
+}
+
+// New creates an Evaluator that sends candidates, encoded with codec, to the
+// workers listening at addrs. natural indicates whether the fitness scores
+// computed by the workers are natural.
+//
+// Worker addresses are URLs. An address starting with http:// or https:// is
+// a worker serving over HTTP, the path of the URL is the path at which the
+// server handler is mounted. An address of the form tcp://host:port, or simply
+// host:port, is a worker serving over TCP.
+//
+// The returned evaluator must be closed, with Close, once it's not used
+// anymore.
+func New(codec Codec, natural bool, addrs []string, options ...func(*Evaluator) error) (*Evaluator, error) {
+	if len(addrs) == 0 {
+		return nil, ErrNoWorkers
+	}
+
+	e := &Evaluator{
+		codec:   codec,
+		natural: natural,
+		timeout: 30 * time.Second,
+		retries: 2,
+		maxsize: DefaultMaxRequestSize,
+		done:    make(chan struct{}),
+	}
+	e.failure = func(interface{}, error) float64 { return evolve.WorstFitness(natural) }
+	for _, opt := range options {
+		if err := opt(e); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, addr := range addrs {
+		t, err := newTransport(addr)
+		if err != nil {
+			return nil, err
+		}
+		e.workers = append(e.workers, &worker{addr: addr, t: t, healthy: 1})
+	}
+
+	if e.interval > 0 {
+		e.wg.Add(1)
+		go e.checkHealth()
+	}
+	return e, nil
+}
+
+func newTransport(addr string) (transport, error) {
+	switch {
+	case strings.HasPrefix(addr, "http://"), strings.HasPrefix(addr, "https://"):
+		return newHTTPTransport(addr), nil
+	case strings.HasPrefix(addr, "tcp://"):
+		return newTCPTransport(strings.TrimPrefix(addr, "tcp://")), nil
+	case !strings.Contains(addr, "://"):
+		return newTCPTransport(addr), nil
+	}
+	return nil, fmt.Errorf("remote: unsupported worker address %q", addr)
+}
+
+// Timeout sets the maximum duration of a single evaluation request, including
+// the time spent by the worker to evaluate the candidate. The default is 30s.
+func Timeout(d time.Duration) func(*Evaluator) error {
+	return func(e *Evaluator) error {
+		if d <= 0 {
+			return errors.New("remote: timeout must be positive")
+		}
+		e.timeout = d
+		return nil
+	}
+}
+
+// Retries sets how many times a failed evaluation is retried, on another
+// worker if possible. The default is 2.
+func Retries(n int) func(*Evaluator) error {
+	return func(e *Evaluator) error {
+		if n < 0 {
+			return errors.New("remote: retries must be non-negative")
+		}
+		e.retries = n
+		return nil
+	}
+}
+
+// HealthCheck enables periodic health checks of the workers. Every interval,
+// each worker is pinged: workers that don't answer are not sent evaluations
+// anymore, until they answer again.
+//
+// Without health checks, a worker is marked unhealthy as soon as a request to
+// it fails, and it's used again only when no healthy worker remains.
+func HealthCheck(interval time.Duration) func(*Evaluator) error {
+	return func(e *Evaluator) error {
+		if interval <= 0 {
+			return errors.New("remote: health check interval must be positive")
+		}
+		e.interval = interval
+		return nil
+	}
+}
+
+// MaxRequestSize sets the maximum size, in bytes, of an encoded candidate.
+// The evaluation of larger candidates fails without being sent to a worker.
+// The default is DefaultMaxRequestSize, workers must accept requests at least
+// as large (see Server.MaxRequestSize).
+func MaxRequestSize(n int) func(*Evaluator) error {
+	return func(e *Evaluator) error {
+		if n <= 0 {
+			return errors.New("remote: max request size must be positive")
+		}
+		e.maxsize = n
+		return nil
+	}
+}
+
+// OnFailure sets the function deciding of the fitness of a candidate which
+// evaluation failed after all retries. By default, the candidate is given the
+// worst possible fitness (see evolve.WorstFitness), so that it's unlikely to be
+// selected.
+//
+// The failure handler is called from the goroutines evaluating the
+// candidates, it must not panic, lest the whole process crashes.
+func OnFailure(f func(cand interface{}, err error) float64) func(*Evaluator) error {
+	return func(e *Evaluator) error {
+		e.failure = f
+		return nil
+	}
+}
+
+// Fitness sends cand to a worker and returns the fitness it computed.
+//
+// pop is not sent to the worker.
+func (e *Evaluator) Fitness(cand interface{}, pop []interface{}) float64 {
+	data, err := e.codec.Encode(cand)
+	if err != nil {
+		return e.fail(cand, fmt.Errorf("can't encode candidate: %v", err))
+	}
+	if len(data) > e.maxsize {
+		return e.fail(cand, fmt.Errorf("encoded candidate too large (%d bytes)", len(data)))
+	}
+
+	tried := make([]bool, len(e.workers))
+	for attempt := 0; ; attempt++ {
+		w := e.pick(tried)
+		fitness, err := e.send(w, data)
+		if err == nil {
+			return fitness
+		}
+		if attempt == e.retries {
+			return e.fail(cand, err)
+		}
+	}
+}
+
+// fail records the failure to evaluate cand and returns the fitness decided
+// by the failure handler.
+func (e *Evaluator) fail(cand interface{}, err error) float64 {
+	e.mu.Lock()
+	e.nfailed++
+	e.lastErr = err
+	e.mu.Unlock()
+	return e.failure(cand, err)
+}
+
+// Failures returns the number of candidates which evaluation failed, and the
+// error of the last failure, nil if no evaluation failed.
+func (e *Evaluator) Failures() (int64, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.nfailed, e.lastErr
+}
+
+// IsNatural specifies whether this evaluator generates 'natural' fitness
+// scores or not.
+func (e *Evaluator) IsNatural() bool { return e.natural }
+
+// send sends an encoded candidate to w.
+func (e *Evaluator) send(w *worker, data []byte) (float64, error) {
+	atomic.AddInt64(&w.inflight, 1)
+	defer atomic.AddInt64(&w.inflight, -1)
+
+	ctx, cancel := context.WithTimeout(context.Background(), e.timeout)
+	defer cancel()
+
+	atomic.AddInt64(&w.nevals, 1)
+	fitness, err := w.t.fitness(ctx, data)
+	if err != nil {
+		atomic.AddInt64(&w.nfails, 1)
+		var everr *EvalError
+		if !errors.As(err, &everr) {
+			atomic.StoreInt32(&w.healthy, 0)
+		}
+		return 0, fmt.Errorf("worker %s: %w", w.addr, err)
+	}
+	atomic.StoreInt32(&w.healthy, 1)
+	return fitness, nil
+}
+
+// pick returns the worker to which the next evaluation should be sent, and
+// marks it as tried. Healthy workers that haven't been tried yet are
+// preferred, then among them the one with the fewest evaluations in flight.
+func (e *Evaluator) pick(tried []bool) *worker {
+	off := int(atomic.AddUint32(&e.next, 1))
+
+	best, bestScore := -1, int64(0)
+	for i := range e.workers {
+		idx := (off + i) % len(e.workers)
+		w := e.workers[idx]
+
+		// Score workers so that the lowest score wins: untried workers first,
+		// then healthy ones, then the least loaded.
+		score := atomic.LoadInt64(&w.inflight)
+		if atomic.LoadInt32(&w.healthy) == 0 {
+			score += 1 << 20
+		}
+		if tried[idx] {
+			score += 1 << 40
+		}
+		if best == -1 || score < bestScore {
+			best, bestScore = idx, score
+		}
+	}
+	tried[best] = true
+	return e.workers[best]
+}
+
+func (e *Evaluator) checkHealth() {
+	defer e.wg.Done()
+
+	tick := time.NewTicker(e.interval)
+	defer tick.Stop()
+	for {
+		select {
+		case <-e.done:
+			return
+		case <-tick.C:
+		}
+
+		var wg sync.WaitGroup
+		for _, w := range e.workers {
+			wg.Add(1)
+			go func(w *worker) {
+				defer wg.Done()
+				ctx, cancel := context.WithTimeout(context.Background(), e.interval)
+				defer cancel()
+				if err := w.t.ping(ctx); err != nil {
+					atomic.StoreInt32(&w.healthy, 0)
+				} else {
+					atomic.StoreInt32(&w.healthy, 1)
+				}
+			}(w)
+		}
+		wg.Wait()
+	}
+}
+
+// WorkerStats holds statistics about a worker.
+type WorkerStats struct {
+	Addr        string
+	Healthy     bool
+	InFlight    int   // number of evaluations in flight
+	Evaluations int64 // number of evaluation requests sent
+	Failures    int64 // number of failed evaluation requests
+}
+
+// Workers returns the statistics of all workers.
+func (e *Evaluator) Workers() []WorkerStats {
+	stats := make([]WorkerStats, len(e.workers))
+	for i, w := range e.workers {
+		stats[i] = WorkerStats{
+			Addr:        w.addr,
+			Healthy:     atomic.LoadInt32(&w.healthy) == 1,
+			InFlight:    int(atomic.LoadInt64(&w.inflight)),
+			Evaluations: atomic.LoadInt64(&w.nevals),
+			Failures:    atomic.LoadInt64(&w.nfails),
+		}
+	}
+	return stats
+}
+
+// Close stops the health checks and closes the connections to the workers.
+func (e *Evaluator) Close() error {
+	close(e.done)
+	e.wg.Wait()
+
+	var err error
+	for _, w := range e.workers {
+		if cerr := w.t.close(); cerr != nil && err == nil {
+			err = cerr
+		}
+	}
+	return err
+}
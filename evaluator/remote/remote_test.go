@@ -0,0 +1,272 @@
+package remote
+
+import (
+	"bytes"
+	"io"
+	"net"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/arl/evolve"
+)
+
+// lenEvaluator evaluates string candidates to their length, after sleeping for
+// the provided duration.
+type lenEvaluator struct {
+	sleep time.Duration
+	count int64
+}
+
+func (e *lenEvaluator) Fitness(cand interface{}, _ []interface{}) float64 {
+	atomic.AddInt64(&e.count, 1)
+	time.Sleep(e.sleep)
+	s := cand.(string)
+	if s == "panic" {
+		panic("bad candidate")
+	}
+	return float64(len(s))
+}
+
+func (*lenEvaluator) IsNatural() bool { return true }
+
+// startTCP starts a TCP worker on localhost and returns its address.
+func startTCP(t *testing.T, eval evolve.Evaluator) string {
+	t.Helper()
+	return serveTCP(t, NewServer(eval, JSON("")))
+}
+
+// serveTCP serves srv, which must be configured, over TCP on localhost and
+// returns its address.
+func serveTCP(t *testing.T, srv *Server) string {
+	t.Helper()
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	go srv.Serve(l)
+	t.Cleanup(func() { srv.Close() })
+	return "tcp://" + l.Addr().String()
+}
+
+// startHTTP starts an HTTP worker on localhost and returns its address.
+func startHTTP(t *testing.T, eval evolve.Evaluator) string {
+	t.Helper()
+
+	ts := httptest.NewServer(NewServer(eval, JSON("")))
+	t.Cleanup(ts.Close)
+	return ts.URL
+}
+
+func TestEvaluator(t *testing.T) {
+	tests := []struct {
+		name  string
+		start func(*testing.T, evolve.Evaluator) string
+	}{
+		{"tcp", startTCP},
+		{"http", startHTTP},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			addr := tt.start(t, &lenEvaluator{})
+			eval, err := New(JSON(""), true, []string{addr})
+			require.NoError(t, err)
+			defer eval.Close()
+
+			assert.True(t, eval.IsNatural())
+			assert.Equal(t, 5.0, eval.Fitness("abcde", nil))
+			assert.Equal(t, 0.0, eval.Fitness("", nil))
+
+			pop := []interface{}{"a", "bb", "ccc", "dddd"}
+			evpop := evolve.EvaluatePopulation(pop, eval, true)
+			for i, ind := range evpop {
+				assert.Equal(t, float64(i+1), ind.Fitness)
+			}
+		})
+	}
+}
+
+func TestEvaluatorLoadBalancing(t *testing.T) {
+	evals := []*lenEvaluator{{sleep: 5 * time.Millisecond}, {sleep: 5 * time.Millisecond}}
+	addr1 := startTCP(t, evals[0])
+	addr2 := startHTTP(t, evals[1])
+
+	eval, err := New(JSON(""), true, []string{addr1, addr2})
+	require.NoError(t, err)
+	defer eval.Close()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 40; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			eval.Fitness("abc", nil)
+		}()
+	}
+	wg.Wait()
+
+	for i, e := range evals {
+		if n := atomic.LoadInt64(&e.count); n < 10 {
+			t.Errorf("worker %d performed %d evaluations, want at least 10 out of 40", i, n)
+		}
+	}
+}
+
+func TestEvaluatorRetries(t *testing.T) {
+	// Reserve an address and close it right away, so that no worker listens
+	// on it.
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	dead := l.Addr().String()
+	l.Close()
+
+	alive := startTCP(t, &lenEvaluator{})
+
+	eval, err := New(JSON(""), true, []string{dead, alive}, Retries(1))
+	require.NoError(t, err)
+	defer eval.Close()
+
+	for i := 0; i < 10; i++ {
+		assert.Equal(t, 3.0, eval.Fitness("abc", nil))
+	}
+
+	stats := eval.Workers()
+	assert.False(t, stats[0].Healthy, "dead worker should be unhealthy")
+	assert.True(t, stats[1].Healthy, "alive worker should be healthy")
+	assert.Equal(t, int64(10), stats[1].Evaluations)
+}
+
+func TestEvaluatorTimeout(t *testing.T) {
+	addr := startTCP(t, &lenEvaluator{sleep: 200 * time.Millisecond})
+
+	var failure error
+	eval, err := New(JSON(""), true, []string{addr},
+		Timeout(20*time.Millisecond),
+		Retries(0),
+		OnFailure(func(cand interface{}, err error) float64 {
+			failure = err
+			return -1
+		}))
+	require.NoError(t, err)
+	defer eval.Close()
+
+	assert.Equal(t, -1.0, eval.Fitness("abc", nil))
+	assert.Error(t, failure)
+}
+
+func TestEvaluatorEvalError(t *testing.T) {
+	for _, addr := range []string{startHTTP(t, &lenEvaluator{}), startTCP(t, &lenEvaluator{})} {
+		var failure error
+		eval, err := New(JSON(""), true, []string{addr},
+			OnFailure(func(cand interface{}, err error) float64 {
+				failure = err
+				return -1
+			}))
+		require.NoError(t, err)
+
+		assert.Equal(t, -1.0, eval.Fitness("panic", nil))
+		var everr *EvalError
+		if assert.ErrorAs(t, failure, &everr) {
+			assert.True(t, strings.Contains(everr.Msg, "bad candidate"), everr.Msg)
+		}
+		// An evaluation error is the candidate fault, not the worker's.
+		assert.True(t, eval.Workers()[0].Healthy)
+		eval.Close()
+	}
+}
+
+func TestEvaluatorDefaultFailure(t *testing.T) {
+	// Nothing listens on a closed listener address.
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	addr := l.Addr().String()
+	l.Close()
+
+	for _, natural := range []bool{true, false} {
+		eval, err := New(JSON(""), natural, []string{addr}, Retries(0))
+		require.NoError(t, err)
+
+		assert.Equal(t, evolve.WorstFitness(natural), eval.Fitness("abc", nil))
+		n, last := eval.Failures()
+		assert.Equal(t, int64(1), n)
+		assert.Error(t, last)
+		eval.Close()
+	}
+}
+
+func TestMaxRequestSize(t *testing.T) {
+	srv := NewServer(&lenEvaluator{}, JSON(""))
+	srv.MaxRequestSize = 10
+	addr := serveTCP(t, srv)
+
+	eval, err := New(JSON(""), true, []string{addr}, Retries(0), MaxRequestSize(100))
+	require.NoError(t, err)
+	defer eval.Close()
+
+	// The encoded candidate is a JSON string.
+	assert.Equal(t, 8.0, eval.Fitness("abcdefgh", nil))
+	assert.Equal(t, 0.0, eval.Fitness("abcdefghijkl", nil))
+	_, last := eval.Failures()
+	assert.Error(t, last)
+
+	eval, err = New(JSON(""), true, []string{addr}, MaxRequestSize(4))
+	require.NoError(t, err)
+	defer eval.Close()
+	assert.Equal(t, 0.0, eval.Fitness("abcdefgh", nil))
+	_, last = eval.Failures()
+	assert.EqualError(t, last, "encoded candidate too large (10 bytes)")
+	assert.Zero(t, eval.Workers()[0].Evaluations)
+
+	// A frame announcing a huge payload is rejected before being read.
+	var frame bytes.Buffer
+	frame.Write([]byte{0xff, 0xff, 0xff, 0xff, frameEval})
+	_, _, err = readFrame(&frame, DefaultMaxRequestSize)
+	assert.EqualError(t, err, "frame too large (4294967295 bytes)")
+
+	// A truncated payload is an error.
+	frame.Reset()
+	writeFrame(&frame, frameEval, []byte("abcdef"))
+	_, _, err = readFrame(io.LimitReader(&frame, 8), DefaultMaxRequestSize)
+	assert.Equal(t, io.ErrUnexpectedEOF, err)
+}
+
+func TestEvaluatorHealthCheck(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	dead := l.Addr().String()
+	l.Close()
+
+	alive := startHTTP(t, &lenEvaluator{})
+
+	eval, err := New(JSON(""), true, []string{dead, alive}, HealthCheck(10*time.Millisecond))
+	require.NoError(t, err)
+	defer eval.Close()
+
+	// Workers are initially considered healthy, health checks should detect
+	// the dead one before any evaluation is sent to it.
+	assert.Eventually(t, func() bool { return !eval.Workers()[0].Healthy },
+		time.Second, 5*time.Millisecond, "dead worker should be detected as unhealthy")
+
+	for i := 0; i < 10; i++ {
+		assert.Equal(t, 3.0, eval.Fitness("abc", nil))
+	}
+	assert.Zero(t, eval.Workers()[0].Evaluations)
+	assert.True(t, eval.Workers()[1].Healthy)
+}
+
+func TestNewErrors(t *testing.T) {
+	_, err := New(JSON(""), true, nil)
+	assert.ErrorIs(t, err, ErrNoWorkers)
+
+	_, err = New(JSON(""), true, []string{"udp://localhost:1234"})
+	assert.Error(t, err)
+
+	_, err = New(JSON(""), true, []string{"localhost:1234"}, Timeout(0))
+	assert.Error(t, err)
+}
@@ -0,0 +1,186 @@
+package remote
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/arl/evolve"
+)
+
+// A Server serves the fitness evaluations of a local evolve.Evaluator to remote
+// Evaluators, over HTTP and/or TCP.
+//
+// Candidates are evaluated concurrently, so the wrapped evaluator must be safe
+// for concurrent use by multiple goroutines.
+type Server struct {
+	// MaxRequestSize is the maximum size, in bytes, of an encoded candidate.
+	// Larger requests are rejected. If MaxRequestSize is 0,
+	// DefaultMaxRequestSize is used. It must be set before serving.
+	MaxRequestSize int
+
+	eval  evolve.Evaluator
+	codec Codec
+
+	mu sync.Mutex
+	ls map[net.Listener]struct{}
+}
+
+// NewServer returns a Server evaluating the candidates it receives, decoded
+// with codec, with eval.
+func NewServer(eval evolve.Evaluator, codec Codec) *Server {
+	return &Server{
+		eval:  eval,
+		codec: codec,
+		ls:    make(map[net.Listener]struct{}),
+	}
+}
+
+func (s *Server) maxRequestSize() int {
+	if s.MaxRequestSize > 0 {
+		return s.MaxRequestSize
+	}
+	return DefaultMaxRequestSize
+}
+
+// evaluate decodes a candidate and evaluates it. Panics of the wrapped
+// evaluator are turned into evaluation errors.
+func (s *Server) evaluate(data []byte) (fitness float64, err error) {
+	cand, err := s.codec.Decode(data)
+	if err != nil {
+		return 0, fmt.Errorf("can't decode candidate: %v", err)
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("evaluator panicked: %v", r)
+		}
+	}()
+	return s.eval.Fitness(cand, nil), nil
+}
+
+// ServeHTTP implements http.Handler.
+//
+// Evaluations are requests to the "fitness" path, relatively to the path at
+// which the handler is mounted: the request body is the encoded candidate and
+// the response body is its fitness, formatted as text. Health checks are GET
+// requests to the "health" path.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch {
+	case strings.HasSuffix(r.URL.Path, "/health"):
+		io.WriteString(w, "ok")
+
+	case strings.HasSuffix(r.URL.Path, "/fitness"):
+		if r.Method != http.MethodPost {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		data, err := io.ReadAll(http.MaxBytesReader(w, r.Body, int64(s.maxRequestSize())))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusRequestEntityTooLarge)
+			return
+		}
+		fitness, err := s.evaluate(data)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+			return
+		}
+		io.WriteString(w, strconv.FormatFloat(fitness, 'g', -1, 64))
+
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// Serve accepts TCP connections on l and serves fitness evaluations on each of
+// them, until l is closed or Close is called.
+//
+// Serve always returns a non-nil error. After Close, the returned error is
+// net.ErrClosed.
+func (s *Server) Serve(l net.Listener) error {
+	s.mu.Lock()
+	s.ls[l] = struct{}{}
+	s.mu.Unlock()
+
+	defer func() {
+		s.mu.Lock()
+		delete(s.ls, l)
+		s.mu.Unlock()
+	}()
+
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			return err
+		}
+		go s.serveConn(conn)
+	}
+}
+
+// ListenAndServe listens on the TCP network address addr and then calls Serve.
+func (s *Server) ListenAndServe(addr string) error {
+	l, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	return s.Serve(l)
+}
+
+// Close closes all the listeners passed to Serve. Connections that have
+// already been accepted are served until the client closes them.
+func (s *Server) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var err error
+	for l := range s.ls {
+		if cerr := l.Close(); cerr != nil && !errors.Is(cerr, net.ErrClosed) && err == nil {
+			err = cerr
+		}
+	}
+	return err
+}
+
+// serveConn reads requests from conn and answers them, one after the other.
+func (s *Server) serveConn(conn net.Conn) {
+	defer conn.Close()
+
+	r := bufio.NewReader(conn)
+	w := bufio.NewWriter(conn)
+	for {
+		kind, payload, err := readFrame(r, s.maxRequestSize())
+		if err != nil {
+			return
+		}
+
+		switch kind {
+		case framePing:
+			err = writeFrame(w, framePong, nil)
+		case frameEval:
+			fitness, everr := s.evaluate(payload)
+			if everr != nil {
+				err = writeFrame(w, frameError, []byte(everr.Error()))
+			} else {
+				var b [8]byte
+				binary.BigEndian.PutUint64(b[:], math.Float64bits(fitness))
+				err = writeFrame(w, frameFitness, b[:])
+			}
+		default:
+			err = writeFrame(w, frameError, []byte(fmt.Sprintf("unknown request %q", kind)))
+		}
+		if err == nil {
+			err = w.Flush()
+		}
+		if err != nil {
+			return
+		}
+	}
+}
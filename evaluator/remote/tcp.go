@@ -0,0 +1,175 @@
+package remote
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net"
+	"sync"
+	"time"
+)
+
+// Over TCP, requests and responses are exchanged as frames made of a 4-bytes
+// big endian length, followed by a one byte frame kind and the payload.
+const (
+	frameEval    = 'E' // request: payload is the encoded candidate
+	framePing    = 'P' // request: no payload
+	frameFitness = 'F' // response: payload is the float64 fitness
+	frameError   = 'X' // response: payload is the error message
+	framePong    = 'p' // response: no payload
+)
+
+// DefaultMaxRequestSize is the default maximum size, in bytes, of an encoded
+// candidate sent to a worker (see Server.MaxRequestSize and MaxRequestSize).
+const DefaultMaxRequestSize = 4 << 20
+
+func writeFrame(w io.Writer, kind byte, payload []byte) error {
+	var hdr [5]byte
+	binary.BigEndian.PutUint32(hdr[:4], uint32(len(payload)))
+	hdr[4] = kind
+	if _, err := w.Write(hdr[:]); err != nil {
+		return err
+	}
+	_, err := w.Write(payload)
+	return err
+}
+
+// readFrame reads a frame which payload is at most max bytes long.
+func readFrame(r io.Reader, max int) (byte, []byte, error) {
+	var hdr [5]byte
+	if _, err := io.ReadFull(r, hdr[:]); err != nil {
+		return 0, nil, err
+	}
+	n := binary.BigEndian.Uint32(hdr[:4])
+	if int64(n) > int64(max) {
+		return 0, nil, fmt.Errorf("frame too large (%d bytes)", n)
+	}
+
+	// The payload is read incrementally, so that memory is only allocated
+	// for the data actually received, not for the announced length.
+	var payload bytes.Buffer
+	if _, err := io.CopyN(&payload, r, int64(n)); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return 0, nil, err
+	}
+	return hdr[4], payload.Bytes(), nil
+}
+
+// tcpTransport sends requests to a TCP worker. Each connection carries one
+// request at a time, idle connections are kept for reuse.
+type tcpTransport struct {
+	addr   string
+	dialer net.Dialer
+
+	mu     sync.Mutex
+	idle   []*tcpConn
+	closed bool
+}
+
+type tcpConn struct {
+	net.Conn
+	r *bufio.Reader
+}
+
+func newTCPTransport(addr string) *tcpTransport {
+	return &tcpTransport{addr: addr}
+}
+
+func (t *tcpTransport) get(ctx context.Context) (*tcpConn, error) {
+	t.mu.Lock()
+	if n := len(t.idle); n > 0 {
+		c := t.idle[n-1]
+		t.idle = t.idle[:n-1]
+		t.mu.Unlock()
+		return c, nil
+	}
+	t.mu.Unlock()
+
+	conn, err := t.dialer.DialContext(ctx, "tcp", t.addr)
+	if err != nil {
+		return nil, err
+	}
+	return &tcpConn{Conn: conn, r: bufio.NewReader(conn)}, nil
+}
+
+func (t *tcpTransport) put(c *tcpConn) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.closed {
+		c.Close()
+		return
+	}
+	t.idle = append(t.idle, c)
+}
+
+// roundTrip sends a request frame and reads the response frame.
+func (t *tcpTransport) roundTrip(ctx context.Context, kind byte, payload []byte) (byte, []byte, error) {
+	c, err := t.get(ctx)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Time{}
+	}
+	c.SetDeadline(deadline)
+
+	if err := writeFrame(c, kind, payload); err != nil {
+		c.Close()
+		return 0, nil, err
+	}
+	rkind, rpayload, err := readFrame(c.r, DefaultMaxRequestSize)
+	if err != nil {
+		c.Close()
+		return 0, nil, err
+	}
+	t.put(c)
+	return rkind, rpayload, nil
+}
+
+func (t *tcpTransport) fitness(ctx context.Context, data []byte) (float64, error) {
+	kind, payload, err := t.roundTrip(ctx, frameEval, data)
+	if err != nil {
+		return 0, err
+	}
+	switch kind {
+	case frameFitness:
+		if len(payload) != 8 {
+			return 0, fmt.Errorf("malformed fitness response (%d bytes)", len(payload))
+		}
+		return math.Float64frombits(binary.BigEndian.Uint64(payload)), nil
+	case frameError:
+		return 0, &EvalError{Msg: string(payload)}
+	}
+	return 0, fmt.Errorf("unexpected response %q", kind)
+}
+
+func (t *tcpTransport) ping(ctx context.Context) error {
+	kind, _, err := t.roundTrip(ctx, framePing, nil)
+	if err != nil {
+		return err
+	}
+	if kind != framePong {
+		return errors.New("unexpected ping response")
+	}
+	return nil
+}
+
+func (t *tcpTransport) close() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.closed = true
+	for _, c := range t.idle {
+		c.Close()
+	}
+	t.idle = nil
+	return nil
+}
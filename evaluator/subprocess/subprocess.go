@@ -0,0 +1,503 @@
+// Package subprocess implements the evaluation of candidates fitness by
+// long-lived external processes.
+//
+// The fitness function is a program, written in any language, that reads
+// evaluation requests on its standard input and writes the results on its
+// standard output, using a line-delimited JSON protocol. Each request is a
+// JSON object on a single line, holding a request ID and the candidate to
+// evaluate:
+//
+//	{"id":1,"candidate":"EVOLVE WORLD"}
+//
+// For each request, the program must write a single line holding the request
+// ID and either the candidate fitness or an error message:
+//
+//	{"id":1,"fitness":12}
+//	{"id":2,"error":"invalid candidate"}
+//
+// Requests may be pipelined, that is a process may receive more requests
+// before it has answered the previous ones (see Pipeline), and the responses
+// may be written in any order since they are matched with requests by ID.
+// Anything the program writes on its standard error is forwarded.
+//
+// A minimal Python fitness program looks like this:
+//
+//	import json, sys
+//	for line in sys.stdin:
+//	    req = json.loads(line)
+//	    resp = {"id": req["id"], "fitness": len(req["candidate"])}
+//	    print(json.dumps(resp), flush=True)
+package subprocess
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"runtime"
+	"sync"
+	"time"
+
+	"github.com/arl/evolve"
+)
+
+var (
+	// ErrTimeout is the error reported when a process didn't answer a request
+	// in time.
+	ErrTimeout = errors.New("subprocess: evaluation timed out")
+
+	// ErrClosed is the error reported when evaluating a candidate with a
+	// closed Evaluator.
+	ErrClosed = errors.New("subprocess: evaluator closed")
+)
+
+// maxResponseSize is the maximum length of a response line. A process writing
+// a longer line is killed.
+var maxResponseSize = 64 << 20
+
+// An EvalError is the error reported by the process for a candidate.
+type EvalError struct{ Msg string }
+
+func (e *EvalError) Error() string { return "subprocess: evaluation failed: " + e.Msg }
+
+// Command returns a function creating commands that run the named program
+// with the given arguments. See exec.Command.
+func Command(name string, args ...string) func() *exec.Cmd {
+	return func() *exec.Cmd { return exec.Command(name, args...) }
+}
+
+// Evaluator is an evolve.Evaluator delegating fitness evaluations to a pool of
+// processes. It is safe for concurrent use by multiple goroutines.
+//
+// Processes that exit or crash are restarted on the next evaluation sent to
+// them. The evaluations that were pending on a crashed process are retried on
+// another process, up to the configured number of retries. If the evaluation
+// still fails, the failure handler decides of the candidate fitness (see
+// OnFailure), and the failure is reported by Failures.
+type Evaluator struct {
+	natural bool
+	newCmd  func() *exec.Cmd
+	encode  func(interface{}) ([]byte, error)
+	stderr  io.Writer
+
+	nprocs   int
+	depth    int
+	timeout  time.Duration
+	retries  int
+	failure  func(cand interface{}, err error) float64
+	procs    []*process
+	slots    chan *process // one entry per available request slot
+	restarts int64
+	mu       sync.Mutex // protects restarts, closed, nfailed and lastErr
+	closed   bool
+	nfailed  int64
+	lastErr  error
+}
+
+// New creates an Evaluator which processes are created with newCmd. natural
+// indicates whether the fitness scores computed by the processes are natural.
+//
+// newCmd must return a new command every time it's called. The standard input
+// and output of the command are used for the protocol and must not be set.
+//
+// The processes are started right away, the returned evaluator must be closed
+// with Close once it's not used anymore.
+func New(natural bool, newCmd func() *exec.Cmd, options ...func(*Evaluator) error) (*Evaluator, error) {
+	e := &Evaluator{
+		natural: natural,
+		newCmd:  newCmd,
+		encode:  json.Marshal,
+		stderr:  os.Stderr,
+		nprocs:  runtime.GOMAXPROCS(0),
+		depth:   1,
+		retries: 1,
+	}
+	e.failure = func(interface{}, error) float64 { return evolve.WorstFitness(natural) }
+	for _, opt := range options {
+		if err := opt(e); err != nil {
+			return nil, err
+		}
+	}
+
+	e.slots = make(chan *process, e.nprocs*e.depth)
+	for i := 0; i < e.nprocs; i++ {
+		p := &process{e: e}
+		if err := p.start(); err != nil {
+			e.Close()
+			return nil, err
+		}
+		e.procs = append(e.procs, p)
+	}
+	// Interleave the slots so that requests are spread over processes.
+	for d := 0; d < e.depth; d++ {
+		for _, p := range e.procs {
+			e.slots <- p
+		}
+	}
+	return e, nil
+}
+
+// Procs sets the number of processes in the pool. It defaults to
+// runtime.GOMAXPROCS(0), which is the number of fitness evaluations the engine
+// can perform in parallel.
+func Procs(n int) func(*Evaluator) error {
+	return func(e *Evaluator) error {
+		if n <= 0 {
+			return errors.New("subprocess: number of processes must be positive")
+		}
+		e.nprocs = n
+		return nil
+	}
+}
+
+// Pipeline sets the maximum number of requests sent to a process before it
+// answers them. It defaults to 1, no pipelining.
+func Pipeline(depth int) func(*Evaluator) error {
+	return func(e *Evaluator) error {
+		if depth <= 0 {
+			return errors.New("subprocess: pipeline depth must be positive")
+		}
+		e.depth = depth
+		return nil
+	}
+}
+
+// Timeout sets the maximum duration a process can take to answer a request.
+// A process exceeding it is considered stuck: it's killed and restarted. By
+// default, there's no timeout.
+func Timeout(d time.Duration) func(*Evaluator) error {
+	return func(e *Evaluator) error {
+		if d <= 0 {
+			return errors.New("subprocess: timeout must be positive")
+		}
+		e.timeout = d
+		return nil
+	}
+}
+
+// Retries sets how many times an evaluation that failed because its process
+// crashed is retried. It defaults to 1. Evaluations that timed out or for
+// which the process reported an error are not retried.
+func Retries(n int) func(*Evaluator) error {
+	return func(e *Evaluator) error {
+		if n < 0 {
+			return errors.New("subprocess: retries must be non-negative")
+		}
+		e.retries = n
+		return nil
+	}
+}
+
+// Encoder sets the function encoding candidates into JSON. It defaults to
+// json.Marshal.
+func Encoder(encode func(cand interface{}) ([]byte, error)) func(*Evaluator) error {
+	return func(e *Evaluator) error {
+		e.encode = encode
+		return nil
+	}
+}
+
+// Stderr sets where the standard error of the processes is forwarded. It
+// defaults to os.Stderr.
+func Stderr(w io.Writer) func(*Evaluator) error {
+	return func(e *Evaluator) error {
+		e.stderr = w
+		return nil
+	}
+}
+
+// OnFailure sets the function deciding of the fitness of a candidate which
+// evaluation failed. By default, the candidate is given the worst possible
+// fitness (see evolve.WorstFitness), so that it's unlikely to be selected.
+//
+// The failure handler is called from the goroutines evaluating the
+// candidates, it must not panic, lest the whole process crashes.
+func OnFailure(f func(cand interface{}, err error) float64) func(*Evaluator) error {
+	return func(e *Evaluator) error {
+		e.failure = f
+		return nil
+	}
+}
+
+// Fitness sends cand to one of the processes and returns the fitness it
+// computed. pop is not sent to the process.
+func (e *Evaluator) Fitness(cand interface{}, pop []interface{}) float64 {
+	data, err := e.encode(cand)
+	if err != nil {
+		return e.fail(cand, fmt.Errorf("can't encode candidate: %v", err))
+	}
+
+	for attempt := 0; ; attempt++ {
+		p, ok := <-e.slots
+		if !ok || e.isClosed() {
+			return e.fail(cand, ErrClosed)
+		}
+		fitness, err := p.evaluate(data)
+		e.release(p)
+
+		if err == nil {
+			return fitness
+		}
+		var everr *EvalError
+		if errors.As(err, &everr) || errors.Is(err, ErrTimeout) || attempt == e.retries {
+			return e.fail(cand, err)
+		}
+	}
+}
+
+// fail records the failure to evaluate cand and returns the fitness decided
+// by the failure handler.
+func (e *Evaluator) fail(cand interface{}, err error) float64 {
+	e.mu.Lock()
+	e.nfailed++
+	e.lastErr = err
+	e.mu.Unlock()
+	return e.failure(cand, err)
+}
+
+// Failures returns the number of candidates which evaluation failed, and the
+// error of the last failure, nil if no evaluation failed.
+func (e *Evaluator) Failures() (int64, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.nfailed, e.lastErr
+}
+
+// release gives a request slot back.
+func (e *Evaluator) release(p *process) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if !e.closed {
+		e.slots <- p
+	}
+}
+
+func (e *Evaluator) isClosed() bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.closed
+}
+
+// IsNatural specifies whether this evaluator generates 'natural' fitness
+// scores or not.
+func (e *Evaluator) IsNatural() bool { return e.natural }
+
+// Restarts returns the number of times a process has been restarted.
+func (e *Evaluator) Restarts() int64 {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.restarts
+}
+
+// Close closes the standard input of all processes and waits for them to
+// exit. Processes that didn't exit after a few seconds are killed.
+func (e *Evaluator) Close() error {
+	e.mu.Lock()
+	if e.closed {
+		e.mu.Unlock()
+		return nil
+	}
+	e.closed = true
+	close(e.slots)
+	e.mu.Unlock()
+
+	for _, p := range e.procs {
+		p.stop(5 * time.Second)
+	}
+	return nil
+}
+
+type request struct {
+	ID        uint64          `json:"id"`
+	Candidate json.RawMessage `json:"candidate"`
+}
+
+type response struct {
+	ID      uint64   `json:"id"`
+	Fitness *float64 `json:"fitness"`
+	Error   string   `json:"error"`
+}
+
+type result struct {
+	fitness float64
+	err     error
+}
+
+// process is one running instance of the fitness program. It's restarted
+// whenever it exits.
+type process struct {
+	e *Evaluator
+
+	mu      sync.Mutex
+	cmd     *exec.Cmd
+	stdin   io.WriteCloser
+	done    chan struct{} // closed when the running instance exited
+	pending map[uint64]chan result
+	nextID  uint64
+	dead    bool
+}
+
+// start starts a new instance of the program. p.mu must be held, or p not
+// shared yet.
+func (p *process) start() error {
+	cmd := p.e.newCmd()
+	cmd.Stderr = p.e.stderr
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return fmt.Errorf("subprocess: %v", err)
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return fmt.Errorf("subprocess: %v", err)
+	}
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("subprocess: can't start process: %v", err)
+	}
+
+	p.cmd = cmd
+	p.stdin = stdin
+	p.done = make(chan struct{})
+	p.pending = make(map[uint64]chan result)
+	p.dead = false
+	go p.read(cmd, stdout, p.pending, p.done)
+	return nil
+}
+
+// read reads the responses of one instance of the program, until it exits.
+func (p *process) read(cmd *exec.Cmd, stdout io.Reader, pending map[uint64]chan result, done chan struct{}) {
+	s := bufio.NewScanner(stdout)
+	s.Buffer(make([]byte, 64*1024), maxResponseSize)
+	for s.Scan() {
+		var resp response
+		if err := json.Unmarshal(s.Bytes(), &resp); err != nil {
+			fmt.Fprintf(p.e.stderr, "subprocess: ignoring malformed response %q: %v\n", s.Text(), err)
+			continue
+		}
+
+		p.mu.Lock()
+		ch, ok := pending[resp.ID]
+		delete(pending, resp.ID)
+		p.mu.Unlock()
+		if !ok {
+			continue
+		}
+
+		switch {
+		case resp.Error != "":
+			ch <- result{err: &EvalError{Msg: resp.Error}}
+		case resp.Fitness == nil:
+			ch <- result{err: &EvalError{Msg: "response has no fitness"}}
+		default:
+			ch <- result{fitness: *resp.Fitness}
+		}
+	}
+
+	// Either the process closed its output, it has exited or is about to, or
+	// its output can't be read anymore, it must then be killed so as not to
+	// wait for it forever.
+	err := s.Err()
+	if err != nil {
+		cmd.Process.Kill()
+		cmd.Wait()
+		err = fmt.Errorf("subprocess: process killed, can't read its response: %w", err)
+	} else if err = cmd.Wait(); err != nil {
+		err = fmt.Errorf("subprocess: process crashed: %v", err)
+	} else {
+		err = errors.New("subprocess: process crashed: process exited")
+	}
+
+	p.mu.Lock()
+	if p.cmd == cmd {
+		p.dead = true
+	}
+	for id, ch := range pending {
+		ch <- result{err: err}
+		delete(pending, id)
+	}
+	p.mu.Unlock()
+	close(done)
+}
+
+// evaluate sends an encoded candidate to the process and waits for its
+// response, restarting the process first if it's dead.
+func (p *process) evaluate(data []byte) (float64, error) {
+	ch := make(chan result, 1)
+
+	p.mu.Lock()
+	if p.dead {
+		if err := p.start(); err != nil {
+			p.mu.Unlock()
+			return 0, err
+		}
+		p.e.mu.Lock()
+		p.e.restarts++
+		p.e.mu.Unlock()
+	}
+	p.nextID++
+	id := p.nextID
+	p.pending[id] = ch
+
+	line, err := json.Marshal(request{ID: id, Candidate: data})
+	if err == nil {
+		_, err = p.stdin.Write(append(line, '\n'))
+	}
+	cmd := p.cmd
+	done := p.done
+	p.mu.Unlock()
+
+	if err != nil {
+		// The process is probably dead, let the reader goroutine notice it.
+		p.kill(cmd)
+	}
+
+	var timeout <-chan time.Time
+	if p.e.timeout > 0 {
+		t := time.NewTimer(p.e.timeout)
+		defer t.Stop()
+		timeout = t.C
+	}
+
+	select {
+	case res := <-ch:
+		return res.fitness, res.err
+	case <-timeout:
+		p.mu.Lock()
+		delete(p.pending, id)
+		p.mu.Unlock()
+		p.kill(cmd)
+		<-done
+		return 0, ErrTimeout
+	}
+}
+
+// kill kills cmd, if it's still the running instance.
+func (p *process) kill(cmd *exec.Cmd) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.cmd == cmd && cmd.Process != nil {
+		cmd.Process.Kill()
+	}
+}
+
+// stop closes the process standard input and waits for it to exit, killing it
+// after grace.
+func (p *process) stop(grace time.Duration) {
+	p.mu.Lock()
+	if p.cmd == nil {
+		p.mu.Unlock()
+		return
+	}
+	p.stdin.Close()
+	cmd, done := p.cmd, p.done
+	p.mu.Unlock()
+
+	select {
+	case <-done:
+	case <-time.After(grace):
+		p.kill(cmd)
+		<-done
+	}
+}
@@ -0,0 +1,234 @@
+package subprocess
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/arl/evolve"
+)
+
+// helper returns a function creating commands that run the test binary as a
+// fitness process, behaving according to mode.
+func helper(mode string) func() *exec.Cmd {
+	return func() *exec.Cmd {
+		cmd := exec.Command(os.Args[0], "-test.run=TestHelperProcess")
+		cmd.Env = append(os.Environ(), "EVOLVE_HELPER_PROCESS="+mode)
+		return cmd
+	}
+}
+
+// TestHelperProcess isn't a real test. It's used as a fitness process by the
+// other tests. The fitness of a candidate string is its length, the following
+// candidates trigger special behaviours:
+//   - "crash": the process exits without answering.
+//   - "slow": the process sleeps before answering.
+//   - "error": the process answers with an error.
+//   - "long": the process answers with a 128KB line, then hangs.
+//
+// In "reverse" mode, the process reads requests two by two and answers them in
+// reverse order.
+func TestHelperProcess(t *testing.T) {
+	mode := os.Getenv("EVOLVE_HELPER_PROCESS")
+	if mode == "" {
+		return
+	}
+	defer os.Exit(0)
+
+	w := bufio.NewWriter(os.Stdout)
+	answer := func(req request) {
+		var cand string
+		json.Unmarshal(req.Candidate, &cand)
+		switch cand {
+		case "crash":
+			os.Exit(3)
+		case "slow":
+			time.Sleep(time.Second)
+		case "error":
+			fmt.Fprintf(w, "{\"id\":%d,\"error\":\"invalid candidate\"}\n", req.ID)
+			return
+		case "long":
+			fmt.Fprintf(w, "{\"id\":%d,\"fitness\":4,\"padding\":\"%s\"}\n", req.ID, strings.Repeat("x", 128<<10))
+			w.Flush()
+			select {}
+		}
+		fmt.Fprintf(w, "{\"id\":%d,\"fitness\":%d}\n", req.ID, len(cand))
+	}
+
+	s := bufio.NewScanner(os.Stdin)
+	var held []request
+	for s.Scan() {
+		var req request
+		if err := json.Unmarshal(s.Bytes(), &req); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(2)
+		}
+		if mode == "reverse" {
+			held = append(held, req)
+			if len(held) < 2 {
+				continue
+			}
+			answer(held[1])
+			answer(held[0])
+			held = held[:0]
+		} else {
+			answer(req)
+		}
+		w.Flush()
+	}
+}
+
+func TestEvaluator(t *testing.T) {
+	eval, err := New(false, helper("normal"), Procs(2))
+	require.NoError(t, err)
+	defer eval.Close()
+
+	assert.False(t, eval.IsNatural())
+	assert.Equal(t, 5.0, eval.Fitness("abcde", nil))
+
+	pop := make([]interface{}, 50)
+	for i := range pop {
+		pop[i] = strings.Repeat("x", i)
+	}
+	evpop := evolve.EvaluatePopulation(pop, eval, true)
+	for i, ind := range evpop {
+		assert.Equal(t, float64(len(pop[i].(string))), ind.Fitness)
+	}
+}
+
+func TestEvaluatorPipelining(t *testing.T) {
+	// Each process answers requests 2 by 2, in reverse order, so evaluations
+	// can only complete if requests are pipelined and matched by ID.
+	eval, err := New(true, helper("reverse"), Procs(1), Pipeline(2))
+	require.NoError(t, err)
+	defer eval.Close()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			cand := strings.Repeat("x", i)
+			assert.Equal(t, float64(i), eval.Fitness(cand, nil))
+		}(i)
+	}
+	wg.Wait()
+}
+
+func TestEvaluatorRestart(t *testing.T) {
+	var failure error
+	eval, err := New(true, helper("normal"), Procs(1), Retries(0),
+		OnFailure(func(cand interface{}, err error) float64 {
+			failure = err
+			return -1
+		}))
+	require.NoError(t, err)
+	defer eval.Close()
+
+	assert.Equal(t, 3.0, eval.Fitness("abc", nil))
+	assert.Equal(t, -1.0, eval.Fitness("crash", nil))
+	assert.Error(t, failure)
+
+	// The process should have been restarted.
+	assert.Equal(t, 4.0, eval.Fitness("abcd", nil))
+	assert.Equal(t, int64(1), eval.Restarts())
+}
+
+func TestEvaluatorTimeout(t *testing.T) {
+	var failure error
+	eval, err := New(true, helper("normal"), Procs(1), Timeout(50*time.Millisecond),
+		OnFailure(func(cand interface{}, err error) float64 {
+			failure = err
+			return -1
+		}))
+	require.NoError(t, err)
+	defer eval.Close()
+
+	assert.Equal(t, -1.0, eval.Fitness("slow", nil))
+	assert.ErrorIs(t, failure, ErrTimeout)
+
+	// The stuck process should have been killed and restarted.
+	assert.Equal(t, 2.0, eval.Fitness("ab", nil))
+	assert.Equal(t, int64(1), eval.Restarts())
+}
+
+func TestEvaluatorEvalError(t *testing.T) {
+	var failure error
+	eval, err := New(true, helper("normal"), Procs(1),
+		OnFailure(func(cand interface{}, err error) float64 {
+			failure = err
+			return -1
+		}))
+	require.NoError(t, err)
+	defer eval.Close()
+
+	assert.Equal(t, -1.0, eval.Fitness("error", nil))
+	var everr *EvalError
+	if assert.ErrorAs(t, failure, &everr) {
+		assert.Equal(t, "invalid candidate", everr.Msg)
+	}
+	assert.Zero(t, eval.Restarts())
+}
+
+func TestEvaluatorClosed(t *testing.T) {
+	var failure error
+	eval, err := New(true, helper("normal"), Procs(1),
+		OnFailure(func(cand interface{}, err error) float64 {
+			failure = err
+			return -1
+		}))
+	require.NoError(t, err)
+	require.NoError(t, eval.Close())
+
+	assert.Equal(t, -1.0, eval.Fitness("abc", nil))
+	assert.ErrorIs(t, failure, ErrClosed)
+}
+
+func TestEvaluatorDefaultFailure(t *testing.T) {
+	for _, natural := range []bool{true, false} {
+		eval, err := New(natural, helper("normal"), Procs(1), Retries(0))
+		require.NoError(t, err)
+
+		assert.Equal(t, evolve.WorstFitness(natural), eval.Fitness("crash", nil))
+		n, last := eval.Failures()
+		assert.Equal(t, int64(1), n)
+		assert.Error(t, last)
+		eval.Close()
+	}
+}
+
+func TestEvaluatorLongResponse(t *testing.T) {
+	// The scanner buffer initially holds 64KB lines.
+	defer func(n int) { maxResponseSize = n }(maxResponseSize)
+	maxResponseSize = 64 << 10
+
+	eval, err := New(true, helper("normal"), Procs(1), Retries(0))
+	require.NoError(t, err)
+	defer eval.Close()
+
+	// The process is killed instead of being waited for forever.
+	assert.Equal(t, 0.0, eval.Fitness("long", nil))
+	_, last := eval.Failures()
+	assert.ErrorIs(t, last, bufio.ErrTooLong)
+
+	// It's restarted for the next evaluation.
+	assert.Equal(t, 3.0, eval.Fitness("abc", nil))
+}
+
+func TestNewErrors(t *testing.T) {
+	_, err := New(true, Command("/this/program/does/not/exist"), Stderr(io.Discard))
+	assert.Error(t, err)
+
+	_, err = New(true, helper("normal"), Procs(0))
+	assert.Error(t, err)
+}
@@ -0,0 +1,217 @@
+package surrogate
+
+import (
+	"math"
+	"sort"
+)
+
+// A Model is a regression model approximating the fitness function.
+//
+// A Model doesn't need to be safe for concurrent use, the surrogate Evaluator
+// serializes calls to its model.
+type Model interface {
+	// Add adds a training sample: x is the feature vector of a candidate and y
+	// its true fitness.
+	Add(x []float64, y float64)
+
+	// Predict returns the predicted fitness for feature vector x. Predict is
+	// only called once at least one sample has been added.
+	Predict(x []float64) float64
+}
+
+// sqdist returns the squared euclidean distance between a and b.
+func sqdist(a, b []float64) float64 {
+	var d float64
+	for i := range a {
+		diff := a[i] - b[i]
+		d += diff * diff
+	}
+	return d
+}
+
+// KNN is a k-nearest neighbours model. The prediction is the mean fitness of
+// the K nearest training samples, weighted by the inverse of their distance.
+type KNN struct {
+	K int
+
+	xs [][]float64
+	ys []float64
+}
+
+// NewKNN returns a k-nearest neighbours model.
+func NewKNN(k int) *KNN { return &KNN{K: k} }
+
+// Add adds a training sample.
+func (m *KNN) Add(x []float64, y float64) {
+	m.xs = append(m.xs, x)
+	m.ys = append(m.ys, y)
+}
+
+// Predict returns the predicted fitness for x.
+func (m *KNN) Predict(x []float64) float64 {
+	type neighbour struct{ d, y float64 }
+	ns := make([]neighbour, len(m.xs))
+	for i := range m.xs {
+		ns[i] = neighbour{d: math.Sqrt(sqdist(x, m.xs[i])), y: m.ys[i]}
+	}
+	sort.Slice(ns, func(i, j int) bool { return ns[i].d < ns[j].d })
+
+	k := m.K
+	if k <= 0 || k > len(ns) {
+		k = len(ns)
+	}
+
+	var sum, wsum float64
+	for _, n := range ns[:k] {
+		if n.d == 0 {
+			// Exact match.
+			return n.y
+		}
+		w := 1 / n.d
+		sum += w * n.y
+		wsum += w
+	}
+	return sum / wsum
+}
+
+// RBF is a radial basis function network, with gaussian kernels centered on
+// the most recent training samples. The network weights are fitted by
+// regularized least squares, the network is lazily refitted when samples are
+// added.
+type RBF struct {
+	// Width is the width of the gaussian kernels. If 0, it is set to the mean
+	// distance between the centers.
+	Width float64
+
+	// MaxCenters is the maximum number of centers, that is the number of most
+	// recent training samples used to fit the network. Fitting is O(n³) in the
+	// number of centers. If 0, 200 centers are used.
+	MaxCenters int
+
+	// Lambda is the regularization coefficient. If 0, 1e-8 is used.
+	Lambda float64
+
+	xs      [][]float64
+	ys      []float64
+	fitted  bool
+	width   float64
+	mean    float64
+	weights []float64
+}
+
+// NewRBF returns a radial basis function network model.
+func NewRBF() *RBF { return &RBF{} }
+
+// Add adds a training sample.
+func (m *RBF) Add(x []float64, y float64) {
+	maxc := m.MaxCenters
+	if maxc <= 0 {
+		maxc = 200
+	}
+	m.xs = append(m.xs, x)
+	m.ys = append(m.ys, y)
+	if len(m.xs) > maxc {
+		m.xs = m.xs[len(m.xs)-maxc:]
+		m.ys = m.ys[len(m.ys)-maxc:]
+	}
+	m.fitted = false
+}
+
+func (m *RBF) fit() {
+	n := len(m.xs)
+
+	m.width = m.Width
+	if m.width <= 0 {
+		var sum float64
+		var npairs int
+		for i := 0; i < n; i++ {
+			for j := i + 1; j < n; j++ {
+				sum += math.Sqrt(sqdist(m.xs[i], m.xs[j]))
+				npairs++
+			}
+		}
+		m.width = 1
+		if npairs > 0 && sum > 0 {
+			m.width = sum / float64(npairs)
+		}
+	}
+	lambda := m.Lambda
+	if lambda <= 0 {
+		lambda = 1e-8
+	}
+
+	// The network models the deviation from the mean fitness, so that
+	// predictions far from any center fall back to the mean.
+	m.mean = 0
+	for _, y := range m.ys {
+		m.mean += y
+	}
+	m.mean /= float64(n)
+
+	// Solve (Φ + λI) w = y - mean, with gaussian elimination.
+	a := make([][]float64, n)
+	for i := range a {
+		a[i] = make([]float64, n+1)
+		for j := 0; j < n; j++ {
+			a[i][j] = m.kernel(m.xs[i], m.xs[j])
+		}
+		a[i][i] += lambda
+		a[i][n] = m.ys[i] - m.mean
+	}
+	m.weights = solve(a)
+	m.fitted = true
+}
+
+func (m *RBF) kernel(a, b []float64) float64 {
+	return math.Exp(-sqdist(a, b) / (2 * m.width * m.width))
+}
+
+// Predict returns the predicted fitness for x.
+func (m *RBF) Predict(x []float64) float64 {
+	if !m.fitted {
+		m.fit()
+	}
+	y := m.mean
+	for i, c := range m.xs {
+		y += m.weights[i] * m.kernel(x, c)
+	}
+	return y
+}
+
+// solve solves the linear system which augmented matrix is a, with gaussian
+// elimination and partial pivoting. Singular systems get zero weights for the
+// dependent unknowns.
+func solve(a [][]float64) []float64 {
+	n := len(a)
+	for col := 0; col < n; col++ {
+		pivot := col
+		for row := col + 1; row < n; row++ {
+			if math.Abs(a[row][col]) > math.Abs(a[pivot][col]) {
+				pivot = row
+			}
+		}
+		a[col], a[pivot] = a[pivot], a[col]
+		if math.Abs(a[col][col]) < 1e-300 {
+			continue
+		}
+		for row := col + 1; row < n; row++ {
+			f := a[row][col] / a[col][col]
+			for k := col; k <= n; k++ {
+				a[row][k] -= f * a[col][k]
+			}
+		}
+	}
+
+	x := make([]float64, n)
+	for row := n - 1; row >= 0; row-- {
+		if math.Abs(a[row][row]) < 1e-300 {
+			continue
+		}
+		sum := a[row][n]
+		for k := row + 1; k < n; k++ {
+			sum -= a[row][k] * x[k]
+		}
+		x[row] = sum / a[row][row]
+	}
+	return x
+}
@@ -0,0 +1,49 @@
+package surrogate
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKNN(t *testing.T) {
+	m := NewKNN(2)
+	m.Add([]float64{0}, 0)
+	m.Add([]float64{1}, 10)
+	m.Add([]float64{10}, 100)
+
+	assert.Equal(t, 10.0, m.Predict([]float64{1}), "exact match")
+	// Halfway between the 2 nearest neighbours.
+	assert.InDelta(t, 5.0, m.Predict([]float64{0.5}), 1e-9)
+	// Closer to 1 than to 0.
+	assert.InDelta(t, 7.5, m.Predict([]float64{0.75}), 1e-9)
+}
+
+func TestRBF(t *testing.T) {
+	rng := rand.New(rand.NewSource(99))
+
+	m := NewRBF()
+	f := func(x []float64) float64 { return math.Sin(x[0]) + x[1]*x[1] }
+	for i := 0; i < 100; i++ {
+		x := []float64{rng.Float64() * 3, rng.Float64()*2 - 1}
+		m.Add(x, f(x))
+	}
+
+	var maxerr float64
+	for i := 0; i < 50; i++ {
+		x := []float64{0.5 + rng.Float64()*2, rng.Float64() - 0.5}
+		maxerr = math.Max(maxerr, math.Abs(m.Predict(x)-f(x)))
+	}
+	assert.Less(t, maxerr, 0.05, "RBF interpolation error too large")
+}
+
+func TestRBFMaxCenters(t *testing.T) {
+	m := &RBF{MaxCenters: 10}
+	for i := 0; i < 30; i++ {
+		m.Add([]float64{float64(i)}, float64(i))
+	}
+	assert.Len(t, m.xs, 10)
+	assert.InDelta(t, 25.0, m.Predict([]float64{25}), 1e-3)
+}
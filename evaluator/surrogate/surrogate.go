@@ -0,0 +1,344 @@
+// Package surrogate implements surrogate-assisted fitness evaluation.
+//
+// When the fitness function is very expensive, a cheap regression model of it,
+// the surrogate, can be trained on the fitness scores computed so far. The
+// surrogate Evaluator uses such a model to pre-screen every generation: the
+// candidates are ranked by predicted fitness and only the most promising ones
+// are evaluated by the real fitness function, the others get their predicted
+// fitness. The proportion of candidates that are really evaluated in each
+// generation is decided by a Controller, the evolution control.
+//
+// Candidates are given to the model as feature vectors, extracted by a
+// user-provided function.
+//
+// The Evaluator is an evolve.GenerationalEvaluator: the engine notifies it of
+// every new generation. When it's used outside of the engine, StartGeneration
+// must be called before each population is evaluated.
+package surrogate
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+
+	"github.com/arl/evolve"
+)
+
+// A Controller decides, for every generation, which proportion of the
+// candidates should be evaluated by the real fitness function.
+type Controller interface {
+	// Fraction returns the proportion, in [0, 1], of candidates of generation
+	// gen (zero-based) that must be evaluated with the real fitness function.
+	Fraction(gen int) float64
+}
+
+// Schedule is a Controller combining individual-based and generation-based
+// evolution control.
+//
+// The first Warmup generations are entirely evaluated with the real fitness
+// function, so that the model gets enough training samples. Afterwards, every
+// Every generations, the whole generation is evaluated with the real fitness
+// function (if Every is not 0), so that the model error can be assessed on all
+// candidates. In other generations, only the proportion Ratio of the best
+// candidates, according to the model, are really evaluated.
+type Schedule struct {
+	Warmup int
+	Every  int
+	Ratio  float64
+}
+
+// Fraction implements Controller.
+func (s Schedule) Fraction(gen int) float64 {
+	if gen < s.Warmup {
+		return 1
+	}
+	if s.Every > 0 && (gen-s.Warmup)%s.Every == s.Every-1 {
+		return 1
+	}
+	return s.Ratio
+}
+
+// Stats holds statistics about the surrogate evaluations.
+type Stats struct {
+	// Generations is the number of generations pre-screened so far.
+	Generations int
+
+	// TrueEvals is the total number of real fitness evaluations.
+	TrueEvals int64
+
+	// Predicted is the total number of fitness scores predicted by the model.
+	Predicted int64
+
+	// MAE and RMSE are the mean absolute error and root mean square error of
+	// the model predictions, measured on the candidates of the last completed
+	// generation that were evaluated by the real fitness function. They are
+	// NaN if no prediction could be checked in that generation.
+	MAE, RMSE float64
+
+	// Checked is the number of predictions from which MAE and RMSE have been
+	// computed.
+	Checked int
+}
+
+// Evaluator is an evolve.Evaluator pre-screening the candidates with a
+// surrogate model, and only evaluating the most promising ones with the
+// wrapped evaluator. It is safe for concurrent use by multiple goroutines.
+//
+// Evaluator implements evolve.ReportingEvaluator, the engine thus reports the
+// surrogate statistics of every generation in the population stats.
+//
+// Fitness scores computed by the real fitness function are remembered, so that
+// candidates surviving to the next generation, such as elites, keep their true
+// fitness. As for evolve.FitnessCache, this requires the real fitness
+// evaluations to be isolated and repeatable.
+type Evaluator struct {
+	wrapped  evolve.Evaluator
+	features func(interface{}) []float64
+	model    Model
+	control  Controller
+	key      func(interface{}) interface{}
+
+	mu     sync.Mutex
+	gen    *generation
+	known  map[interface{}]float64 // true fitness by candidate key
+	stats  Stats
+	errsum float64 // sum of absolute errors in the current generation
+	sqsum  float64 // sum of squared errors in the current generation
+	nerrs  int
+}
+
+// generation holds the pre-screening results for a generation.
+type generation struct {
+	once sync.Once
+	num  int
+
+	// predictions of the candidates that are not evaluated, and of those that
+	// are, for error measurement. Candidates that are not in pred nor in
+	// eval are evaluated.
+	pred map[interface{}]float64
+	eval map[interface{}]float64
+}
+
+// New returns a surrogate Evaluator.
+//
+// wrapped is the real, expensive, fitness evaluator, features extracts the
+// feature vector of a candidate and model is the surrogate model. By default,
+// the evolution control is Schedule{Warmup: 1, Every: 10, Ratio: 0.2}.
+func New(wrapped evolve.Evaluator, features func(cand interface{}) []float64, model Model, options ...func(*Evaluator) error) (*Evaluator, error) {
+	e := &Evaluator{
+		wrapped:  wrapped,
+		features: features,
+		model:    model,
+		control:  Schedule{Warmup: 1, Every: 10, Ratio: 0.2},
+		key:      defaultKey,
+		known:    make(map[interface{}]float64),
+	}
+	e.stats.MAE, e.stats.RMSE = math.NaN(), math.NaN()
+	for _, opt := range options {
+		if err := opt(e); err != nil {
+			return nil, err
+		}
+	}
+	return e, nil
+}
+
+func defaultKey(cand interface{}) interface{} {
+	if key, ok := evolve.CandidateKey(cand); ok {
+		return key
+	}
+	return cand
+}
+
+// Control sets the evolution control.
+func Control(c Controller) func(*Evaluator) error {
+	return func(e *Evaluator) error {
+		if c == nil {
+			return errors.New("surrogate: nil controller")
+		}
+		e.control = c
+		return nil
+	}
+}
+
+// Key sets the function returning the key identifying a candidate. Keys must
+// be comparable. By default, candidates are keyed by the encoding of their
+// content (see evolve.CandidateKey) or, if it can't be encoded, by themselves.
+func Key(key func(cand interface{}) interface{}) func(*Evaluator) error {
+	return func(e *Evaluator) error {
+		e.key = key
+		return nil
+	}
+}
+
+// Fitness returns either the true fitness of cand, computed by the wrapped
+// evaluator, or the fitness predicted by the model.
+//
+// The first call in a generation triggers the pre-screening of all the
+// candidates of pop, which must thus be the whole population being evaluated,
+// as it is when Fitness is called by evolve.EvaluatePopulation. If
+// StartGeneration has not been called, the candidates are considered to be
+// part of generation 0.
+func (e *Evaluator) Fitness(cand interface{}, pop []interface{}) float64 {
+	key := e.key(cand)
+
+	e.mu.Lock()
+	if fitness, ok := e.known[key]; ok {
+		e.mu.Unlock()
+		return fitness
+	}
+	if e.gen == nil {
+		e.gen = &generation{}
+	}
+	gen := e.gen
+	e.mu.Unlock()
+
+	gen.once.Do(func() { e.prescreen(gen, pop) })
+
+	if fitness, ok := gen.pred[key]; ok {
+		e.mu.Lock()
+		e.stats.Predicted++
+		e.mu.Unlock()
+		return fitness
+	}
+
+	fitness := e.wrapped.Fitness(cand, pop)
+	x := e.features(cand)
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.known[key] = fitness
+	e.stats.TrueEvals++
+	e.model.Add(x, fitness)
+	if pred, ok := gen.eval[key]; ok && gen == e.gen {
+		diff := pred - fitness
+		e.errsum += math.Abs(diff)
+		e.sqsum += diff * diff
+		e.nerrs++
+	}
+	return fitness
+}
+
+// StartGeneration implements evolve.GenerationalEvaluator. It closes the
+// previous generation, if any, so that the next evaluated population gets
+// pre-screened.
+func (e *Evaluator) StartGeneration(gen int) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.gen != nil {
+		if e.gen.num == gen {
+			return
+		}
+		e.stats.Generations++
+		e.stats.Checked = e.nerrs
+		e.stats.MAE, e.stats.RMSE = e.errors()
+		e.errsum, e.sqsum, e.nerrs = 0, 0, 0
+	}
+	e.gen = &generation{num: gen}
+}
+
+// errors returns the mean absolute error and root mean square error of the
+// predictions checked so far in the current generation. e.mu must be held.
+func (e *Evaluator) errors() (mae, rmse float64) {
+	if e.nerrs == 0 {
+		return math.NaN(), math.NaN()
+	}
+	return e.errsum / float64(e.nerrs), math.Sqrt(e.sqsum / float64(e.nerrs))
+}
+
+// prescreen predicts the fitness of all candidates of pop that don't have a
+// known fitness, and decides which ones are evaluated.
+func (e *Evaluator) prescreen(gen *generation, pop []interface{}) {
+	type prediction struct {
+		key     interface{}
+		fitness float64
+	}
+
+	frac := math.Max(0, math.Min(1, e.control.Fraction(gen.num)))
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	var preds []prediction
+	seen := make(map[interface{}]bool)
+	for _, cand := range pop {
+		key := e.key(cand)
+		if _, ok := e.known[key]; ok || seen[key] {
+			continue
+		}
+		seen[key] = true
+		if e.stats.TrueEvals == 0 {
+			// Nothing to predict with, all candidates are evaluated.
+			continue
+		}
+		preds = append(preds, prediction{key: key, fitness: e.model.Predict(e.features(cand))})
+	}
+
+	gen.pred = make(map[interface{}]float64)
+	gen.eval = make(map[interface{}]float64)
+	if len(preds) == 0 {
+		return
+	}
+
+	// Rank the unknown candidates by predicted fitness, fittest first.
+	natural := e.wrapped.IsNatural()
+	sort.SliceStable(preds, func(i, j int) bool {
+		if natural {
+			return preds[i].fitness > preds[j].fitness
+		}
+		return preds[i].fitness < preds[j].fitness
+	})
+
+	neval := int(math.Ceil(frac * float64(len(preds))))
+	for i, p := range preds {
+		if i < neval {
+			gen.eval[p.key] = p.fitness
+		} else {
+			// Fitness scores must be non-negative.
+			gen.pred[p.key] = math.Max(0, p.fitness)
+		}
+	}
+}
+
+// IsNatural specifies whether this evaluator generates 'natural' fitness
+// scores or not.
+func (e *Evaluator) IsNatural() bool { return e.wrapped.IsNatural() }
+
+// Stats returns the surrogate statistics. It is safe to call Stats
+// concurrently with Fitness, for example from an observer.
+func (e *Evaluator) Stats() Stats {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.stats
+}
+
+// EvaluatorStats implements evolve.ReportingEvaluator. Contrary to Stats, the
+// model errors are those measured so far in the current generation, which is
+// complete when the engine computes the population stats. The returned keys
+// are:
+//   - "surrogate_mae" and "surrogate_rmse", the model errors, NaN if no
+//     prediction has been checked,
+//   - "surrogate_checked", the number of checked predictions,
+//   - "surrogate_true_evals", the total number of real fitness evaluations,
+//   - "surrogate_predicted", the total number of predicted fitness scores.
+func (e *Evaluator) EvaluatorStats() map[string]float64 {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	mae, rmse := e.errors()
+	return map[string]float64{
+		"surrogate_mae":        mae,
+		"surrogate_rmse":       rmse,
+		"surrogate_checked":    float64(e.nerrs),
+		"surrogate_true_evals": float64(e.stats.TrueEvals),
+		"surrogate_predicted":  float64(e.stats.Predicted),
+	}
+}
+
+// String returns a short description of the surrogate statistics.
+func (s Stats) String() string {
+	return fmt.Sprintf("surrogate: %d true evaluations, %d predictions, MAE=%.4g, RMSE=%.4g",
+		s.TrueEvals, s.Predicted, s.MAE, s.RMSE)
+}
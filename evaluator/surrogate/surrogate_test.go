@@ -0,0 +1,141 @@
+package surrogate
+
+import (
+	"math"
+	"math/rand"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/arl/evolve"
+)
+
+// sphere is the sum of squares of the candidate components, a non-natural
+// fitness. It counts its evaluations.
+type sphere struct{ count int64 }
+
+func (s *sphere) Fitness(cand interface{}, _ []interface{}) float64 {
+	atomic.AddInt64(&s.count, 1)
+	var sum float64
+	for _, x := range cand.([]float64) {
+		sum += x * x
+	}
+	return sum
+}
+
+func (*sphere) IsNatural() bool { return false }
+
+func identity(cand interface{}) []float64 { return cand.([]float64) }
+
+func randomPop(rng *rand.Rand, n int) []interface{} {
+	pop := make([]interface{}, n)
+	for i := range pop {
+		pop[i] = []float64{rng.Float64()*4 - 2, rng.Float64()*4 - 2}
+	}
+	return pop
+}
+
+func TestSchedule(t *testing.T) {
+	s := Schedule{Warmup: 2, Every: 3, Ratio: 0.25}
+	want := []float64{1, 1, 0.25, 0.25, 1, 0.25, 0.25, 1}
+	for gen, w := range want {
+		assert.Equalf(t, w, s.Fraction(gen), "Fraction(%d)", gen)
+	}
+}
+
+func TestEvaluator(t *testing.T) {
+	rng := rand.New(rand.NewSource(99))
+	real := &sphere{}
+	eval, err := New(real, identity, NewKNN(3), Control(Schedule{Warmup: 1, Ratio: 0.2}))
+	require.NoError(t, err)
+	assert.False(t, eval.IsNatural())
+
+	// The first generation is fully evaluated.
+	pop0 := randomPop(rng, 100)
+	eval.StartGeneration(0)
+	evolve.EvaluatePopulation(pop0, eval, true)
+	assert.Equal(t, int64(100), real.count)
+
+	// In the next generation, only 20% of the new candidates are evaluated.
+	// Half of the population survived, their fitness is already known.
+	pop1 := append(randomPop(rng, 50), pop0[:50]...)
+	eval.StartGeneration(1)
+	evpop := evolve.EvaluatePopulation(pop1, eval, true)
+	assert.Equal(t, int64(110), real.count)
+
+	// Survivors keep their true fitness.
+	for _, ind := range evpop[50:] {
+		assert.Equal(t, (&sphere{}).Fitness(ind.Candidate, nil), ind.Fitness)
+	}
+
+	// The errors of the current generation are reported to the engine.
+	rstats := eval.EvaluatorStats()
+	assert.Equal(t, 10.0, rstats["surrogate_checked"])
+	assert.Equal(t, 110.0, rstats["surrogate_true_evals"])
+	assert.Equal(t, 40.0, rstats["surrogate_predicted"])
+	assert.Less(t, rstats["surrogate_mae"], 1.0)
+	assert.GreaterOrEqual(t, rstats["surrogate_rmse"], rstats["surrogate_mae"])
+
+	// Starting a new generation closes the previous one, in which 2 of the 10
+	// new candidates are really evaluated.
+	eval.StartGeneration(2)
+	evolve.EvaluatePopulation(randomPop(rng, 10), eval, true)
+	stats := eval.Stats()
+	assert.Equal(t, 2, stats.Generations)
+	assert.Equal(t, int64(112), stats.TrueEvals)
+	assert.Equal(t, int64(48), stats.Predicted)
+	assert.Equal(t, 10, stats.Checked)
+	assert.False(t, math.IsNaN(stats.MAE))
+	assert.Less(t, stats.MAE, 1.0)
+	assert.GreaterOrEqual(t, stats.RMSE, stats.MAE)
+	assert.Equal(t, rstats["surrogate_mae"], stats.MAE)
+}
+
+func TestEvaluatorPrescreening(t *testing.T) {
+	real := &sphere{}
+	eval, err := New(real, identity, NewKNN(1), Control(Schedule{Warmup: 1, Ratio: 0.5}))
+	require.NoError(t, err)
+
+	// Train the model.
+	eval.StartGeneration(0)
+	evolve.EvaluatePopulation([]interface{}{
+		[]float64{0, 0}, []float64{1, 1}, []float64{2, 2}, []float64{3, 3},
+	}, eval, false)
+
+	// The 2 candidates predicted as the fittest (i.e nearest to the origin)
+	// must be evaluated, the 2 others predicted.
+	pop := []interface{}{
+		[]float64{2.9, 3}, []float64{0.1, 0}, []float64{2, 1.9}, []float64{1, 1.1},
+	}
+	eval.StartGeneration(1)
+	evpop := evolve.EvaluatePopulation(pop, eval, false)
+	assert.Equal(t, int64(6), real.count)
+	assert.Equal(t, 18.0, evpop[0].Fitness, "predicted from nearest neighbour (3, 3)")
+	assert.InDelta(t, 0.01, evpop[1].Fitness, 1e-12, "really evaluated")
+	assert.Equal(t, 8.0, evpop[2].Fitness, "predicted from nearest neighbour (2, 2)")
+	assert.InDelta(t, 2.21, evpop[3].Fitness, 1e-12, "really evaluated")
+}
+
+func TestEvaluatorReusedPopulation(t *testing.T) {
+	real := &sphere{}
+	eval, err := New(real, identity, NewKNN(1), Control(Schedule{Warmup: 1, Ratio: 0.5}))
+	require.NoError(t, err)
+
+	pop := []interface{}{
+		[]float64{0, 0}, []float64{1, 1}, []float64{2, 2}, []float64{3, 3},
+	}
+	eval.StartGeneration(0)
+	evolve.EvaluatePopulation(pop, eval, false)
+
+	// New candidates, in the same slice, must be pre-screened.
+	pop[0], pop[1], pop[2], pop[3] = []float64{2.9, 3}, []float64{0.1, 0}, []float64{2, 1.9}, []float64{1, 1.1}
+	eval.StartGeneration(1)
+	evolve.EvaluatePopulation(pop, eval, false)
+	assert.Equal(t, int64(6), real.count)
+
+	// Notifying the same generation again has no effect.
+	eval.StartGeneration(1)
+	assert.Equal(t, 1, eval.Stats().Generations)
+}
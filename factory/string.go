@@ -42,11 +42,11 @@ func NewString(alphabet string, length int) (*String, error) {
 	}, nil
 }
 
-// New creates a random string.
+// New creates a random string, in which runes are chosen with rng.
 func (gen *String) New(rng *rand.Rand) interface{} {
 	b := make([]byte, gen.length)
 	for i := 0; i < gen.length; i++ {
-		b[i] = gen.alphabet[rand.Int31n(int32(len(gen.alphabet)))]
+		b[i] = gen.alphabet[rng.Int31n(int32(len(gen.alphabet)))]
 	}
 	return string(b)
 }
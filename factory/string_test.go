@@ -60,6 +60,17 @@ func TestStringFactory(t *testing.T) {
 	}
 }
 
+func TestStringFactoryReproducible(t *testing.T) {
+	factory, err := NewString("ABCdefg", 32)
+	require.NoError(t, err)
+
+	s1 := factory.New(rand.New(rand.NewSource(99)))
+	s2 := factory.New(rand.New(rand.NewSource(99)))
+	if s1 != s2 {
+		t.Errorf("strings generated with the same seed differ: %q != %q", s1, s2)
+	}
+}
+
 var sink interface{}
 
 func BenchmarkNewString(b *testing.B) {
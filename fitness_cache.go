@@ -1,6 +1,11 @@
 package evolve
 
-import "sync"
+import (
+	"container/list"
+	"fmt"
+	"reflect"
+	"sync"
+)
 
 // FitnessCache provides caching for any Evaluator implementation. The
 // results of fitness evaluations are stored in a cache so that if the same
@@ -20,11 +25,54 @@ import "sync"
 // candidates are evaluated against the other members of the population.  So
 // unless the fitness evaluator ignores the second parameter to the
 // Evaluator.Fitness method, caching must not be used.
+//
+// Candidates are identified by the key returned by Key. By default, candidates
+// which content can be encoded (see CandidateKey) are keyed by the encoding
+// of their content, so that slices and pointers to bit strings are cached by
+// content, not by identity. Other candidates are used directly as keys, they
+// must then be comparable.
+//
+// The zero value, with Wrapped set, is an unbounded cache. A FitnessCache must
+// not be copied after first use.
 type FitnessCache struct {
 
 	// Wrapped is the fitness evaluator for which we want to provide caching
 	Wrapped Evaluator
-	cache   sync.Map
+
+	// Key, if not nil, returns the key identifying a candidate in the cache.
+	// Keys must be comparable. Key must be set before first use.
+	Key func(cand interface{}) interface{}
+
+	// Capacity is the maximum number of fitness scores kept in the cache. Once
+	// the cache is full, the least recently used score is evicted. 0 means
+	// unbounded. Capacity must be set before first use.
+	Capacity int
+
+	mu      sync.Mutex
+	entries map[interface{}]*list.Element
+	lru     list.List // of *cacheEntry, most recently used first
+	stats   CacheStats
+}
+
+type cacheEntry struct {
+	key     interface{}
+	fitness float64
+}
+
+// CacheStats holds the statistics of a fitness cache.
+type CacheStats struct {
+	Hits      int64 // number of fitness scores found in the cache
+	Misses    int64 // number of fitness scores computed by the wrapped evaluator
+	Evictions int64 // number of fitness scores evicted from the cache
+	Len       int   // number of fitness scores currently in the cache
+}
+
+// HitRatio returns the proportion of lookups that were cache hits.
+func (cs CacheStats) HitRatio() float64 {
+	if cs.Hits+cs.Misses == 0 {
+		return 0
+	}
+	return float64(cs.Hits) / float64(cs.Hits+cs.Misses)
 }
 
 // Fitness calculates a fitness score for the given candidate.
@@ -34,17 +82,75 @@ type FitnessCache struct {
 // specified candidate that score is returned without delegating to the wrapped
 // evaluator.
 func (c *FitnessCache) Fitness(cand interface{}, pop []interface{}) float64 {
-	var fitness float64
-	val, ok := c.cache.Load(cand)
-	if ok {
-		fitness = val.(float64)
-	} else {
-		fitness = c.Wrapped.Fitness(cand, pop)
-		c.cache.Store(cand, fitness)
+	key := c.key(cand)
+
+	c.mu.Lock()
+	if c.entries == nil {
+		c.entries = make(map[interface{}]*list.Element)
+	}
+	if elem, ok := c.entries[key]; ok {
+		c.lru.MoveToFront(elem)
+		c.stats.Hits++
+		fitness := elem.Value.(*cacheEntry).fitness
+		c.mu.Unlock()
+		return fitness
+	}
+	c.stats.Misses++
+	c.mu.Unlock()
+
+	// Evaluate outside of the lock, so that concurrent evaluations of
+	// different candidates are not serialized.
+	fitness := c.Wrapped.Fitness(cand, pop)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if elem, ok := c.entries[key]; ok {
+		// The same candidate has been evaluated concurrently.
+		c.lru.MoveToFront(elem)
+		return fitness
+	}
+	c.entries[key] = c.lru.PushFront(&cacheEntry{key: key, fitness: fitness})
+	if c.Capacity > 0 && c.lru.Len() > c.Capacity {
+		oldest := c.lru.Back()
+		c.lru.Remove(oldest)
+		delete(c.entries, oldest.Value.(*cacheEntry).key)
+		c.stats.Evictions++
 	}
 	return fitness
 }
 
+func (c *FitnessCache) key(cand interface{}) interface{} {
+	if c.Key != nil {
+		return c.Key(cand)
+	}
+	if key, ok := CandidateKey(cand); ok {
+		return key
+	}
+	if cand != nil && !reflect.TypeOf(cand).Comparable() {
+		panic(fmt.Sprintf("FitnessCache: can't key candidates of type %T, implement Keyer or set FitnessCache.Key", cand))
+	}
+	return cand
+}
+
 // IsNatural specifies whether this evaluator generates 'natural' fitness
 // scores or not.
 func (c *FitnessCache) IsNatural() bool { return c.Wrapped.IsNatural() }
+
+// Stats returns the cache statistics. It is safe to call Stats concurrently
+// with Fitness, for example from an observer.
+func (c *FitnessCache) Stats() CacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	stats := c.stats
+	stats.Len = c.lru.Len()
+	return stats
+}
+
+// Reset empties the cache and resets its statistics.
+func (c *FitnessCache) Reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries = nil
+	c.lru.Init()
+	c.stats = CacheStats{}
+}
@@ -1,6 +1,13 @@
 package evolve
 
-import "testing"
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/arl/evolve/pkg/bitstring"
+)
 
 // incrEvaluator breaks the rules for the caching evaluator in that it is not
 // repeatable (it returns different values when invoked multiple times for the
@@ -58,3 +65,75 @@ func TestFitnessCacheNaturalness(t *testing.T) {
 		t.Errorf("fitness cache should not be natural if wrapped is not natural")
 	}
 }
+
+func TestFitnessCacheContentKeys(t *testing.T) {
+	bs1, _ := bitstring.MakeFromString("10110")
+	bs2, _ := bitstring.MakeFromString("10110")
+
+	tests := []struct {
+		name       string
+		cand, same interface{}
+	}{
+		{"[]int", []int{1, 2, 3}, []int{1, 2, 3}},
+		{"[]byte", []byte("abc"), []byte("abc")},
+		{"*bitstring.Bitstring", bs1, bs2},
+		{"Keyer", keyer{1, 2}, keyer{1, 2}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			eval := FitnessCache{Wrapped: &incrEvaluator{natural: true}}
+			if fitness := eval.Fitness(tt.cand, nil); fitness != 1 {
+				t.Errorf("wrong fitness, want 1, got %v", fitness)
+			}
+			// A different candidate with the same content should hit the cache.
+			if fitness := eval.Fitness(tt.same, nil); fitness != 1 {
+				t.Errorf("wrong fitness, want 1 (cached), got %v", fitness)
+			}
+		})
+	}
+}
+
+func TestFitnessCacheExactKeys(t *testing.T) {
+	// Keys are the full encoding of the content, not a hash of it that may
+	// collide.
+	var eval FitnessCache
+	for _, cand := range []interface{}{"abc", []byte("abc"), []int{1, 2, 3}} {
+		want, _ := CandidateKey(cand)
+		assert.Equal(t, want, eval.key(cand))
+	}
+}
+
+func TestFitnessCacheUnhashable(t *testing.T) {
+	eval := FitnessCache{Wrapped: &incrEvaluator{natural: true}}
+	assert.Panics(t, func() { eval.Fitness(map[string]int{}, nil) })
+
+	// Unless a key function is provided.
+	eval = FitnessCache{
+		Wrapped: &incrEvaluator{natural: true},
+		Key:     func(cand interface{}) interface{} { return len(cand.(map[string]int)) },
+	}
+	assert.Equal(t, 1.0, eval.Fitness(map[string]int{"a": 1}, nil))
+	assert.Equal(t, 1.0, eval.Fitness(map[string]int{"b": 2}, nil))
+	assert.Equal(t, 2.0, eval.Fitness(map[string]int{}, nil))
+}
+
+func TestFitnessCacheCapacity(t *testing.T) {
+	eval := FitnessCache{Wrapped: &incrEvaluator{natural: true}, Capacity: 2}
+
+	assert.Equal(t, 1.0, eval.Fitness("a", nil))
+	assert.Equal(t, 2.0, eval.Fitness("b", nil))
+	assert.Equal(t, 1.0, eval.Fitness("a", nil)) // "a" is now the most recently used
+	assert.Equal(t, 3.0, eval.Fitness("c", nil)) // evicts "b"
+	assert.Equal(t, 1.0, eval.Fitness("a", nil))
+	assert.Equal(t, 4.0, eval.Fitness("b", nil)) // "b" has been evicted
+
+	assert.Equal(t, CacheStats{Hits: 2, Misses: 4, Evictions: 2, Len: 2}, eval.Stats())
+	assert.Equal(t, 2.0/6.0, eval.Stats().HitRatio())
+
+	eval.Reset()
+	assert.Equal(t, CacheStats{}, eval.Stats())
+}
+
+type keyer struct{ a, b int }
+
+func (k keyer) CacheKey() string { return fmt.Sprint(k.a, k.b) }
@@ -0,0 +1,150 @@
+package evolve
+
+import (
+	"fmt"
+	"math/rand"
+	"reflect"
+	"strings"
+)
+
+// Origin describes how an individual has been born.
+//
+// Origins are only recorded when the engine tracks the lineage of the
+// individuals, otherwise the Origin field of the individuals is nil.
+type Origin struct {
+	// ID uniquely identifies the individual. Elites keep their ID from one
+	// generation to the next.
+	ID uint64
+
+	// Parents holds the IDs of the parents of the individual, nil for the
+	// individuals of the initial population.
+	Parents []uint64
+
+	// Op names the operators that produced the individual from its parents,
+	// joined by '+' when several operators of a pipeline modified it, e.g
+	// "crossover+mutation". Op is empty for the individuals of the initial
+	// population and for unmodified copies of their single parent.
+	Op string
+
+	// Born is the (zero-based) number of the generation in which the
+	// individual has been born.
+	Born int
+}
+
+// Derivation describes how an offspring returned by an operator has been
+// derived from the candidates given to the operator.
+type Derivation struct {
+	// Parents holds the indexes, in the operator input, of the candidates the
+	// offspring has been derived from.
+	Parents []int
+
+	// Op names the operators that modified the offspring. Op is empty if the
+	// offspring is an unmodified copy of its single parent.
+	Op string
+}
+
+// A TracingOperator is an Operator that reports how its offspring are derived
+// from the selected candidates.
+type TracingOperator interface {
+	Operator
+
+	// ApplyTrace is equivalent to Apply, it must consume the same random
+	// numbers and return the same offspring, but it also returns the
+	// derivation of each offspring.
+	ApplyTrace([]interface{}, *rand.Rand) ([]interface{}, []Derivation)
+}
+
+// ApplyTrace applies op to sel and returns the offspring along with their
+// derivations.
+//
+// If op is not a TracingOperator, and if it returns as many offspring as
+// candidates in sel, the i-th offspring is assumed to derive from sel[i], in
+// which case it is considered modified by op if it's not the same candidate
+// (see SameCandidate). Otherwise the parents of the offspring are unknown.
+func ApplyTrace(op Operator, sel []interface{}, rng *rand.Rand) ([]interface{}, []Derivation) {
+	if top, ok := op.(TracingOperator); ok {
+		return top.ApplyTrace(sel, rng)
+	}
+
+	off := op.Apply(sel, rng)
+	derivs := make([]Derivation, len(off))
+	name := OperatorName(op)
+	for i := range off {
+		if len(off) != len(sel) {
+			derivs[i].Op = name
+			continue
+		}
+		derivs[i].Parents = []int{i}
+		if !SameCandidate(off[i], sel[i]) {
+			derivs[i].Op = name
+		}
+	}
+	return off, derivs
+}
+
+// OperatorName returns the name of op, as reported in derivations: the
+// result of its String method if it has one, or the name of its type.
+func OperatorName(op Operator) string {
+	if s, ok := op.(fmt.Stringer); ok {
+		return s.String()
+	}
+	return strings.TrimPrefix(fmt.Sprintf("%T", op), "*")
+}
+
+// ComposeDerivations returns the derivations of offspring obtained by
+// applying an operator, whose derivations are next, to the offspring of
+// another operator, whose derivations are prev. The returned derivations
+// refer to the candidates given to the first operator.
+func ComposeDerivations(prev, next []Derivation) []Derivation {
+	derivs := make([]Derivation, len(next))
+	for i, d := range next {
+		var ops []string
+		seen := make(map[int]bool)
+		for _, p := range d.Parents {
+			if p < 0 || p >= len(prev) {
+				continue
+			}
+			for _, pp := range prev[p].Parents {
+				if !seen[pp] {
+					seen[pp] = true
+					derivs[i].Parents = append(derivs[i].Parents, pp)
+				}
+			}
+			ops = appendOps(ops, prev[p].Op)
+		}
+		ops = appendOps(ops, d.Op)
+		derivs[i].Op = strings.Join(ops, "+")
+	}
+	return derivs
+}
+
+// appendOps appends to ops the names of the operators in op that are not
+// already in ops.
+func appendOps(ops []string, op string) []string {
+	if op == "" {
+		return ops
+	}
+next:
+	for _, name := range strings.Split(op, "+") {
+		for _, o := range ops {
+			if o == name {
+				continue next
+			}
+		}
+		ops = append(ops, name)
+	}
+	return ops
+}
+
+// SameCandidate reports whether a and b are the same candidate, or have the
+// same content.
+//
+// The contents of candidates that can be keyed (see CandidateKey) are compared
+// by key, those of other candidates with reflect.DeepEqual.
+func SameCandidate(a, b interface{}) bool {
+	if ka, ok := CandidateKey(a); ok {
+		kb, ok := CandidateKey(b)
+		return ok && ka == kb
+	}
+	return reflect.DeepEqual(a, b)
+}
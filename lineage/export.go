@@ -0,0 +1,124 @@
+package lineage
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+)
+
+// WriteDOT writes to w the ancestry of the individual with the given ID, up
+// to depth generations of ancestors (depth <= 0 means no limit), as a Graphviz
+// DOT directed graph.
+//
+// Every individual is a node, labelled with its ID, birth generation, fitness
+// and candidate if recorded. Edges go from parents to offspring and are
+// labelled with the operators that produced the offspring. The individual
+// whose ancestry is written is drawn in bold.
+func (g *Genealogy) WriteDOT(w io.Writer, id uint64, depth int) error {
+	recs, err := g.Ancestry(id, depth)
+	if err != nil {
+		return err
+	}
+	known := make(map[uint64]bool, len(recs))
+	for _, r := range recs {
+		known[r.ID] = true
+	}
+
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "digraph ancestry {\n")
+	fmt.Fprintf(bw, "\tnode [shape=box];\n")
+	for _, r := range recs {
+		label := fmt.Sprintf("#%d gen %d\nfitness %v", r.ID, r.Born, r.Fitness)
+		if r.Candidate != "" {
+			label += "\n" + r.Candidate
+		}
+		style := ""
+		if r.ID == id {
+			style = ", style=bold"
+		}
+		fmt.Fprintf(bw, "\tn%d [label=%s%s];\n", r.ID, dotQuote(label), style)
+	}
+	for _, r := range recs {
+		op := r.Op
+		if op == "" {
+			op = "copy"
+		}
+		for _, p := range r.Parents {
+			if known[p] {
+				fmt.Fprintf(bw, "\tn%d -> n%d [label=%s];\n", p, r.ID, dotQuote(op))
+			}
+		}
+	}
+	fmt.Fprintf(bw, "}\n")
+	return bw.Flush()
+}
+
+// dotQuote returns s as a DOT quoted string.
+func dotQuote(s string) string {
+	return `"` + dotEscaper.Replace(s) + `"`
+}
+
+var dotEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// jsonRecord is the JSON representation of a record.
+type jsonRecord struct {
+	ID        uint64   `json:"id"`
+	Parents   []uint64 `json:"parents"`
+	Op        string   `json:"op,omitempty"`
+	Born      int      `json:"born"`
+	Fitness   *float64 `json:"fitness"`
+	Candidate string   `json:"candidate,omitempty"`
+}
+
+// WriteJSON writes to w the ancestry of the individual with the given ID, up
+// to depth generations of ancestors (depth <= 0 means no limit), as a JSON
+// object.
+//
+// Since individuals may share ancestors, the ancestry is not written as a
+// tree, but as the flat list of the individuals, from the oldest to the
+// youngest, each referring to its parents by ID:
+//
+//	{
+//	  "root": 12,
+//	  "individuals": [
+//	    {"id": 3, "parents": [], "born": 0, "fitness": 1},
+//	    {"id": 12, "parents": [3], "op": "mutation", "born": 1, "fitness": 2}
+//	  ]
+//	}
+//
+// A fitness that is not a number is written as null.
+func (g *Genealogy) WriteJSON(w io.Writer, id uint64, depth int) error {
+	recs, err := g.Ancestry(id, depth)
+	if err != nil {
+		return err
+	}
+
+	out := struct {
+		Root        uint64       `json:"root"`
+		Individuals []jsonRecord `json:"individuals"`
+	}{Root: id, Individuals: make([]jsonRecord, len(recs))}
+
+	for i, r := range recs {
+		jr := jsonRecord{
+			ID:        r.ID,
+			Parents:   r.Parents,
+			Op:        r.Op,
+			Born:      r.Born,
+			Candidate: r.Candidate,
+		}
+		if jr.Parents == nil {
+			jr.Parents = []uint64{}
+		}
+		if f := r.Fitness; !math.IsNaN(f) && !math.IsInf(f, 0) {
+			jr.Fitness = &f
+		}
+		out.Individuals[i] = jr
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(out)
+}
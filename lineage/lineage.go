@@ -0,0 +1,212 @@
+// Package lineage records the genealogy of the individuals of an evolution,
+// and exports the ancestry of any individual as a Graphviz DOT graph or as
+// JSON.
+package lineage
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/arl/evolve"
+)
+
+// A Record is the genealogical record of an individual.
+type Record struct {
+	evolve.Origin
+
+	// Fitness is the fitness score of the individual.
+	Fitness float64
+
+	// Candidate is the formatted candidate, empty unless the genealogy has
+	// been created with the Format option.
+	Candidate string
+}
+
+// A Genealogy records the origin of the individuals, it is filled by the
+// engine when it's given to the engine.Lineage option.
+//
+// Records are kept for all the individuals born during the evolution, unless
+// they are pruned (see Prune and Trim). The engine prunes the genealogy every
+// generation, and optionally trims it (see engine.Lineage). A Genealogy is
+// safe for concurrent use, for example to export the ancestry of the best
+// candidate from an observer.
+type Genealogy struct {
+	mu     sync.Mutex
+	lastID uint64
+	recs   map[uint64]*Record
+	format func(interface{}) string
+}
+
+// New creates an empty genealogy.
+func New(options ...func(*Genealogy) error) (*Genealogy, error) {
+	g := &Genealogy{recs: make(map[uint64]*Record)}
+	for _, opt := range options {
+		if err := opt(g); err != nil {
+			return nil, err
+		}
+	}
+	return g, nil
+}
+
+// Format makes the genealogy record the candidates, formatted with f. If f is
+// nil, candidates are formatted with fmt.Sprint.
+//
+// Formatted candidates appear in the exported ancestry, they may however
+// increase the memory used by the genealogy considerably.
+func Format(f func(interface{}) string) func(*Genealogy) error {
+	return func(g *Genealogy) error {
+		if f == nil {
+			f = func(c interface{}) string { return fmt.Sprint(c) }
+		}
+		g.format = f
+		return nil
+	}
+}
+
+// Add records the birth of ind, in generation born, from the parents whose IDs
+// are given. op names the operators that produced ind. A new ID is assigned to
+// the individual, and its Origin is set.
+func (g *Genealogy) Add(ind *evolve.Individual, parents []uint64, op string, born int) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.lastID++
+	ind.Origin = &evolve.Origin{
+		ID:      g.lastID,
+		Parents: parents,
+		Op:      op,
+		Born:    born,
+	}
+	rec := &Record{Origin: *ind.Origin, Fitness: ind.Fitness}
+	if g.format != nil {
+		rec.Candidate = g.format(ind.Candidate)
+	}
+	g.recs[rec.ID] = rec
+}
+
+// Len returns the number of records in the genealogy.
+func (g *Genealogy) Len() int {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return len(g.recs)
+}
+
+// Get returns the record of the individual with the given ID. ok is false if
+// there's no such record.
+func (g *Genealogy) Get(id uint64) (rec Record, ok bool) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	r, ok := g.recs[id]
+	if !ok {
+		return Record{}, false
+	}
+	return *r, true
+}
+
+// Ancestry returns the records of the individual with the given ID and of its
+// ancestors, up to depth generations of ancestors (depth <= 0 means no limit),
+// ordered by ID, that is from the oldest to the youngest.
+//
+// Ancestors whose records have been pruned are omitted.
+func (g *Genealogy) Ancestry(id uint64, depth int) ([]Record, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if _, ok := g.recs[id]; !ok {
+		return nil, fmt.Errorf("no individual with ID %d", id)
+	}
+
+	var recs []Record
+	seen := map[uint64]bool{id: true}
+	level := []uint64{id}
+	for d := 0; len(level) > 0; d++ {
+		var next []uint64
+		for _, id := range level {
+			r, ok := g.recs[id]
+			if !ok {
+				continue
+			}
+			recs = append(recs, *r)
+			if depth > 0 && d == depth {
+				continue
+			}
+			for _, p := range r.Parents {
+				if !seen[p] {
+					seen[p] = true
+					next = append(next, p)
+				}
+			}
+		}
+		level = next
+	}
+
+	sort.Slice(recs, func(i, j int) bool { return recs[i].ID < recs[j].ID })
+	return recs, nil
+}
+
+// Trim removes the records of the individuals born before generation born,
+// except those of the individuals in pop, so that the genealogy only holds the
+// records of the last generations. It returns the number of removed records.
+//
+// Contrary to Prune, Trim doesn't keep the ancestors of the individuals in
+// pop: their ancestry is cut at generation born. Individuals without origin
+// are ignored.
+func (g *Genealogy) Trim(pop evolve.Population, born int) int {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	alive := make(map[uint64]bool, len(pop))
+	for _, ind := range pop {
+		if ind.Origin != nil {
+			alive[ind.Origin.ID] = true
+		}
+	}
+
+	n := 0
+	for id, r := range g.recs {
+		if r.Born < born && !alive[id] {
+			delete(g.recs, id)
+			n++
+		}
+	}
+	return n
+}
+
+// Prune removes the records of the individuals that are neither in pop nor
+// ancestors of the individuals in pop, so that the genealogy doesn't grow
+// indefinitely. It returns the number of removed records.
+func (g *Genealogy) Prune(pop evolve.Population) (int, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	alive := make(map[uint64]bool, len(pop))
+	var stack []uint64
+	for _, ind := range pop {
+		if ind.Origin == nil {
+			return 0, errors.New("individual without origin")
+		}
+		stack = append(stack, ind.Origin.ID)
+	}
+	for len(stack) > 0 {
+		id := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if alive[id] {
+			continue
+		}
+		alive[id] = true
+		if r, ok := g.recs[id]; ok {
+			stack = append(stack, r.Parents...)
+		}
+	}
+
+	n := 0
+	for id := range g.recs {
+		if !alive[id] {
+			delete(g.recs, id)
+			n++
+		}
+	}
+	return n, nil
+}
@@ -0,0 +1,139 @@
+package lineage
+
+import (
+	"bytes"
+	"encoding/json"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/arl/evolve"
+)
+
+// family returns a genealogy and the individuals of three generations:
+//
+//	1   2   3
+//	 \ / \
+//	  4   5
+//	  |
+//	  6
+func family(t *testing.T) (*Genealogy, []*evolve.Individual) {
+	g, err := New(Format(nil))
+	require.NoError(t, err)
+
+	inds := make([]*evolve.Individual, 6)
+	for i := range inds {
+		inds[i] = &evolve.Individual{Candidate: string(rune('a' + i)), Fitness: float64(i)}
+	}
+	g.Add(inds[0], nil, "", 0)
+	g.Add(inds[1], nil, "", 0)
+	g.Add(inds[2], nil, "", 0)
+	g.Add(inds[3], []uint64{1, 2}, "crossover", 1)
+	g.Add(inds[4], []uint64{2}, "mutation", 1)
+	g.Add(inds[5], []uint64{4}, "", 2)
+	return g, inds
+}
+
+func TestGenealogyAncestry(t *testing.T) {
+	g, inds := family(t)
+	assert.Equal(t, 6, g.Len())
+	assert.Equal(t, uint64(4), inds[3].Origin.ID)
+
+	ids := func(recs []Record) []uint64 {
+		var ids []uint64
+		for _, r := range recs {
+			ids = append(ids, r.ID)
+		}
+		return ids
+	}
+
+	recs, err := g.Ancestry(6, 0)
+	require.NoError(t, err)
+	assert.Equal(t, []uint64{1, 2, 4, 6}, ids(recs))
+	assert.Equal(t, "d", recs[2].Candidate)
+
+	recs, err = g.Ancestry(6, 1)
+	require.NoError(t, err)
+	assert.Equal(t, []uint64{4, 6}, ids(recs))
+
+	_, err = g.Ancestry(7, 0)
+	assert.Error(t, err)
+}
+
+func TestGenealogyPrune(t *testing.T) {
+	g, inds := family(t)
+
+	n, err := g.Prune(evolve.Population{inds[5], inds[4]})
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+	_, ok := g.Get(3)
+	assert.False(t, ok)
+	_, ok = g.Get(1)
+	assert.True(t, ok)
+
+	_, err = g.Prune(evolve.Population{{}})
+	assert.Error(t, err)
+}
+
+func TestGenealogyTrim(t *testing.T) {
+	g, inds := family(t)
+
+	// 3 is in the population, 4 isn't but is born in generation 1.
+	n := g.Trim(evolve.Population{inds[5], inds[2], {}}, 1)
+	assert.Equal(t, 2, n)
+	for id, want := range map[uint64]bool{1: false, 2: false, 3: true, 4: true, 5: true, 6: true} {
+		_, ok := g.Get(id)
+		assert.Equalf(t, want, ok, "record %d", id)
+	}
+
+	recs, err := g.Ancestry(6, 0)
+	require.NoError(t, err)
+	assert.Len(t, recs, 2)
+}
+
+func TestGenealogyWriteDOT(t *testing.T) {
+	g, _ := family(t)
+
+	var buf bytes.Buffer
+	require.NoError(t, g.WriteDOT(&buf, 4, 0))
+	assert.Equal(t, `digraph ancestry {
+	node [shape=box];
+	n1 [label="#1 gen 0\nfitness 0\na"];
+	n2 [label="#2 gen 0\nfitness 1\nb"];
+	n4 [label="#4 gen 1\nfitness 3\nd", style=bold];
+	n1 -> n4 [label="crossover"];
+	n2 -> n4 [label="crossover"];
+}
+`, buf.String())
+}
+
+func TestGenealogyWriteJSON(t *testing.T) {
+	g, inds := family(t)
+	inds[5].Fitness = math.NaN()
+	g.Add(inds[5], []uint64{6}, "", 3)
+
+	var buf bytes.Buffer
+	require.NoError(t, g.WriteJSON(&buf, 7, 0))
+
+	var got struct {
+		Root        uint64
+		Individuals []struct {
+			ID        uint64
+			Parents   []uint64
+			Op        string
+			Born      int
+			Fitness   *float64
+			Candidate string
+		}
+	}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &got))
+	assert.Equal(t, uint64(7), got.Root)
+	require.Len(t, got.Individuals, 5)
+	assert.Equal(t, []uint64{}, got.Individuals[0].Parents)
+	assert.Equal(t, "crossover", got.Individuals[2].Op)
+	assert.Equal(t, 3.0, *got.Individuals[2].Fitness)
+	assert.Nil(t, got.Individuals[4].Fitness)
+	assert.Equal(t, 3, got.Individuals[4].Born)
+}
@@ -0,0 +1,55 @@
+package evolve
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// incOdd increments odd integers and leaves the others unchanged.
+type incOdd struct{}
+
+func (incOdd) Apply(sel []interface{}, rng *rand.Rand) []interface{} {
+	off := make([]interface{}, len(sel))
+	for i, c := range sel {
+		off[i] = c.(int) + c.(int)%2
+	}
+	return off
+}
+
+func TestApplyTrace(t *testing.T) {
+	off, derivs := ApplyTrace(incOdd{}, []interface{}{1, 2, 3}, nil)
+	assert.Equal(t, []interface{}{2, 2, 4}, off)
+	assert.Equal(t, []Derivation{
+		{Parents: []int{0}, Op: "evolve.incOdd"},
+		{Parents: []int{1}},
+		{Parents: []int{2}, Op: "evolve.incOdd"},
+	}, derivs)
+}
+
+func TestComposeDerivations(t *testing.T) {
+	prev := []Derivation{
+		{Parents: []int{0, 1}, Op: "crossover"},
+		{Parents: []int{1, 0}, Op: "crossover"},
+		{Parents: []int{2}},
+	}
+	next := []Derivation{
+		{Parents: []int{0}, Op: "mutation"},
+		{Parents: []int{1}},
+		{Parents: []int{2, 0}, Op: "crossover"},
+	}
+	assert.Equal(t, []Derivation{
+		{Parents: []int{0, 1}, Op: "crossover+mutation"},
+		{Parents: []int{1, 0}, Op: "crossover"},
+		{Parents: []int{2, 0, 1}, Op: "crossover"},
+	}, ComposeDerivations(prev, next))
+}
+
+func TestSameCandidate(t *testing.T) {
+	assert.True(t, SameCandidate([]int{1, 2}, []int{1, 2}))
+	assert.False(t, SameCandidate([]int{1, 2}, []int{2, 1}))
+	assert.False(t, SameCandidate([]int{1, 2}, "ab"))
+	assert.True(t, SameCandidate(map[int]int{1: 2}, map[int]int{1: 2}))
+	assert.False(t, SameCandidate(map[int]int{1: 2}, map[int]int{1: 3}))
+}
@@ -0,0 +1,67 @@
+package observer
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/arl/evolve"
+)
+
+// A Column is a field of the records written by a Log.
+type Column struct {
+	// Name is the name of the column, the CSV header or JSON key.
+	Name string
+
+	// Value extracts the value of the column from the population stats.
+	// Supported types are int, float64, string and bool.
+	Value func(*evolve.PopulationStats) interface{}
+}
+
+// Predefined columns. Elapsed is expressed in seconds.
+var (
+	Generation = Column{"generation", func(s *evolve.PopulationStats) interface{} { return s.GenNumber }}
+	Elapsed    = Column{"elapsed", func(s *evolve.PopulationStats) interface{} { return s.Elapsed.Seconds() }}
+	Best       = Column{"best", func(s *evolve.PopulationStats) interface{} { return s.BestFitness }}
+	Mean       = Column{"mean", func(s *evolve.PopulationStats) interface{} { return s.Mean }}
+	StdDev     = Column{"stddev", func(s *evolve.PopulationStats) interface{} { return s.StdDev }}
+	Worst      = Column{"worst", func(s *evolve.PopulationStats) interface{} { return s.WorstFitness }}
+	Median     = Column{"median", func(s *evolve.PopulationStats) interface{} { return s.Median }}
+	BestEver   = Column{"best_ever", func(s *evolve.PopulationStats) interface{} { return s.BestEverFitness }}
+	Size       = Column{"size", func(s *evolve.PopulationStats) interface{} { return s.Size }}
+
+	Evaluations = Column{"evaluations", func(s *evolve.PopulationStats) interface{} { return int(s.Evaluations) }}
+	CacheHits   = Column{"cache_hits", func(s *evolve.PopulationStats) interface{} { return int(s.CacheHits) }}
+)
+
+// DefaultColumns are the columns of a Log created without the Columns option.
+var DefaultColumns = []Column{Generation, Elapsed, Best, Mean, StdDev}
+
+// Diversity returns the column holding the value of the diversity measure
+// name (see engine.MeasureDiversity).
+func Diversity(name string) Column {
+	return Column{name, func(s *evolve.PopulationStats) interface{} { return s.Diversity[name] }}
+}
+
+// Quantile returns the column holding the fitness quantile of probability p,
+// which must have been configured with engine.Quantiles. The value is NaN if
+// it has not.
+func Quantile(p float64) Column {
+	name := "q" + strconv.FormatFloat(p, 'f', -1, 64)
+	return Column{name, func(s *evolve.PopulationStats) interface{} {
+		for _, q := range s.Quantiles {
+			if q.P == p {
+				return q.Value
+			}
+		}
+		return nan
+	}}
+}
+
+// BestCandidate returns the column holding the best candidate, formatted with
+// format. If format is nil, candidates are formatted with fmt.Sprint.
+func BestCandidate(format func(cand interface{}) string) Column {
+	if format == nil {
+		format = func(cand interface{}) string { return fmt.Sprint(cand) }
+	}
+	return Column{"best_candidate", func(s *evolve.PopulationStats) interface{} { return format(s.BestCand) }}
+}
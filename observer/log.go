@@ -0,0 +1,193 @@
+// Package observer provides observers of the evolution engine.
+package observer
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+
+	"github.com/arl/evolve"
+)
+
+var nan = math.NaN()
+
+// Log is an observer writing one record per generation to an io.Writer, either
+// in CSV or in JSON Lines format. It implements engine.Finisher.
+//
+// Records are buffered, the buffer is flushed when the evolution ends, or with
+// Flush. Write errors can't be reported by the engine, the first one is
+// recorded and returned by Err, after which the Log stops writing.
+type Log struct {
+	w      *bufio.Writer
+	csv    *csv.Writer // nil for JSON Lines
+	cols   []Column
+	every  int
+	header bool // CSV header written
+	last   int  // last written generation
+	err    error
+}
+
+// NewCSV returns a Log writing CSV records, preceded by a header line holding
+// the column names.
+//
+// Floating-point values are formatted with the minimal number of digits
+// needed to represent them exactly.
+func NewCSV(w io.Writer, options ...func(*Log) error) (*Log, error) {
+	l, err := newLog(w, options...)
+	if err != nil {
+		return nil, err
+	}
+	l.csv = csv.NewWriter(l.w)
+	return l, nil
+}
+
+// NewJSONL returns a Log writing JSON Lines, i.e one JSON object per line, in
+// which keys are the column names. Non-finite floating-point values, that
+// JSON can't represent, are written as null.
+func NewJSONL(w io.Writer, options ...func(*Log) error) (*Log, error) {
+	return newLog(w, options...)
+}
+
+func newLog(w io.Writer, options ...func(*Log) error) (*Log, error) {
+	l := &Log{
+		w:     bufio.NewWriter(w),
+		cols:  DefaultColumns,
+		every: 1,
+		last:  -1,
+	}
+	for _, opt := range options {
+		if err := opt(l); err != nil {
+			return nil, err
+		}
+	}
+	return l, nil
+}
+
+// Columns sets the columns of the records. By default records are made of
+// DefaultColumns.
+func Columns(cols ...Column) func(*Log) error {
+	return func(l *Log) error {
+		if len(cols) == 0 {
+			return errors.New("no columns")
+		}
+		l.cols = cols
+		return nil
+	}
+}
+
+// Every throttles the log, so that only one generation out of n is written,
+// those whose number is a multiple of n. The last generation is always
+// written.
+func Every(n int) func(*Log) error {
+	return func(l *Log) error {
+		if n < 1 {
+			return errors.New("invalid log throttling, must be strictly positive")
+		}
+		l.every = n
+		return nil
+	}
+}
+
+// Observe writes a record, unless the generation is throttled.
+func (l *Log) Observe(stats *evolve.PopulationStats) {
+	if stats.GenNumber%l.every == 0 {
+		l.write(stats)
+	}
+}
+
+// Finish writes the last generation, if it was throttled, then flushes the
+// records.
+func (l *Log) Finish(stats *evolve.PopulationStats) {
+	if stats.GenNumber != l.last {
+		l.write(stats)
+	}
+	l.Flush()
+}
+
+// Flush writes any buffered record to the underlying io.Writer.
+func (l *Log) Flush() error {
+	if l.err != nil {
+		return l.err
+	}
+	if l.csv != nil {
+		l.csv.Flush()
+	}
+	l.err = l.w.Flush()
+	return l.err
+}
+
+// Err returns the first error that occurred while writing records.
+func (l *Log) Err() error { return l.err }
+
+func (l *Log) write(stats *evolve.PopulationStats) {
+	if l.err != nil {
+		return
+	}
+	l.last = stats.GenNumber
+	if l.csv != nil {
+		l.err = l.writeCSV(stats)
+	} else {
+		l.err = l.writeJSON(stats)
+	}
+}
+
+func (l *Log) writeCSV(stats *evolve.PopulationStats) error {
+	if !l.header {
+		l.header = true
+		names := make([]string, len(l.cols))
+		for i, c := range l.cols {
+			names[i] = c.Name
+		}
+		if err := l.csv.Write(names); err != nil {
+			return err
+		}
+	}
+	rec := make([]string, len(l.cols))
+	for i, c := range l.cols {
+		rec[i] = format(c.Value(stats))
+	}
+	if err := l.csv.Write(rec); err != nil {
+		return err
+	}
+	return l.csv.Error()
+}
+
+func (l *Log) writeJSON(stats *evolve.PopulationStats) error {
+	l.w.WriteByte('{')
+	for i, c := range l.cols {
+		if i > 0 {
+			l.w.WriteByte(',')
+		}
+		key, _ := json.Marshal(c.Name)
+		l.w.Write(key)
+		l.w.WriteByte(':')
+
+		v := c.Value(stats)
+		if f, ok := v.(float64); ok && (math.IsNaN(f) || math.IsInf(f, 0)) {
+			v = nil
+		}
+		buf, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Errorf("column %s: %v", c.Name, err)
+		}
+		l.w.Write(buf)
+	}
+	_, err := l.w.WriteString("}\n")
+	return err
+}
+
+// format formats a CSV value.
+func format(v interface{}) string {
+	switch v := v.(type) {
+	case float64:
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	case string:
+		return v
+	}
+	return fmt.Sprint(v)
+}
@@ -0,0 +1,120 @@
+package observer
+
+import (
+	"bytes"
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/arl/evolve"
+)
+
+func generations(n int) []*evolve.PopulationStats {
+	var stats []*evolve.PopulationStats
+	for i := 0; i < n; i++ {
+		stats = append(stats, &evolve.PopulationStats{
+			GenNumber:   i,
+			Elapsed:     time.Duration(i) * 500 * time.Millisecond,
+			BestCand:    "cand,\"" + string(rune('a'+i)),
+			BestFitness: float64(10 - i),
+			Mean:        float64(20-i) / 4,
+			StdDev:      math.NaN(),
+			Quantiles:   []evolve.Quantile{{P: 0.9, Value: 1.5}},
+			Diversity:   map[string]float64{"hamming": float64(i)},
+		})
+	}
+	return stats
+}
+
+func run(l *Log, stats []*evolve.PopulationStats) {
+	for _, s := range stats {
+		l.Observe(s)
+	}
+	l.Finish(stats[len(stats)-1])
+}
+
+func TestCSV(t *testing.T) {
+	var buf bytes.Buffer
+	l, err := NewCSV(&buf)
+	require.NoError(t, err)
+
+	stats := generations(3)
+	l.Observe(stats[0])
+	assert.Empty(t, buf.String(), "records should be buffered")
+	l.Observe(stats[1])
+	l.Observe(stats[2])
+	l.Finish(stats[2])
+
+	want := `generation,elapsed,best,mean,stddev
+0,0,10,5,NaN
+1,0.5,9,4.75,NaN
+2,1,8,4.5,NaN
+`
+	assert.Equal(t, want, buf.String())
+	assert.NoError(t, l.Err())
+}
+
+func TestCSVColumns(t *testing.T) {
+	var buf bytes.Buffer
+	l, err := NewCSV(&buf, Columns(Generation, Quantile(0.9), Quantile(0.1), Diversity("hamming"), BestCandidate(nil)))
+	require.NoError(t, err)
+	run(l, generations(2))
+
+	want := `generation,q0.9,q0.1,hamming,best_candidate
+0,1.5,NaN,0,"cand,""a"
+1,1.5,NaN,1,"cand,""b"
+`
+	assert.Equal(t, want, buf.String())
+}
+
+func TestJSONL(t *testing.T) {
+	var buf bytes.Buffer
+	l, err := NewJSONL(&buf, Columns(Generation, Elapsed, Best, StdDev, BestCandidate(func(cand interface{}) string {
+		return cand.(string)[6:]
+	})))
+	require.NoError(t, err)
+	run(l, generations(2))
+
+	want := `{"generation":0,"elapsed":0,"best":10,"stddev":null,"best_candidate":"a"}
+{"generation":1,"elapsed":0.5,"best":9,"stddev":null,"best_candidate":"b"}
+`
+	assert.Equal(t, want, buf.String())
+}
+
+func TestEvery(t *testing.T) {
+	var buf bytes.Buffer
+	l, err := NewCSV(&buf, Columns(Generation), Every(3))
+	require.NoError(t, err)
+	run(l, generations(8))
+	assert.Equal(t, "generation\n0\n3\n6\n7\n", buf.String())
+
+	// The last generation is not written twice.
+	buf.Reset()
+	l, err = NewCSV(&buf, Columns(Generation), Every(3))
+	require.NoError(t, err)
+	run(l, generations(7))
+	assert.Equal(t, "generation\n0\n3\n6\n", buf.String())
+
+	_, err = NewCSV(&buf, Every(0))
+	assert.Error(t, err)
+}
+
+type failWriter struct{ n int }
+
+func (w *failWriter) Write(p []byte) (int, error) {
+	w.n++
+	return 0, errors.New("disk full")
+}
+
+func TestLogError(t *testing.T) {
+	w := &failWriter{}
+	l, err := NewJSONL(w)
+	require.NoError(t, err)
+	run(l, generations(3))
+	assert.EqualError(t, l.Err(), "disk full")
+	assert.Equal(t, 1, w.n)
+}
@@ -0,0 +1,231 @@
+package observer
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/arl/evolve"
+)
+
+// Metrics publishes live metrics of one or more evolution runs, in Prometheus
+// text exposition format and as an expvar.Var.
+//
+// Each run is identified by a name, which is the value of the run label of
+// the Prometheus metrics. The observer of a run, returned by Run, never blocks
+// the evolution loop: it atomically replaces the snapshot of the run metrics,
+// that is read by the HTTP handler and by String.
+//
+// Metrics is an http.Handler, serving the Prometheus metrics, and an
+// expvar.Var, that can thus be published with expvar.Publish:
+//
+//	m := observer.NewMetrics()
+//	http.Handle("/metrics", m)
+//	expvar.Publish("evolve", m)
+type Metrics struct {
+	mu   sync.RWMutex
+	runs map[string]*RunMetrics
+}
+
+// NewMetrics returns a new, empty, Metrics.
+func NewMetrics() *Metrics {
+	return &Metrics{runs: make(map[string]*RunMetrics)}
+}
+
+// Run returns the observer collecting the metrics of the run called name,
+// creating it if needed.
+func (m *Metrics) Run(name string) *RunMetrics {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	rm, ok := m.runs[name]
+	if !ok {
+		rm = &RunMetrics{}
+		m.runs[name] = rm
+	}
+	return rm
+}
+
+// Remove removes the run called name, its metrics are not published anymore.
+func (m *Metrics) Remove(name string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.runs, name)
+}
+
+// RunMetrics is the observer collecting the metrics of a single run.
+type RunMetrics struct {
+	snap atomic.Value // snapshot
+
+	// Only accessed by Observe.
+	prevElapsed time.Duration
+	evals       int64
+}
+
+// snapshot holds the metrics of a run, after a given generation.
+type snapshot struct {
+	Generation  int     `json:"generation"`
+	Best        float64 `json:"best"`
+	Mean        float64 `json:"mean"`
+	StdDev      float64 `json:"stddev"`
+	Evaluations int64   `json:"evaluations"`
+	EvalsPerSec float64 `json:"evals_per_sec"`
+	GenDuration float64 `json:"generation_seconds"`
+	Elapsed     float64 `json:"elapsed_seconds"`
+}
+
+// Observe updates the run metrics.
+//
+// The number of evaluations is the one reported by the engine (see
+// evolve.PopulationStats.Evaluations). If stats don't report any evaluation
+// nor cache hit, it is the sum of the population sizes, every candidate of
+// every generation being counted as evaluated once.
+func (rm *RunMetrics) Observe(stats *evolve.PopulationStats) {
+	if stats.GenNumber == 0 {
+		rm.prevElapsed, rm.evals = 0, 0
+	}
+	dur := stats.Elapsed - rm.prevElapsed
+	rm.prevElapsed = stats.Elapsed
+	prevEvals := rm.evals
+	rm.evals = evaluations(stats, rm.evals)
+
+	s := snapshot{
+		Generation:  stats.GenNumber,
+		Best:        stats.BestFitness,
+		Mean:        stats.Mean,
+		StdDev:      stats.StdDev,
+		Evaluations: rm.evals,
+		GenDuration: dur.Seconds(),
+		Elapsed:     stats.Elapsed.Seconds(),
+	}
+	if dur > 0 {
+		s.EvalsPerSec = float64(rm.evals-prevEvals) / dur.Seconds()
+	}
+	rm.snap.Store(s)
+}
+
+// evaluations returns the total number of evaluations after the generation
+// described by stats, given the total prev before it.
+func evaluations(stats *evolve.PopulationStats, prev int64) int64 {
+	if stats.Evaluations+stats.CacheHits > 0 {
+		return stats.Evaluations
+	}
+	return prev + int64(stats.Size)
+}
+
+func (rm *RunMetrics) snapshot() (snapshot, bool) {
+	s, ok := rm.snap.Load().(snapshot)
+	return s, ok
+}
+
+// sortedRuns returns the snapshots of the runs that have been observed at
+// least once, sorted by run name.
+func (m *Metrics) sortedRuns() ([]string, []snapshot) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	names := make([]string, 0, len(m.runs))
+	for name := range m.runs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	snaps := make([]snapshot, 0, len(names))
+	observed := names[:0]
+	for _, name := range names {
+		if s, ok := m.runs[name].snapshot(); ok {
+			observed = append(observed, name)
+			snaps = append(snaps, s)
+		}
+	}
+	return observed, snaps
+}
+
+var promMetrics = []struct {
+	name, typ, help string
+	value           func(snapshot) float64
+}{
+	{"evolve_generation", "gauge", "Number of the last completed generation.", func(s snapshot) float64 { return float64(s.Generation) }},
+	{"evolve_best_fitness", "gauge", "Fitness of the best candidate.", func(s snapshot) float64 { return s.Best }},
+	{"evolve_mean_fitness", "gauge", "Mean fitness of the population.", func(s snapshot) float64 { return s.Mean }},
+	{"evolve_stddev_fitness", "gauge", "Standard deviation of the population fitness.", func(s snapshot) float64 { return s.StdDev }},
+	{"evolve_evaluations_total", "counter", "Number of fitness evaluations.", func(s snapshot) float64 { return float64(s.Evaluations) }},
+	{"evolve_evaluations_per_second", "gauge", "Fitness evaluations per second, in the last generation.", func(s snapshot) float64 { return s.EvalsPerSec }},
+	{"evolve_generation_duration_seconds", "gauge", "Duration of the last generation.", func(s snapshot) float64 { return s.GenDuration }},
+	{"evolve_elapsed_seconds", "gauge", "Time elapsed since the evolution start.", func(s snapshot) float64 { return s.Elapsed }},
+}
+
+// WritePrometheus writes the metrics of all runs in Prometheus text
+// exposition format.
+func (m *Metrics) WritePrometheus(w io.Writer) error {
+	names, snaps := m.sortedRuns()
+	var sb strings.Builder
+	for _, pm := range promMetrics {
+		fmt.Fprintf(&sb, "# HELP %s %s\n# TYPE %s %s\n", pm.name, pm.help, pm.name, pm.typ)
+		for i, name := range names {
+			fmt.Fprintf(&sb, "%s{run=\"%s\"} %s\n", pm.name, escapeLabel(name), formatProm(pm.value(snaps[i])))
+		}
+	}
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+// ServeHTTP serves the metrics in Prometheus text exposition format.
+func (m *Metrics) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	m.WritePrometheus(w)
+}
+
+// String returns the metrics of all runs as a JSON object, keyed by run name.
+// It implements expvar.Var. Non-finite values are reported as null.
+func (m *Metrics) String() string {
+	names, snaps := m.sortedRuns()
+	runs := make(map[string]snapshot, len(names))
+	for i, name := range names {
+		runs[name] = snaps[i]
+	}
+	buf, _ := json.Marshal(runs)
+	return string(buf)
+}
+
+// MarshalJSON implements json.Marshaler. Non-finite values, which JSON can't
+// represent, are marshaled as null.
+func (s snapshot) MarshalJSON() ([]byte, error) {
+	type fields snapshot // without MarshalJSON method
+	return json.Marshal(struct {
+		fields
+		Best   *float64 `json:"best"`
+		Mean   *float64 `json:"mean"`
+		StdDev *float64 `json:"stddev"`
+	}{fields(s), finite(s.Best), finite(s.Mean), finite(s.StdDev)})
+}
+
+// finite returns a pointer to f, or nil if f is not finite.
+func finite(f float64) *float64 {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return nil
+	}
+	return &f
+}
+
+func formatProm(f float64) string {
+	switch {
+	case math.IsNaN(f):
+		return "NaN"
+	case math.IsInf(f, 1):
+		return "+Inf"
+	case math.IsInf(f, -1):
+		return "-Inf"
+	}
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func escapeLabel(s string) string { return labelEscaper.Replace(s) }
@@ -0,0 +1,99 @@
+package observer
+
+import (
+	"encoding/json"
+	"expvar"
+	"io/ioutil"
+	"math"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/arl/evolve"
+)
+
+func TestMetricsPrometheus(t *testing.T) {
+	m := NewMetrics()
+	m.Run("unobserved")
+	a := m.Run(`run "a"`)
+	a.Observe(&evolve.PopulationStats{GenNumber: 0, Size: 100, BestFitness: 3, Mean: 1, StdDev: 0.5, Elapsed: time.Second})
+	a.Observe(&evolve.PopulationStats{GenNumber: 1, Size: 100, BestFitness: 4, Mean: 2, StdDev: math.NaN(), Elapsed: 1500 * time.Millisecond})
+	m.Run("b").Observe(&evolve.PopulationStats{GenNumber: 0, Size: 10, BestFitness: 1, Elapsed: 2 * time.Second})
+
+	srv := httptest.NewServer(m)
+	defer srv.Close()
+	resp, err := srv.Client().Get(srv.URL)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(resp.Header.Get("Content-Type"), "text/plain; version=0.0.4"))
+
+	text := string(body)
+	for _, line := range []string{
+		"# TYPE evolve_generation gauge",
+		`evolve_generation{run="run \"a\""} 1`,
+		`evolve_generation{run="b"} 0`,
+		`evolve_best_fitness{run="run \"a\""} 4`,
+		`evolve_stddev_fitness{run="run \"a\""} NaN`,
+		"# TYPE evolve_evaluations_total counter",
+		`evolve_evaluations_total{run="run \"a\""} 200`,
+		`evolve_evaluations_per_second{run="run \"a\""} 200`,
+		`evolve_evaluations_per_second{run="b"} 5`,
+		`evolve_generation_duration_seconds{run="run \"a\""} 0.5`,
+		`evolve_elapsed_seconds{run="b"} 2`,
+	} {
+		assert.Contains(t, text, line+"\n")
+	}
+	assert.NotContains(t, text, "unobserved")
+
+	// A new run resets the counters.
+	a.Observe(&evolve.PopulationStats{GenNumber: 0, Size: 100})
+	var sb strings.Builder
+	require.NoError(t, m.WritePrometheus(&sb))
+	assert.Contains(t, sb.String(), `evolve_evaluations_total{run="run \"a\""} 100`+"\n")
+
+	m.Remove("b")
+	sb.Reset()
+	require.NoError(t, m.WritePrometheus(&sb))
+	assert.NotContains(t, sb.String(), `run="b"`)
+}
+
+func TestMetricsExpvar(t *testing.T) {
+	m := NewMetrics()
+	expvar.Publish("evolve-test", m)
+	m.Run("a").Observe(&evolve.PopulationStats{GenNumber: 3, Size: 10, BestFitness: 2, StdDev: math.Inf(1), Elapsed: time.Second})
+
+	var runs map[string]map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(expvar.Get("evolve-test").String()), &runs))
+	assert.Equal(t, 3.0, runs["a"]["generation"])
+	assert.Equal(t, 2.0, runs["a"]["best"])
+	assert.Contains(t, runs["a"], "stddev")
+	assert.Nil(t, runs["a"]["stddev"])
+	assert.Equal(t, 10.0, runs["a"]["evaluations"])
+}
+
+func TestMetricsConcurrent(t *testing.T) {
+	m := NewMetrics()
+	var wg sync.WaitGroup
+	for _, name := range []string{"a", "b", "c"} {
+		wg.Add(1)
+		go func(rm *RunMetrics) {
+			defer wg.Done()
+			for i := 0; i < 1000; i++ {
+				rm.Observe(&evolve.PopulationStats{GenNumber: i, Size: 10, Elapsed: time.Duration(i) * time.Millisecond})
+			}
+		}(m.Run(name))
+	}
+	for i := 0; i < 100; i++ {
+		m.WritePrometheus(ioutil.Discard)
+		_ = m.String()
+	}
+	wg.Wait()
+	assert.Contains(t, m.String(), `"generation":999`)
+}
@@ -0,0 +1,235 @@
+package observer
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strings"
+	"time"
+
+	"golang.org/x/term"
+
+	"github.com/arl/evolve"
+	"github.com/arl/evolve/condition"
+)
+
+// sparks are the ASCII levels of the sparkline, from lowest to highest.
+const sparks = "_.-~=+*#"
+
+// Progress is an observer rendering the progress of the evolution on a
+// terminal. It implements engine.Finisher.
+//
+// On a terminal, a status line is updated in place. It shows the generation,
+// elapsed time, best and mean fitness, a sparkline of the best fitness history,
+// the progress toward the termination conditions, and the number of
+// evaluations per second. When the output is not a terminal, plain lines are
+// written periodically instead.
+type Progress struct {
+	w        io.Writer
+	tty      bool
+	interval time.Duration
+	width    int
+	conds    []evolve.Condition
+
+	hist     []float64 // best fitness history
+	natural  bool
+	first    float64   // best fitness of the first generation
+	last     time.Time // last time the status was rendered
+	prevElap time.Duration
+	evals    int64 // total evaluations
+	evalrate float64
+	linelen  int
+}
+
+// NewProgress returns a Progress observer writing to w.
+//
+// w is considered a terminal if it is an *os.File referring to a terminal,
+// which is not the case of other character devices such as /dev/null (see
+// TTY to force the terminal mode). By default, the status line of a terminal
+// is refreshed at most every 100ms, while plain lines are written at most
+// every 5s, the sparkline is 20 characters wide and no termination condition
+// is tracked.
+func NewProgress(w io.Writer, options ...func(*Progress) error) (*Progress, error) {
+	p := &Progress{w: w, width: 20}
+	if f, ok := w.(*os.File); ok {
+		p.tty = term.IsTerminal(int(f.Fd()))
+	}
+	for _, opt := range options {
+		if err := opt(p); err != nil {
+			return nil, err
+		}
+	}
+	if p.interval == 0 {
+		p.interval = 5 * time.Second
+		if p.tty {
+			p.interval = 100 * time.Millisecond
+		}
+	}
+	return p, nil
+}
+
+// TTY forces the terminal mode on or off.
+func TTY(tty bool) func(*Progress) error {
+	return func(p *Progress) error {
+		p.tty = tty
+		return nil
+	}
+}
+
+// Interval sets the minimum duration between 2 updates of the status. If d is
+// negative, every generation is rendered.
+func Interval(d time.Duration) func(*Progress) error {
+	return func(p *Progress) error {
+		p.interval = d
+		return nil
+	}
+}
+
+// Sparkline sets the width of the sparkline, i.e the number of generations
+// of best fitness history shown. A width of 0 disables the sparkline.
+func Sparkline(width int) func(*Progress) error {
+	return func(p *Progress) error {
+		if width < 0 {
+			return errors.New("invalid sparkline width")
+		}
+		p.width = width
+		return nil
+	}
+}
+
+// Track sets the termination conditions whose progress is shown. Supported
+// conditions are condition.GenerationCount, condition.ElapsedTime and
+// condition.TargetFitness, others are ignored. The progress shown is the one
+// of the closest condition to be satisfied.
+func Track(conds ...evolve.Condition) func(*Progress) error {
+	return func(p *Progress) error {
+		p.conds = conds
+		return nil
+	}
+}
+
+// Observe updates the status.
+func (p *Progress) Observe(stats *evolve.PopulationStats) {
+	if stats.GenNumber == 0 {
+		p.hist = p.hist[:0]
+		p.first = stats.BestFitness
+		p.natural = stats.Natural
+		p.prevElap = 0
+		p.evals = 0
+		p.last = time.Time{}
+	}
+	if p.width > 0 {
+		if len(p.hist) == p.width {
+			copy(p.hist, p.hist[1:])
+			p.hist = p.hist[:len(p.hist)-1]
+		}
+		p.hist = append(p.hist, stats.BestFitness)
+	}
+	evals := evaluations(stats, p.evals)
+	if dur := stats.Elapsed - p.prevElap; dur > 0 {
+		p.evalrate = float64(evals-p.evals) / dur.Seconds()
+	}
+	p.prevElap = stats.Elapsed
+	p.evals = evals
+
+	now := time.Now()
+	if now.Sub(p.last) < p.interval {
+		return
+	}
+	p.last = now
+	p.render(stats)
+}
+
+// Finish renders the status of the last generation, and terminates the status
+// line on a terminal.
+func (p *Progress) Finish(stats *evolve.PopulationStats) {
+	p.render(stats)
+	if p.tty {
+		fmt.Fprintln(p.w)
+	}
+}
+
+func (p *Progress) render(stats *evolve.PopulationStats) {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "gen %d  %s  best %.6g  mean %.6g", stats.GenNumber,
+		stats.Elapsed.Truncate(100*time.Millisecond), stats.BestFitness, stats.Mean)
+	if p.width > 0 {
+		fmt.Fprintf(&sb, "  [%s]", p.sparkline())
+	}
+	if prog, ok := p.progress(stats); ok {
+		fmt.Fprintf(&sb, "  %s %3.0f%%", bar(prog, 10), 100*prog)
+	}
+	fmt.Fprintf(&sb, "  %.0f evals/s", p.evalrate)
+
+	line := sb.String()
+	if !p.tty {
+		fmt.Fprintln(p.w, line)
+		return
+	}
+	// Overwrite the previous status line.
+	pad := ""
+	if n := p.linelen - len(line); n > 0 {
+		pad = strings.Repeat(" ", n)
+	}
+	p.linelen = len(line)
+	fmt.Fprintf(p.w, "\r%s%s", line, pad)
+}
+
+// sparkline returns the sparkline of the best fitness history. Fitter is
+// higher, whether fitness scores are natural or not.
+func (p *Progress) sparkline() string {
+	lo, hi := math.Inf(1), math.Inf(-1)
+	for _, f := range p.hist {
+		lo, hi = math.Min(lo, f), math.Max(hi, f)
+	}
+	b := make([]byte, len(p.hist), p.width)
+	for i, f := range p.hist {
+		lvl := 0
+		if hi > lo {
+			lvl = int((f - lo) / (hi - lo) * float64(len(sparks)-1))
+		}
+		if !p.natural {
+			lvl = len(sparks) - 1 - lvl
+		}
+		b[i] = sparks[lvl]
+	}
+	for len(b) < p.width {
+		b = append(b, ' ')
+	}
+	return string(b)
+}
+
+// progress returns the progress, in [0, 1], toward the closest termination
+// condition to be satisfied.
+func (p *Progress) progress(stats *evolve.PopulationStats) (float64, bool) {
+	var prog float64
+	var ok bool
+	for _, c := range p.conds {
+		var cur float64
+		switch c := c.(type) {
+		case condition.GenerationCount:
+			cur = float64(stats.GenNumber+1) / float64(c)
+		case condition.ElapsedTime:
+			cur = float64(stats.Elapsed) / float64(c)
+		case condition.TargetFitness:
+			if c.IsSatisfied(stats) {
+				cur = 1
+			} else if p.first != c.Fitness {
+				cur = (stats.BestFitness - p.first) / (c.Fitness - p.first)
+			}
+		default:
+			continue
+		}
+		ok = true
+		prog = math.Max(prog, math.Max(0, math.Min(1, cur)))
+	}
+	return prog, ok
+}
+
+// bar returns a progress bar of the given width.
+func bar(prog float64, width int) string {
+	n := int(prog * float64(width))
+	return "[" + strings.Repeat("#", n) + strings.Repeat(".", width-n) + "]"
+}
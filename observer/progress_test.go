@@ -0,0 +1,99 @@
+package observer
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/arl/evolve"
+	"github.com/arl/evolve/condition"
+)
+
+func progressStats(gen int, best float64) *evolve.PopulationStats {
+	return &evolve.PopulationStats{
+		GenNumber:   gen,
+		BestFitness: best,
+		Mean:        best / 2,
+		Natural:     true,
+		Size:        100,
+		Elapsed:     time.Duration(gen+1) * 100 * time.Millisecond,
+	}
+}
+
+func TestProgressPlain(t *testing.T) {
+	var buf bytes.Buffer
+	p, err := NewProgress(&buf, Interval(-1), Sparkline(4), Track(condition.GenerationCount(10), condition.NewUserAbort()))
+	require.NoError(t, err)
+
+	for i := 0; i < 3; i++ {
+		p.Observe(progressStats(i, float64(i)))
+	}
+	p.Finish(progressStats(2, 2))
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	require.Len(t, lines, 4)
+	assert.Equal(t, "gen 0  100ms  best 0  mean 0  [_   ]  [#.........]  10%  1000 evals/s", lines[0])
+	assert.Equal(t, "gen 2  300ms  best 2  mean 1  [_~# ]  [###.......]  30%  1000 evals/s", lines[2])
+	assert.Equal(t, lines[2], lines[3])
+}
+
+func TestProgressTTY(t *testing.T) {
+	var buf bytes.Buffer
+	p, err := NewProgress(&buf, TTY(true), Interval(-1), Sparkline(0))
+	require.NoError(t, err)
+
+	p.Observe(&evolve.PopulationStats{GenNumber: 9, BestFitness: 12345, Elapsed: time.Second})
+	p.Observe(&evolve.PopulationStats{GenNumber: 10, BestFitness: 1, Elapsed: time.Second})
+	p.Finish(&evolve.PopulationStats{GenNumber: 10, BestFitness: 1, Elapsed: time.Second})
+
+	out := buf.String()
+	assert.Equal(t, 3, strings.Count(out, "\r"))
+	assert.True(t, strings.HasSuffix(out, "\n"))
+	// The shorter line overwrites the longer one.
+	assert.Contains(t, out, "\rgen 10  1s  best 1  mean 0  0 evals/s   \r")
+}
+
+func TestProgressDevNull(t *testing.T) {
+	f, err := os.OpenFile(os.DevNull, os.O_WRONLY, 0)
+	require.NoError(t, err)
+	defer f.Close()
+
+	// A character device that is not a terminal.
+	p, err := NewProgress(f)
+	require.NoError(t, err)
+	assert.False(t, p.tty)
+	assert.Equal(t, 5*time.Second, p.interval)
+}
+
+func TestProgressThrottle(t *testing.T) {
+	var buf bytes.Buffer
+	p, err := NewProgress(&buf, Interval(time.Hour))
+	require.NoError(t, err)
+	for i := 0; i < 100; i++ {
+		p.Observe(progressStats(i, 1))
+	}
+	assert.Equal(t, 1, strings.Count(buf.String(), "\n"))
+}
+
+func TestProgressConditions(t *testing.T) {
+	p, err := NewProgress(&bytes.Buffer{}, Track(
+		condition.ElapsedTime(time.Second),
+		condition.TargetFitness{Fitness: 0, Natural: false},
+	))
+	require.NoError(t, err)
+
+	stats := &evolve.PopulationStats{BestFitness: 100, Elapsed: 100 * time.Millisecond}
+	p.Observe(stats)
+	prog, ok := p.progress(stats)
+	assert.True(t, ok)
+	assert.InDelta(t, 0.1, prog, 1e-9)
+
+	stats = &evolve.PopulationStats{GenNumber: 1, BestFitness: 25, Elapsed: 200 * time.Millisecond}
+	prog, _ = p.progress(stats)
+	assert.InDelta(t, 0.75, prog, 1e-9)
+}
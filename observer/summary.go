@@ -0,0 +1,130 @@
+package observer
+
+import (
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"time"
+
+	"github.com/arl/evolve"
+)
+
+// Summary is an observer accumulating the telemetry of every generation: the
+// time spent in each phase, the activity of the genetic operators and the
+// number of offspring fitter than their parents (see
+// evolve.PopulationStats.Timings). It implements engine.Finisher, the summary
+// of the run is written when the evolution ends.
+//
+// Operator activity is only reported by engines with telemetry enabled (see
+// engine.Telemetry).
+type Summary struct {
+	w   io.Writer
+	err error
+
+	gens     int
+	elapsed  time.Duration
+	timings  evolve.Timings
+	ops      []evolve.OperatorStats
+	bred     int // offspring produced by operators
+	improved int
+}
+
+// NewSummary returns a Summary observer that writes the summary of the run to
+// w when the evolution ends. If w is nil, the summary is not written, but the
+// accumulated telemetry can still be accessed.
+func NewSummary(w io.Writer) *Summary {
+	return &Summary{w: w}
+}
+
+// Observe implements engine.Observer. The accumulated telemetry is reset at
+// generation 0, so that a Summary reused across runs only reports the last one.
+func (s *Summary) Observe(stats *evolve.PopulationStats) {
+	if stats.GenNumber == 0 {
+		s.gens, s.elapsed, s.timings = 0, 0, evolve.Timings{}
+		s.ops, s.bred, s.improved = nil, 0, 0
+	}
+	s.gens++
+	s.elapsed = stats.Elapsed
+	s.timings = s.timings.Add(stats.Timings)
+	if stats.Operators != nil {
+		s.bred += stats.Size - stats.NumElites
+		s.improved += stats.Improved
+		s.ops = evolve.MergeOperatorStats(s.ops, stats.Operators)
+	}
+}
+
+// Finish implements engine.Finisher, it writes the summary.
+func (s *Summary) Finish(*evolve.PopulationStats) {
+	if s.w != nil {
+		s.err = s.Write(s.w)
+	}
+}
+
+// Err returns the error that occurred while writing the summary, if any.
+func (s *Summary) Err() error { return s.err }
+
+// Generations returns the number of observed generations.
+func (s *Summary) Generations() int { return s.gens }
+
+// Timings returns the total time spent in each phase.
+func (s *Summary) Timings() evolve.Timings { return s.timings }
+
+// Operators returns the total activity of each operator.
+func (s *Summary) Operators() []evolve.OperatorStats {
+	return append([]evolve.OperatorStats(nil), s.ops...)
+}
+
+// Improved returns the total number of offspring fitter than their parents,
+// and the total number of offspring produced by the operators.
+func (s *Summary) Improved() (improved, offspring int) { return s.improved, s.bred }
+
+// Write writes the summary to w, in a human readable form.
+func (s *Summary) Write(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "generations\t%d\t\n", s.gens)
+	fmt.Fprintf(tw, "elapsed\t%v\t\n", s.elapsed.Round(time.Millisecond))
+	fmt.Fprintf(tw, "\t\t\t\t\n")
+
+	total := s.timings.Total()
+	fmt.Fprintf(tw, "phase\ttotal\tper gen\tshare\t\n")
+	phases := []struct {
+		name string
+		d    time.Duration
+	}{
+		{"selection", s.timings.Selection},
+		{"operators", s.timings.Operators},
+		{"evaluation", s.timings.Evaluation},
+		{"sorting", s.timings.Sorting},
+		{"total", total},
+	}
+	for _, p := range phases {
+		fmt.Fprintf(tw, "%s\t%v\t%v\t%s\t\n", p.name, p.d, s.perGen(p.d), percent(int64(p.d), int64(total)))
+	}
+
+	if len(s.ops) > 0 {
+		fmt.Fprintf(tw, "\t\t\t\t\n")
+		fmt.Fprintf(tw, "operator\toffspring\tchanged\ttime\t\n")
+		for _, op := range s.ops {
+			fmt.Fprintf(tw, "%s\t%d\t%d (%s)\t%v\t\n", op.Name, op.Offspring, op.Changed, percent(int64(op.Changed), int64(op.Offspring)), op.Time)
+		}
+		fmt.Fprintf(tw, "\t\t\t\t\n")
+		fmt.Fprintf(tw, "improved\t%d / %d (%s)\t\t\t\n", s.improved, s.bred, percent(int64(s.improved), int64(s.bred)))
+	}
+	return tw.Flush()
+}
+
+// perGen returns the mean duration per generation.
+func (s *Summary) perGen(d time.Duration) time.Duration {
+	if s.gens == 0 {
+		return 0
+	}
+	return d / time.Duration(s.gens)
+}
+
+// percent formats n/total as a percentage.
+func percent(n, total int64) string {
+	if total == 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%.1f%%", 100*float64(n)/float64(total))
+}
@@ -0,0 +1,90 @@
+package observer
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/arl/evolve"
+)
+
+func TestSummary(t *testing.T) {
+	var sb strings.Builder
+	s := NewSummary(&sb)
+
+	s.Observe(&evolve.PopulationStats{
+		Size:    10,
+		Timings: evolve.Timings{Evaluation: 4 * time.Millisecond, Sorting: time.Millisecond},
+	})
+	for gen := 1; gen <= 2; gen++ {
+		stats := &evolve.PopulationStats{
+			Size:      10,
+			NumElites: 2,
+			GenNumber: gen,
+			Elapsed:   time.Duration(gen) * 10 * time.Millisecond,
+			Timings: evolve.Timings{
+				Selection:  time.Millisecond,
+				Operators:  2 * time.Millisecond,
+				Evaluation: 4 * time.Millisecond,
+				Sorting:    time.Millisecond,
+			},
+			Operators: []evolve.OperatorStats{
+				{Name: "crossover", Time: time.Millisecond, Offspring: 8, Changed: 6},
+				{Name: "mutation", Time: time.Millisecond, Offspring: 8, Changed: 2},
+			},
+			Improved: 3,
+		}
+		s.Observe(stats)
+	}
+	s.Finish(nil)
+	assert.NoError(t, s.Err())
+
+	assert.Equal(t, 3, s.Generations())
+	assert.Equal(t, evolve.Timings{
+		Selection:  2 * time.Millisecond,
+		Operators:  4 * time.Millisecond,
+		Evaluation: 12 * time.Millisecond,
+		Sorting:    3 * time.Millisecond,
+	}, s.Timings())
+	improved, bred := s.Improved()
+	assert.Equal(t, 6, improved)
+	assert.Equal(t, 16, bred)
+	assert.Equal(t, []evolve.OperatorStats{
+		{Name: "crossover", Time: 2 * time.Millisecond, Offspring: 16, Changed: 12},
+		{Name: "mutation", Time: 2 * time.Millisecond, Offspring: 16, Changed: 4},
+	}, s.Operators())
+
+	out := sb.String()
+	assert.Contains(t, out, "generations  3")
+	assert.Contains(t, out, "elapsed      20ms")
+	assert.Regexp(t, `evaluation +12ms +4ms +57\.1%`, out)
+	assert.Regexp(t, `total +21ms +7ms +100\.0%`, out)
+	assert.Regexp(t, `crossover +16 +12 \(75\.0%\) +2ms`, out)
+	assert.Regexp(t, `improved +6 / 16 \(37\.5%\)`, out)
+}
+
+func TestSummaryReset(t *testing.T) {
+	s := NewSummary(nil)
+	stats := &evolve.PopulationStats{
+		Size:      10,
+		Timings:   evolve.Timings{Evaluation: time.Millisecond},
+		Operators: []evolve.OperatorStats{{Name: "mutation", Offspring: 10, Changed: 4}},
+		Improved:  2,
+	}
+	for run := 0; run < 2; run++ {
+		for gen := 0; gen < 3; gen++ {
+			stats.GenNumber = gen
+			s.Observe(stats)
+		}
+	}
+
+	// Only the last run is reported.
+	assert.Equal(t, 3, s.Generations())
+	assert.Equal(t, evolve.Timings{Evaluation: 3 * time.Millisecond}, s.Timings())
+	assert.Equal(t, []evolve.OperatorStats{{Name: "mutation", Offspring: 30, Changed: 12}}, s.Operators())
+	improved, bred := s.Improved()
+	assert.Equal(t, 6, improved)
+	assert.Equal(t, 30, bred)
+}
@@ -2,6 +2,8 @@ package mutation
 
 import (
 	"math/rand"
+
+	"github.com/arl/evolve"
 )
 
 // Mutation implements the mutation evolutionnary operator. It modifies the
@@ -28,6 +30,23 @@ func (op *Mutation) Apply(population []interface{}, rng *rand.Rand) []interface{
 	return muted
 }
 
+// ApplyTrace is like Apply but it also returns the derivation of each mutant.
+// A mutant identical to its parent is reported as an unmodified copy.
+func (op *Mutation) ApplyTrace(population []interface{}, rng *rand.Rand) ([]interface{}, []evolve.Derivation) {
+	muted := op.Apply(population, rng)
+	derivs := make([]evolve.Derivation, len(muted))
+	for i := range muted {
+		derivs[i].Parents = []int{i}
+		if !evolve.SameCandidate(muted[i], population[i]) {
+			derivs[i].Op = op.String()
+		}
+	}
+	return muted, derivs
+}
+
+// String returns the operator name, "mutation".
+func (op *Mutation) String() string { return "mutation" }
+
 // A Mutater mutates individuals.
 type Mutater interface {
 
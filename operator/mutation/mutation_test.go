@@ -0,0 +1,38 @@
+package mutation
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/arl/evolve"
+	"github.com/arl/evolve/generator"
+)
+
+func TestMutationApplyTrace(t *testing.T) {
+	mut := New(&String{
+		Alphabet:    "ab",
+		Probability: generator.ConstFloat64(0.5),
+	})
+	pop := []interface{}{"aaaa", "bbbb", "abab", "baba", "aabb", "bbaa"}
+
+	want := mut.Apply(pop, rand.New(rand.NewSource(99)))
+	got, derivs := mut.ApplyTrace(pop, rand.New(rand.NewSource(99)))
+	assert.Equal(t, want, got)
+	assert.Len(t, derivs, len(pop))
+
+	var nchanged int
+	for i, d := range derivs {
+		assert.Equal(t, []int{i}, d.Parents)
+		if got[i] == pop[i] {
+			assert.Empty(t, d.Op)
+		} else {
+			assert.Equal(t, "mutation", d.Op)
+			nchanged++
+		}
+	}
+	assert.True(t, nchanged > 0)
+
+	var _ evolve.TracingOperator = mut
+}
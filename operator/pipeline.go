@@ -17,3 +17,31 @@ func (ops Pipeline) Apply(sel []interface{}, rng *rand.Rand) []interface{} {
 	}
 	return sel
 }
+
+// ApplyTrace is like Apply but it also returns the derivation of each
+// offspring, composed from the derivations reported by each operator (see
+// evolve.ApplyTrace).
+func (ops Pipeline) ApplyTrace(sel []interface{}, rng *rand.Rand) ([]interface{}, []evolve.Derivation) {
+	off, derivs, _ := ops.ApplyInstrumented(sel, rng)
+	return off, derivs
+}
+
+// ApplyInstrumented is like ApplyTrace but it also returns the stats of each
+// operator in the pipeline (see evolve.ApplyInstrumented).
+func (ops Pipeline) ApplyInstrumented(sel []interface{}, rng *rand.Rand) ([]interface{}, []evolve.Derivation, []evolve.OperatorStats) {
+	derivs := make([]evolve.Derivation, len(sel))
+	for i := range derivs {
+		derivs[i].Parents = []int{i}
+	}
+	var stats []evolve.OperatorStats
+	for _, op := range ops {
+		var (
+			next []evolve.Derivation
+			st   []evolve.OperatorStats
+		)
+		sel, next, st = evolve.ApplyInstrumented(op, sel, rng)
+		derivs = evolve.ComposeDerivations(derivs, next)
+		stats = append(stats, st...)
+	}
+	return sel, derivs, stats
+}
@@ -41,3 +41,19 @@ func TestEvolutionPipeline(t *testing.T) {
 		t.Error("want sum = 90, got", sum)
 	}
 }
+
+func TestPipelineApplyTrace(t *testing.T) {
+	pipe := Pipeline{adjustInt(0), adjustInt(1)}
+	got, derivs := pipe.ApplyTrace([]interface{}{1, 2}, nil)
+	if got[0] != 2 || got[1] != 3 {
+		t.Errorf("got offspring %v, want [2 3]", got)
+	}
+	for i, d := range derivs {
+		if len(d.Parents) != 1 || d.Parents[0] != i {
+			t.Errorf("offspring %d: got parents %v, want [%d]", i, d.Parents, i)
+		}
+		if d.Op != "operator.adjustInt" {
+			t.Errorf("offspring %d: got op %q, want %q", i, d.Op, "operator.adjustInt")
+		}
+	}
+}
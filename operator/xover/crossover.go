@@ -3,6 +3,7 @@ package xover
 import (
 	"math/rand"
 
+	"github.com/arl/evolve"
 	"github.com/arl/evolve/generator"
 )
 
@@ -53,22 +54,44 @@ func New(mater Mater) *Crossover {
 // Returns the combined set of evolved offsprings generated by applying
 // crossover to the selected candidates.
 func (op *Crossover) Apply(sel []interface{}, rng *rand.Rand) []interface{} {
+	off, _ := op.apply(sel, rng, false)
+	return off
+}
+
+// ApplyTrace is like Apply but it also returns the derivation of each
+// offspring. Offspring of a crossover derive from both parents, unless they
+// are identical to one of them.
+func (op *Crossover) ApplyTrace(sel []interface{}, rng *rand.Rand) ([]interface{}, []evolve.Derivation) {
+	return op.apply(sel, rng, true)
+}
+
+// String returns the operator name, "crossover".
+func (op *Crossover) String() string { return "crossover" }
+
+func (op *Crossover) apply(sel []interface{}, rng *rand.Rand, trace bool) ([]interface{}, []evolve.Derivation) {
 	// Shuffle the collection before applying each operation so that the
 	// evolution is not influenced by any ordering artifacts from previous
-	// operations.
-	selcopy := make([]interface{}, len(sel))
-	copy(selcopy, sel)
+	// operations. The indexes of the selected candidates are shuffled
+	// rather than the candidates, to keep track of them.
+	idx := make([]int, len(sel))
+	for i := range idx {
+		idx[i] = i
+	}
 
-	rand.Shuffle(len(selcopy), func(i, j int) {
-		selcopy[i], selcopy[j] = selcopy[j], selcopy[i]
+	rng.Shuffle(len(idx), func(i, j int) {
+		idx[i], idx[j] = idx[j], idx[i]
 	})
 
 	res := make([]interface{}, 0, len(sel))
-	for i := 0; i < len(selcopy); {
-		p1 := selcopy[i]
+	var derivs []evolve.Derivation
+	if trace {
+		derivs = make([]evolve.Derivation, 0, len(sel))
+	}
+	for i := 0; i < len(idx); {
+		i1 := idx[i]
 		i++
-		if i < len(selcopy) {
-			p2 := selcopy[i]
+		if i < len(idx) {
+			i2 := idx[i]
 			i++
 
 			// get/decide a xover probability for this run
@@ -81,17 +104,42 @@ func (op *Crossover) Apply(sel []interface{}, rng *rand.Rand) []interface{} {
 				npts = int(op.Points.Next())
 			}
 			if npts > 0 {
-				res = append(res, op.Mate(p1, p2, int64(npts), rng)...)
+				children := op.Mate(sel[i1], sel[i2], int64(npts), rng)
+				res = append(res, children...)
+				if trace {
+					for _, c := range children {
+						derivs = append(derivs, op.derive(c, sel, i1, i2))
+					}
+				}
 			} else {
 				// If there is no crossover to perform, just add the parents to the
 				// results unaltered.
-				res = append(res, p1, p2)
+				res = append(res, sel[i1], sel[i2])
+				if trace {
+					derivs = append(derivs,
+						evolve.Derivation{Parents: []int{i1}},
+						evolve.Derivation{Parents: []int{i2}})
+				}
 			}
 		} else {
 			// If we have an odd number of selected candidates, we can't pair up
 			// the last one so just leave it unmodified.
-			res = append(res, p1)
+			res = append(res, sel[i1])
+			if trace {
+				derivs = append(derivs, evolve.Derivation{Parents: []int{i1}})
+			}
 		}
 	}
-	return res
+	return res, derivs
+}
+
+// derive returns the derivation of the child of sel[i1] and sel[i2].
+func (op *Crossover) derive(child interface{}, sel []interface{}, i1, i2 int) evolve.Derivation {
+	switch {
+	case evolve.SameCandidate(child, sel[i1]):
+		return evolve.Derivation{Parents: []int{i1}}
+	case evolve.SameCandidate(child, sel[i2]):
+		return evolve.Derivation{Parents: []int{i2}}
+	}
+	return evolve.Derivation{Parents: []int{i1, i2}, Op: op.String()}
 }
@@ -48,3 +48,50 @@ func TestCrossover_Apply(t *testing.T) {
 		sameStringPop(t, pop, got)
 	})
 }
+
+func TestCrossover_ApplyReproducible(t *testing.T) {
+	pop := []interface{}{"abcde", "fghij", "klmno", "pqrst", "uvwxy", "zABCD"}
+
+	xover := New(StringMater{})
+	xover.Points = generator.ConstInt(1)
+	xover.Probability = generator.ConstFloat64(1)
+
+	got1 := xover.Apply(pop, rand.New(rand.NewSource(99)))
+	got2 := xover.Apply(pop, rand.New(rand.NewSource(99)))
+	assert.Equal(t, got1, got2, "Apply should only depend on the provided rng")
+}
+
+func TestCrossover_ApplyTrace(t *testing.T) {
+	pop := []interface{}{"abcde", "fghij", "klmno", "pqrst", "uvwxy"}
+	xover := New(StringMater{})
+	xover.Points = generator.ConstInt(1)
+	xover.Probability = generator.ConstFloat64(1)
+
+	want := xover.Apply(pop, rand.New(rand.NewSource(99)))
+	got, derivs := xover.ApplyTrace(pop, rand.New(rand.NewSource(99)))
+	assert.Equal(t, want, got)
+	assert.Len(t, derivs, len(pop))
+
+	for i, d := range derivs[:4] {
+		// Every character of the offspring comes from one of its parents.
+		assert.Equal(t, "crossover", d.Op)
+		assert.Len(t, d.Parents, 2)
+		child := got[i].(string)
+		for j := range child {
+			p1, p2 := pop[d.Parents[0]].(string), pop[d.Parents[1]].(string)
+			assert.True(t, child[j] == p1[j] || child[j] == p2[j])
+		}
+	}
+	// The last candidate can't be paired.
+	last := derivs[4]
+	assert.Empty(t, last.Op)
+	assert.Equal(t, pop[last.Parents[0]], got[4])
+
+	// Without crossover, offspring are copies of their parents.
+	xover.Probability = generator.ConstFloat64(0)
+	got, derivs = xover.ApplyTrace(pop, rand.New(rand.NewSource(99)))
+	for i, d := range derivs {
+		assert.Empty(t, d.Op)
+		assert.Equal(t, pop[d.Parents[0]], got[i])
+	}
+}
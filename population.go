@@ -9,6 +9,23 @@ import (
 type Individual struct {
 	Candidate interface{}
 	Fitness   float64
+
+	// Scores is the vector of scores of the candidate, when the population has
+	// been evaluated by a VectorEvaluator. In that case Fitness is the first
+	// score. The order of individuals with several scores is defined by a
+	// Comparator.
+	Scores []float64
+
+	// Samples is the number of fitness evaluations the fitness score has been
+	// estimated from, and CI is the half-width of the confidence interval
+	// around it. They are only set when the population has been evaluated by
+	// a NoisyEvaluator, otherwise they are both 0.
+	Samples int
+	CI      float64
+
+	// Origin describes how the individual has been born, it's only set when
+	// the engine tracks the lineage of the individuals.
+	Origin *Origin
 }
 
 // Population is a group of individual.
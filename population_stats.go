@@ -15,6 +15,23 @@ type PopulationStats struct {
 	// population.
 	BestFitness float64
 
+	// BestScores is the vector of scores of the fittest candidate, if the
+	// population has been evaluated by a VectorEvaluator, or nil.
+	BestScores []float64
+
+	// WorstFitness is the fitness score of the weakest candidate in the
+	// population, according to the comparator of the engine.
+	WorstFitness float64
+
+	// BestEverCand is the fittest candidate found since the evolution start,
+	// BestEverFitness is its fitness score and BestEverGen the (zero-based)
+	// number of the generation in which it was found. Candidates from
+	// different generations are compared by the comparator of the engine,
+	// their fitness score by default.
+	BestEverCand    interface{}
+	BestEverFitness float64
+	BestEverGen     int
+
 	// Mean is the arithmetic mean of fitness scores for each member of
 	// the population.
 	Mean float64
@@ -22,6 +39,22 @@ type PopulationStats struct {
 	// StdDev is a measure of the variation in fitness scores.
 	StdDev float64
 
+	// Median is the median of the fitness scores of the population.
+	Median float64
+
+	// Quantiles holds the fitness quantiles configured with
+	// engine.Quantiles.
+	//
+	// Median and quantiles are exact when the population is ordered by
+	// fitness, which is the default. When the engine orders the population
+	// with another Comparator, they are estimated in a streaming fashion (see
+	// Dataset.TrackQuantiles).
+	Quantiles []Quantile
+
+	// Histogram is the fitness histogram of the population, or nil if no
+	// histogram has been configured (see engine.HistogramBins).
+	Histogram *Histogram
+
 	// Natural indicates, if true, that higher fitness is better.
 	Natural bool
 
@@ -37,4 +70,41 @@ type PopulationStats struct {
 
 	// Elapsed is the duration elapsed since the evolution start.
 	Elapsed time.Duration
+
+	// Evaluations is the number of fitness evaluations performed since the
+	// evolution start, and CacheHits the number of fitness scores served by a
+	// fitness cache instead (see evolve.CachingEvaluator). Every candidate
+	// evaluated by the engine, including the elites, counts for one or the
+	// other.
+	Evaluations int64
+	CacheHits   int64
+
+	// Timings holds the wall time spent in each phase of the generation,
+	// which includes the production of the generation from the previous one
+	// and the sorting of the population. Phases that are not performed by the
+	// epocher used by the engine are reported as 0.
+	Timings Timings
+
+	// Operators reports the activity of each genetic operator, in the order
+	// they have been applied to produce the generation, and Improved is the
+	// number of offspring, modified by operators, fitter than all their
+	// parents. They are only reported when the engine traces the operators
+	// (see engine.Telemetry), and never for the initial population.
+	Operators []OperatorStats
+	Improved  int
+
+	// Diversity holds the values of the diversity measures of the population,
+	// keyed by measure names. It is nil if no diversity measure has been
+	// configured (see engine.MeasureDiversity).
+	Diversity map[string]float64
+
+	// EvaluatorStats holds the statistics reported by the evaluator of the
+	// engine, keyed by name, if it's a ReportingEvaluator, or nil.
+	EvaluatorStats map[string]float64
+}
+
+// Quantile is the value of a quantile of probability P.
+type Quantile struct {
+	P     float64
+	Value float64
 }
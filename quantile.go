@@ -0,0 +1,115 @@
+package evolve
+
+import (
+	"math"
+	"sort"
+)
+
+// p2 estimates a quantile of a stream of values with the P² algorithm (Jain
+// and Chlamtac, 1985), in constant time and memory per value.
+type p2 struct {
+	p  float64
+	n  int        // number of values
+	q  [5]float64 // marker heights
+	ns [5]float64 // marker positions, 1-based
+	nd [5]float64 // desired marker positions
+	dn [5]float64 // increments of the desired positions
+}
+
+func newP2(p float64) *p2 {
+	e := &p2{p: p}
+	e.reset()
+	return e
+}
+
+func (e *p2) reset() {
+	p := e.p
+	e.n = 0
+	e.ns = [5]float64{1, 2, 3, 4, 5}
+	e.nd = [5]float64{1, 1 + 2*p, 1 + 4*p, 3 + 2*p, 5}
+	e.dn = [5]float64{0, p / 2, p, (1 + p) / 2, 1}
+}
+
+func (e *p2) add(x float64) {
+	if e.n < 5 {
+		e.q[e.n] = x
+		e.n++
+		if e.n == 5 {
+			sort.Float64s(e.q[:])
+		}
+		return
+	}
+	e.n++
+
+	// Find the cell k of x, adjusting the extreme markers.
+	var k int
+	switch {
+	case x < e.q[0]:
+		e.q[0] = x
+		k = 0
+	case x >= e.q[4]:
+		e.q[4] = x
+		k = 3
+	default:
+		for k = 0; k < 3 && x >= e.q[k+1]; k++ {
+		}
+	}
+	for i := k + 1; i < 5; i++ {
+		e.ns[i]++
+	}
+	for i := range e.nd {
+		e.nd[i] += e.dn[i]
+	}
+
+	// Adjust the heights of the middle markers.
+	for i := 1; i < 4; i++ {
+		d := e.nd[i] - e.ns[i]
+		if d >= 1 && e.ns[i+1]-e.ns[i] > 1 || d <= -1 && e.ns[i-1]-e.ns[i] < -1 {
+			s := math.Copysign(1, d)
+			q := e.parabolic(i, s)
+			if q <= e.q[i-1] || q >= e.q[i+1] {
+				q = e.linear(i, s)
+			}
+			e.q[i] = q
+			e.ns[i] += s
+		}
+	}
+}
+
+func (e *p2) parabolic(i int, s float64) float64 {
+	n, q := &e.ns, &e.q
+	return q[i] + s/(n[i+1]-n[i-1])*
+		((n[i]-n[i-1]+s)*(q[i+1]-q[i])/(n[i+1]-n[i])+
+			(n[i+1]-n[i]-s)*(q[i]-q[i-1])/(n[i]-n[i-1]))
+}
+
+func (e *p2) linear(i int, s float64) float64 {
+	j := i + int(s)
+	return e.q[i] + s*(e.q[j]-e.q[i])/(e.ns[j]-e.ns[i])
+}
+
+// value returns the current estimate of the quantile.
+func (e *p2) value() float64 {
+	if e.n >= 5 {
+		return e.q[2]
+	}
+	// Exact quantile of the few values seen so far.
+	cpy := make([]float64, e.n)
+	copy(cpy, e.q[:e.n])
+	sort.Float64s(cpy)
+	return quantile(cpy, e.p)
+}
+
+// quantile returns the p-quantile of sorted, linearly interpolating between
+// the closest ranks.
+func quantile(sorted []float64, p float64) float64 {
+	if len(sorted) == 0 {
+		return math.NaN()
+	}
+	pos := p * float64(len(sorted)-1)
+	lo := int(math.Floor(pos))
+	if lo >= len(sorted)-1 {
+		return sorted[len(sorted)-1]
+	}
+	return sorted[lo] + (pos-float64(lo))*(sorted[lo+1]-sorted[lo])
+}
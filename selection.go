@@ -14,7 +14,11 @@ type Selection interface {
 	// Select selects the specified number of candidates from the population.
 	//
 	// - pop must be sorted by descending fitness, i.e the fittest individual of the
-	// population should be pop[0].
+	// population should be pop[0]. When the engine uses a Comparator, the order
+	// of pop is the one defined by that comparator: strategies that only
+	// depend on the order of the individuals, such as tournament, truncation
+	// or rank-based selections, respect it, while fitness-proportionate
+	// strategies use the Fitness field of the individuals.
 	// - natural indicates fitter individuals have fitness scores.
 	// - size is the number of individual selections to perform (not necessarily the
 	// number of distinct candidates to select, since the same individual may
@@ -18,6 +18,10 @@ import (
 // excessively high occurrences of particular candidates. If this is a problem,
 // StochasticUniversalSampling provides an alternative fitness-proportionate
 // strategy for selection.
+//
+// Selection probabilities only depend on the Fitness field: the comparator of
+// the engine and the vector scores of the individuals are ignored (see
+// engine.Compare).
 var RouletteWheel = rouletteWheel{}
 
 type rouletteWheel struct{}
@@ -22,6 +22,10 @@ type sigmaScaling struct{ selector evolve.Selection }
 // in a population of mostly unfit individuals. It also helps to amplify minor
 // fitness differences in a more mature population where the rate of improvement
 // has slowed.
+//
+// Scaled scores are computed from the Fitness field alone, sigma scaling thus
+// doesn't follow the comparator of the engine, nor the vector scores of the
+// individuals (see engine.Compare).
 func NewSigmaScaling(selector evolve.Selection) evolve.Selection {
 	return &sigmaScaling{selector: selector}
 }
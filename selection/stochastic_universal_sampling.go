@@ -11,6 +11,10 @@ import (
 // fitness-proportionate selection strategy. Ensures that the frequency of
 // selection for each candidate is consistent with its expected frequency of
 // selection.
+//
+// Like any fitness-proportionate strategy, it ignores the comparator of the
+// engine and the vector scores of the individuals, only their Fitness counts
+// (see engine.Compare).
 type StochasticUniversalSampling struct{}
 
 // Select selects the specified number of candidates from the population.
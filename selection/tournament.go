@@ -66,6 +66,10 @@ func (ts *Tournament) SetProbRange(min, max float64) error {
 }
 
 // Select selects the specified number of candidates from the population.
+//
+// The fitter of two candidates is determined by their position in pop, which
+// must be sorted, the fittest candidate first. Tournament thus respects the
+// order defined by any evolve.Comparator, natural is ignored.
 func (ts *Tournament) Select(
 	pop evolve.Population,
 	natural bool,
@@ -74,9 +78,13 @@ func (ts *Tournament) Select(
 
 	sel := make([]interface{}, size)
 	for i := 0; i < size; i++ {
-		// Pick two candidates at random.
-		cand1 := pop[rng.Intn(len(pop))]
-		cand2 := pop[rng.Intn(len(pop))]
+		// Pick two candidates at random. Since the population is sorted, the
+		// fitter of the two is the one with the lowest index.
+		i1 := rng.Intn(len(pop))
+		i2 := rng.Intn(len(pop))
+		if i2 < i1 {
+			i1, i2 = i2, i1
+		}
 
 		// get a random value to decide wether to select the fitter individual
 		// or the weaker one.
@@ -85,18 +93,10 @@ func (ts *Tournament) Select(
 			prob = ts.probmin + (ts.probmax-ts.probmin)*rng.Float64()
 		}
 
-		if natural && rng.Float64() < prob { // Select the fitter candidate.
-			if cand2.Fitness > cand1.Fitness {
-				sel[i] = cand2.Candidate
-			} else {
-				sel[i] = cand1.Candidate
-			}
+		if rng.Float64() < prob { // Select the fitter candidate.
+			sel[i] = pop[i1].Candidate
 		} else { // Select the less fit candidate.
-			if cand2.Fitness > cand1.Fitness {
-				sel[i] = cand1.Candidate
-			} else {
-				sel[i] = cand2.Candidate
-			}
+			sel[i] = pop[i2].Candidate
 		}
 	}
 	return sel
@@ -38,3 +38,22 @@ func TestTournamentSelectionSetProb(t *testing.T) {
 		t.Errorf("want ts.SetProb(0.4, 0.6) = ErrInvalidTournamentProb, got %v", err)
 	}
 }
+
+func TestTournamentSelectionRespectsOrder(t *testing.T) {
+	// With a probability of 1, the weakest candidate, i.e the last of the
+	// sorted population, can only be selected against itself.
+	ts := NewTournament()
+	errcheck(t, ts.SetProb(1))
+	for _, natural := range []bool{true, false} {
+		testRandomBasedSelection(t, ts, randomBasedPopNatural, natural, 1000,
+			func(selected []interface{}) error {
+				if n := frequency(selected, "Gary"); n > 200 {
+					return fmt.Errorf("weakest candidate selected %d times out of 1000", n)
+				}
+				if n := frequency(selected, "Steve"); n < 300 {
+					return fmt.Errorf("fittest candidate selected %d times out of 1000", n)
+				}
+				return nil
+			})
+	}
+}
@@ -0,0 +1,105 @@
+package evolve
+
+import (
+	"math/rand"
+	"time"
+)
+
+// Timings holds the wall time spent in each phase of a generation.
+type Timings struct {
+	// Selection is the time spent selecting the candidates to breed.
+	Selection time.Duration
+
+	// Operators is the time spent applying the genetic operators to the
+	// selected candidates. The time spent in each operator is reported in
+	// OperatorStats.
+	Operators time.Duration
+
+	// Evaluation is the time spent evaluating the fitness of the candidates.
+	Evaluation time.Duration
+
+	// Sorting is the time spent sorting the population.
+	Sorting time.Duration
+}
+
+// Total returns the total time spent in all phases.
+func (t Timings) Total() time.Duration {
+	return t.Selection + t.Operators + t.Evaluation + t.Sorting
+}
+
+// Add returns the sum of t and u, phase by phase.
+func (t Timings) Add(u Timings) Timings {
+	return Timings{
+		Selection:  t.Selection + u.Selection,
+		Operators:  t.Operators + u.Operators,
+		Evaluation: t.Evaluation + u.Evaluation,
+		Sorting:    t.Sorting + u.Sorting,
+	}
+}
+
+// OperatorStats reports the activity of a genetic operator.
+type OperatorStats struct {
+	// Name is the operator name (see OperatorName).
+	Name string
+
+	// Time is the time spent applying the operator.
+	Time time.Duration
+
+	// Offspring is the number of offspring the operator produced, and
+	// Changed the number of those that are not unmodified copies of their
+	// parent.
+	Offspring int
+	Changed   int
+}
+
+// An InstrumentedOperator is a TracingOperator made of several operators, and
+// that reports the activity of each of them.
+type InstrumentedOperator interface {
+	TracingOperator
+
+	// ApplyInstrumented is equivalent to ApplyTrace, it also returns the
+	// stats of the operators it's made of, in the order they are applied.
+	ApplyInstrumented([]interface{}, *rand.Rand) ([]interface{}, []Derivation, []OperatorStats)
+}
+
+// ApplyInstrumented applies op to sel and returns the offspring, their
+// derivations (see ApplyTrace) and the stats of the operators.
+//
+// If op is not an InstrumentedOperator, the returned stats only hold those of
+// op, considered as a single operator, that changed the offspring whose
+// derivation has a non-empty Op.
+func ApplyInstrumented(op Operator, sel []interface{}, rng *rand.Rand) ([]interface{}, []Derivation, []OperatorStats) {
+	if iop, ok := op.(InstrumentedOperator); ok {
+		return iop.ApplyInstrumented(sel, rng)
+	}
+
+	start := time.Now()
+	off, derivs := ApplyTrace(op, sel, rng)
+	stats := OperatorStats{
+		Name:      OperatorName(op),
+		Time:      time.Since(start),
+		Offspring: len(off),
+	}
+	for _, d := range derivs {
+		if d.Op != "" {
+			stats.Changed++
+		}
+	}
+	return off, derivs, []OperatorStats{stats}
+}
+
+// MergeOperatorStats adds the stats of the operators in src to those of the
+// same operators in dst, matched by position and name, and returns dst. Stats
+// of operators that don't match are appended to dst.
+func MergeOperatorStats(dst, src []OperatorStats) []OperatorStats {
+	for i, st := range src {
+		if i >= len(dst) || dst[i].Name != st.Name {
+			dst = append(dst, st)
+			continue
+		}
+		dst[i].Time += st.Time
+		dst[i].Offspring += st.Offspring
+		dst[i].Changed += st.Changed
+	}
+	return dst
+}
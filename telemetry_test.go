@@ -0,0 +1,36 @@
+package evolve
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestApplyInstrumented(t *testing.T) {
+	off, derivs, stats := ApplyInstrumented(incOdd{}, []interface{}{1, 2, 3}, nil)
+	assert.Equal(t, []interface{}{2, 2, 4}, off)
+	assert.Len(t, derivs, 3)
+	assert.Len(t, stats, 1)
+	assert.Equal(t, "evolve.incOdd", stats[0].Name)
+	assert.Equal(t, 3, stats[0].Offspring)
+	assert.Equal(t, 2, stats[0].Changed)
+}
+
+func TestMergeOperatorStats(t *testing.T) {
+	dst := []OperatorStats{{Name: "crossover", Time: time.Second, Offspring: 10, Changed: 6}}
+	src := []OperatorStats{
+		{Name: "crossover", Time: time.Second, Offspring: 10, Changed: 4},
+		{Name: "mutation", Time: time.Second, Offspring: 10, Changed: 1},
+	}
+	assert.Equal(t, []OperatorStats{
+		{Name: "crossover", Time: 2 * time.Second, Offspring: 20, Changed: 10},
+		{Name: "mutation", Time: time.Second, Offspring: 10, Changed: 1},
+	}, MergeOperatorStats(dst, src))
+}
+
+func TestTimings(t *testing.T) {
+	a := Timings{Selection: 1, Operators: 2, Evaluation: 3, Sorting: 4}
+	assert.Equal(t, time.Duration(10), a.Total())
+	assert.Equal(t, Timings{Selection: 2, Operators: 4, Evaluation: 6, Sorting: 8}, a.Add(a))
+}